@@ -7,39 +7,97 @@ import (
 	"github.com/nao1215/markdown/internal"
 )
 
-// alert renders a GitHub alert, quoting every line of the body.
+// alert renders an alert in the dialect, quoting every line of the text and
+// then of the blocks.
 //
 // Only the first line used to carry the "> " marker. Prose survived that by
 // lazy continuation, but a list, a blank line, or a fenced block in the text
 // escaped the callout and rendered as a sibling of it. Because alert text is
 // usually a variable rather than a literal, the newline that caused it was
 // never visible at the call site.
-func alert(kind, text string) string {
+func alert(d Dialect, kind AlertKind, title, text, blocks string) string {
 	lf := internal.LineFeed()
 
 	// Split on "\n" after dropping "\r" so a plain Go literal containing "\n"
 	// is handled on Windows too, where internal.LineFeed() is "\r\n".
-	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
-	for i, line := range lines {
-		switch {
-		case strings.TrimSpace(line) == "":
-			lines[i] = ">"
-		case strings.HasPrefix(line, ">"):
-			// Already quoted by the caller. Callers wrote these continuations by
-			// hand to work around this very bug; prefixing them again would turn
-			// them into a quote nested inside the alert.
-		default:
-			lines[i] = "> " + line
+	lines := []string{}
+	if text != "" || blocks == "" {
+		lines = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+		for i, line := range lines {
+			switch {
+			case strings.TrimSpace(line) == "":
+				lines[i] = ">"
+			case strings.HasPrefix(line, ">"):
+				// Already quoted by the caller. Callers wrote these continuations by
+				// hand to work around this very bug; prefixing them again would turn
+				// them into a quote nested inside the alert.
+			default:
+				lines[i] = "> " + line
+			}
+		}
+	}
+	if blocks != "" {
+		// The blocks are written by the builder, so a line starting with ">"
+		// is a quote inside the alert and is quoted like every other line.
+		if len(lines) > 0 {
+			lines = append(lines, ">")
+		}
+		for _, line := range strings.Split(strings.ReplaceAll(blocks, "\r\n", "\n"), "\n") {
+			if strings.TrimSpace(line) == "" {
+				lines = append(lines, ">")
+				continue
+			}
+			lines = append(lines, "> "+line)
 		}
 	}
 
-	return fmt.Sprintf("> [!%s]  %s%s", kind, lf, strings.Join(lines, lf))
+	body := strings.Join(lines, lf)
+	title = oneLine(title)
+
+	switch d {
+	case DialectGFM:
+		return fmt.Sprintf("> [!%s]  %s%s", kind, lf, body)
+	case DialectGitLab:
+		// GitLab reads the rest of the marker line as the title of the alert,
+		// so nothing but the title may follow the marker.
+		marker := "> [!" + strings.ToLower(string(kind)) + "]"
+		if title != "" {
+			marker += " " + title
+		}
+		return marker + lf + body
+	case DialectCommonMark, DialectAzureDevOps, DialectBitbucket:
+	}
+	if title == "" {
+		title = kind.title()
+	}
+	return fmt.Sprintf("> **%s**%s>%s%s", title, lf, lf, body)
+}
+
+// AlertBlocks writes an alert of the given kind that holds the blocks body
+// adds to the builder it is given, such as a table, a code block or a list,
+// which the text of Note and the others cannot hold. Every line of them is
+// quoted.
+//
+// The builder body is given writes the way m does and shares its footnotes,
+// reference links and cross references, and an error it records is recorded by
+// m. A title other than "" replaces the name of the kind at the top of the
+// alert; GitHub alerts have none, so there a title is recorded as
+// ErrUnsupportedByDialect and left out. WithEscaping escapes the title as it
+// does the text of Note. A kind that is not one of the AlertKind constants is
+// recorded as an error and nothing is written.
+func (m *Markdown) AlertBlocks(kind AlertKind, title string, body func(m *Markdown)) *Markdown {
+	switch kind {
+	case AlertKindNote, AlertKindTip, AlertKindImportant, AlertKindWarning, AlertKindCaution:
+	default:
+		m.addError(fmt.Errorf("unknown alert kind: %q", kind))
+		return m
+	}
+	return m.add(&Alert{Kind: kind, Title: m.literal(title, EscapeParagraph), Body: m.blockBody(body)})
 }
 
 // Note set text with note format.
 func (m *Markdown) Note(text string) *Markdown {
-	m.body = append(m.body, alert("NOTE", text))
-	return m
+	return m.add(&Alert{Kind: AlertKindNote, Text: m.literal(text, EscapeParagraph)})
 }
 
 // Notef set text with note format. It is similar to fmt.Sprintf.
@@ -49,8 +107,7 @@ func (m *Markdown) Notef(format string, args ...interface{}) *Markdown {
 
 // Tip set text with tip format.
 func (m *Markdown) Tip(text string) *Markdown {
-	m.body = append(m.body, alert("TIP", text))
-	return m
+	return m.add(&Alert{Kind: AlertKindTip, Text: m.literal(text, EscapeParagraph)})
 }
 
 // Tipf set text with tip format. It is similar to fmt.Sprintf.
@@ -60,8 +117,7 @@ func (m *Markdown) Tipf(format string, args ...interface{}) *Markdown {
 
 // Important set text with important format.
 func (m *Markdown) Important(text string) *Markdown {
-	m.body = append(m.body, alert("IMPORTANT", text))
-	return m
+	return m.add(&Alert{Kind: AlertKindImportant, Text: m.literal(text, EscapeParagraph)})
 }
 
 // Importantf set text with important format. It is similar to fmt.Sprintf.
@@ -71,8 +127,7 @@ func (m *Markdown) Importantf(format string, args ...interface{}) *Markdown {
 
 // Warning set text with warning format.
 func (m *Markdown) Warning(text string) *Markdown {
-	m.body = append(m.body, alert("WARNING", text))
-	return m
+	return m.add(&Alert{Kind: AlertKindWarning, Text: m.literal(text, EscapeParagraph)})
 }
 
 // Warningf set text with warning format. It is similar to fmt.Sprintf.
@@ -82,8 +137,7 @@ func (m *Markdown) Warningf(format string, args ...interface{}) *Markdown {
 
 // Caution set text with caution format.
 func (m *Markdown) Caution(text string) *Markdown {
-	m.body = append(m.body, alert("CAUTION", text))
-	return m
+	return m.add(&Alert{Kind: AlertKindCaution, Text: m.literal(text, EscapeParagraph)})
 }
 
 // Cautionf set text with caution format. It is similar to fmt.Sprintf.
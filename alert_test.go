@@ -0,0 +1,175 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAlertBlocks(t *testing.T) {
+	t.Parallel()
+
+	body := func(m *Markdown) {
+		m.PlainText("Run:").
+			CodeBlocks(SyntaxHighlightShell, "make test").
+			Table(TableSet{Header: []string{"a"}, Rows: [][]string{{"1"}}})
+	}
+
+	tests := map[string]struct {
+		opts  []Option
+		title string
+		body  func(*Markdown)
+		want  string
+	}{
+		"blocks": {
+			body: body,
+			want: "> [!WARNING]  \n> Run:\n> ```shell\n> make test\n> ```\n> | a |\n> |---------|\n> | 1 |",
+		},
+		"a quote and a list inside": {
+			body: func(m *Markdown) { m.Blockquote("quoted").BulletList("a", "b") },
+			want: "> [!WARNING]  \n> > quoted\n>\n> - a\n> - b",
+		},
+		"nested alert": {
+			body: func(m *Markdown) { m.AlertBlocks(AlertKindTip, "", func(m *Markdown) { m.PlainText("inner") }) },
+			want: "> [!WARNING]  \n> > [!TIP]  \n> > inner",
+		},
+		"title on GitLab": {
+			opts:  []Option{WithDialect(DialectGitLab)},
+			title: "Before you upgrade",
+			body:  func(m *Markdown) { m.BulletList("back up") },
+			want:  "> [!warning] Before you upgrade\n> - back up",
+		},
+		"title elsewhere": {
+			opts:  []Option{WithDialect(DialectCommonMark)},
+			title: "Before you upgrade",
+			body:  func(m *Markdown) { m.BulletList("back up") },
+			want:  "> **Before you upgrade**\n>\n> - back up",
+		},
+		"escaped title": {
+			opts:  []Option{WithDialect(DialectGitLab), WithEscaping()},
+			title: "*not* bold",
+			body:  func(m *Markdown) { m.PlainText("x") },
+			want:  "> [!warning] \\*not\\* bold\n> x",
+		},
+		"no body": {
+			want: "> [!WARNING]  \n>",
+		},
+		"block spacing": {
+			opts: []Option{WithBlockSpacing()},
+			body: func(m *Markdown) { m.H2("Steps").PlainText("a") },
+			want: "> [!WARNING]  \n> ## Steps\n>\n> a",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, tt.opts...).AlertBlocks(AlertKindWarning, tt.title, tt.body)
+			if err := m.Error(); err != nil {
+				t.Fatalf("Error() = %v", err)
+			}
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), m.String()); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAlertBlocksTitleOnGitHub(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).AlertBlocks(AlertKindNote, "Title", func(m *Markdown) { m.PlainText("x") })
+	if err := m.Error(); !errors.Is(err, ErrUnsupportedByDialect) {
+		t.Errorf("Error() = %v, want ErrUnsupportedByDialect", err)
+	}
+	if diff := cmp.Diff(normalizeLineFeeds("> [!NOTE]  \n> x"), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAlertBlocksErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown kind", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).AlertBlocks("HINT", "", nil)
+		if err := m.Error(); err == nil || !strings.Contains(err.Error(), `unknown alert kind: "HINT"`) {
+			t.Errorf("Error() = %v, want the unknown kind", err)
+		}
+		if got := m.String(); got != "" {
+			t.Errorf("String() = %q, want nothing written", got)
+		}
+	})
+
+	t.Run("the body's error", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).AlertBlocks(AlertKindNote, "", func(m *Markdown) {
+			m.Table(TableSet{Header: []string{"a"}, Rows: [][]string{{"1", "2"}}})
+		})
+		if err := m.Error(); err == nil || !strings.Contains(err.Error(), "failed to validate columns") {
+			t.Errorf("Error() = %v, want the table's", err)
+		}
+	})
+
+	t.Run("the dialect is checked once", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithDialect(DialectBitbucket)).AlertBlocks(AlertKindNote, "", func(m *Markdown) {
+			m.Details("more", "text")
+		})
+		err := m.Error()
+		if !errors.Is(err, ErrUnsupportedByDialect) {
+			t.Fatalf("Error() = %v, want ErrUnsupportedByDialect", err)
+		}
+		if n := strings.Count(err.Error(), "a details block"); n != 1 {
+			t.Errorf("Error() = %v, reports the details block %d times, want once", err, n)
+		}
+	})
+}
+
+func TestAlertBlocksShareFootnotes(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil)
+	m.PlainText("a" + m.Footnote("first"))
+	m.AlertBlocks(AlertKindNote, "", func(inner *Markdown) {
+		inner.PlainText("b" + inner.Footnote("second") + " c" + inner.Footnote("first"))
+	})
+
+	want := "a[^1]\n> [!NOTE]  \n> b[^2] c[^1]\n\n[^1]: first\n[^2]: second"
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBlockBodiesCheckCrossReferences(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(m *Markdown, body func(inner *Markdown)){
+		"alert":   func(m *Markdown, body func(inner *Markdown)) { m.AlertBlocks(AlertKindNote, "", body) },
+		"details": func(m *Markdown, body func(inner *Markdown)) { m.DetailsBlocks("More", false, body) },
+	}
+
+	for name, add := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			broken := NewMarkdown(&strings.Builder{})
+			add(broken, func(inner *Markdown) { inner.PlainText(inner.CrossReference("y", "nope")) })
+			if err := broken.Build(); !errors.Is(err, ErrUndefinedCrossReference) || !strings.Contains(err.Error(), "#nope") {
+				t.Errorf("Build() = %v, want ErrUndefinedCrossReference naming #nope", err)
+			}
+
+			defined := NewMarkdown(&strings.Builder{}).H2WithID("Install", "install")
+			add(defined, func(inner *Markdown) { inner.PlainText(inner.CrossReference("install", "install")) })
+			if err := defined.Build(); err != nil {
+				t.Errorf("Build() = %v, want nil", err)
+			}
+		})
+	}
+}
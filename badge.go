@@ -1,11 +1,17 @@
 package markdown
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 // RedBadge set text with red badge format.
 func (m *Markdown) RedBadge(text string) *Markdown {
-	m.body = append(m.body, fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-red)", text))
-	return m
+	return m.add(&Paragraph{Text: fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-red)", text)})
 }
 
 // RedBadgef set text with red badge format. It is similar to fmt.Sprintf.
@@ -15,8 +21,7 @@ func (m *Markdown) RedBadgef(format string, args ...interface{}) *Markdown {
 
 // YellowBadge set text with yellow badge format.
 func (m *Markdown) YellowBadge(text string) *Markdown {
-	m.body = append(m.body, fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-yellow)", text))
-	return m
+	return m.add(&Paragraph{Text: fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-yellow)", text)})
 }
 
 // YellowBadgef set text with yellow badge format. It is similar to fmt.Sprintf.
@@ -26,8 +31,7 @@ func (m *Markdown) YellowBadgef(format string, args ...interface{}) *Markdown {
 
 // GreenBadge set text with green badge format.
 func (m *Markdown) GreenBadge(text string) *Markdown {
-	m.body = append(m.body, fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-green)", text))
-	return m
+	return m.add(&Paragraph{Text: fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-green)", text)})
 }
 
 // GreenBadgef set text with green badge format. It is similar to fmt.Sprintf.
@@ -37,11 +41,233 @@ func (m *Markdown) GreenBadgef(format string, args ...interface{}) *Markdown {
 
 // BlueBadge set text with blue badge format.
 func (m *Markdown) BlueBadge(text string) *Markdown {
-	m.body = append(m.body, fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-blue)", text))
-	return m
+	return m.add(&Paragraph{Text: fmt.Sprintf("![Badge](https://img.shields.io/badge/%s-blue)", text)})
 }
 
 // BlueBadgef set text with blue badge format. It is similar to fmt.Sprintf.
 func (m *Markdown) BlueBadgef(format string, args ...interface{}) *Markdown {
 	return m.BlueBadge(fmt.Sprintf(format, args...))
 }
+
+// BadgeStyle is the look of a Badge, as shields.io names it.
+type BadgeStyle string
+
+const (
+	// BadgeStyleDefault leaves the style to shields.io, which draws it flat.
+	BadgeStyleDefault BadgeStyle = ""
+	// BadgeStyleFlat is a flat badge with rounded corners.
+	BadgeStyleFlat BadgeStyle = "flat"
+	// BadgeStyleFlatSquare is a flat badge with square corners.
+	BadgeStyleFlatSquare BadgeStyle = "flat-square"
+	// BadgeStylePlastic is a badge with a gloss.
+	BadgeStylePlastic BadgeStyle = "plastic"
+	// BadgeStyleForTheBadge is a larger badge in capitals.
+	BadgeStyleForTheBadge BadgeStyle = "for-the-badge"
+	// BadgeStyleSocial is a badge styled like a GitHub button.
+	BadgeStyleSocial BadgeStyle = "social"
+)
+
+// Badge is a shields.io badge such as "build | passing": a label on the left,
+// a message on a colored background on the right.
+//
+// A badge is static, its text written into its URL, unless Endpoint is set.
+// Then shields.io reads the label, the message and the color from the JSON
+// file at that URL each time the badge is shown, which is how a badge follows
+// a value CI computes, such as coverage. EndpointJSON writes such a file.
+type Badge struct {
+	// Label is the text on the left, or "" for a badge with a message only.
+	Label string
+	// Message is the text on the right. A static badge needs one.
+	Message string
+	// Color is the background of the message: a name shields.io knows, such
+	// as "brightgreen" or "informational", or a hex color such as "#4c1" or
+	// "44cc11". "" is lightgrey.
+	Color string
+	// LabelColor is the background of the label, in the same form as Color,
+	// or "" for the default grey.
+	LabelColor string
+	// Style is the look of the badge.
+	Style BadgeStyle
+	// Logo is the name of a Simple Icons logo shown before the label, such as
+	// "go" or "github", or "" for none.
+	Logo string
+	// LogoColor is the color of the logo, in the same form as Color.
+	LogoColor string
+	// Link is the URL the badge links to, or "" for a badge that is only an
+	// image.
+	Link string
+	// Endpoint is the URL of a shields.io endpoint JSON file the badge reads
+	// its label, message and color from, or "" for a static badge.
+	Endpoint string
+}
+
+// URL returns the address of the badge image.
+//
+// The label and the message of a static badge are written in the path the way
+// shields.io reads it: a dash is doubled, an underscore is doubled, a space
+// becomes an underscore, and the rest is percent-encoded.
+func (b Badge) URL() string {
+	query := url.Values{}
+	if b.Endpoint != "" {
+		query.Set("url", b.Endpoint)
+	}
+	for key, value := range map[string]string{
+		"style":      string(b.Style),
+		"logo":       b.Logo,
+		"logoColor":  badgeColor(b.LogoColor),
+		"labelColor": badgeColor(b.LabelColor),
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	var path string
+	if b.Endpoint != "" {
+		path = "https://img.shields.io/endpoint"
+	} else {
+		color := badgeColor(b.Color)
+		if color == "" {
+			color = "lightgrey"
+		}
+		parts := []string{escapeBadgeText(b.Message), escapeBadgeText(color)}
+		if b.Label != "" {
+			parts = append([]string{escapeBadgeText(b.Label)}, parts...)
+		}
+		path = "https://img.shields.io/badge/" + strings.Join(parts, "-")
+	}
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// String returns the badge as a markdown image, inside a link when the badge
+// has one. The alternative text is "label: message", which is what a screen
+// reader or a broken image shows.
+func (b Badge) String() string {
+	alt := b.Message
+	switch {
+	case b.Label != "" && b.Message != "":
+		alt = b.Label + ": " + b.Message
+	case b.Label != "":
+		alt = b.Label
+	case alt == "":
+		alt = "Badge"
+	}
+	image := Image(EscapeLinkText(alt), b.URL())
+	if b.Link == "" {
+		return image
+	}
+	return Link(image, b.Link)
+}
+
+// validate reports what is missing from the badge, or a style shields.io does
+// not have.
+func (b Badge) validate() error {
+	if b.Endpoint == "" && b.Message == "" {
+		return fmt.Errorf("badge %q has no message", b.Label)
+	}
+	switch b.Style {
+	case BadgeStyleDefault, BadgeStyleFlat, BadgeStyleFlatSquare, BadgeStylePlastic, BadgeStyleForTheBadge, BadgeStyleSocial:
+		return nil
+	default:
+		return fmt.Errorf("unknown badge style: %q", b.Style)
+	}
+}
+
+// Badge writes a badge as a paragraph of its own. A static badge without a
+// message, or with a style shields.io does not have, is recorded as an error
+// and not written.
+func (m *Markdown) Badge(b Badge) *Markdown {
+	return m.Badges(b)
+}
+
+// Badges writes badges side by side, as the row at the top of a README. A badge
+// Badge would refuse is recorded as an error and left out of the row.
+func (m *Markdown) Badges(badges ...Badge) *Markdown {
+	row := make([]string, 0, len(badges))
+	for _, b := range badges {
+		if err := b.validate(); err != nil {
+			m.addError(err)
+			continue
+		}
+		row = append(row, b.String())
+	}
+	if len(row) == 0 {
+		return m
+	}
+	return m.add(&Paragraph{Text: strings.Join(row, " ")})
+}
+
+// badgeEndpoint is the JSON shields.io reads a dynamic badge from.
+type badgeEndpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color,omitempty"`
+	LabelColor    string `json:"labelColor,omitempty"`
+	Style         string `json:"style,omitempty"`
+	NamedLogo     string `json:"namedLogo,omitempty"`
+	LogoColor     string `json:"logoColor,omitempty"`
+}
+
+// EndpointJSON returns the shields.io endpoint JSON of the badge: the file a
+// badge whose Endpoint is its URL reads its label, message and color from. CI
+// writes it, with WriteEndpointFile for example, and publishes it wherever
+// shields.io can fetch it. Link and Endpoint are not part of it.
+//
+// A badge without a message, or with a style shields.io does not have, is an
+// error.
+func (b Badge) EndpointJSON() ([]byte, error) {
+	if b.Message == "" {
+		return nil, fmt.Errorf("badge %q has no message", b.Label)
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(badgeEndpoint{
+		SchemaVersion: 1,
+		Label:         b.Label,
+		Message:       b.Message,
+		Color:         badgeColor(b.Color),
+		LabelColor:    badgeColor(b.LabelColor),
+		Style:         string(b.Style),
+		NamedLogo:     b.Logo,
+		LogoColor:     badgeColor(b.LogoColor),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode badge endpoint: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// WriteEndpointFile writes the EndpointJSON of the badge to the file at path,
+// replacing the file if there is one.
+func (b Badge) WriteEndpointFile(path string) error {
+	out, err := b.EndpointJSON()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Clean(path), out, badgeEndpointFileMode); err != nil {
+		return fmt.Errorf("failed to write badge endpoint: %w", err)
+	}
+	return nil
+}
+
+// badgeEndpointFileMode is the mode of a file WriteEndpointFile creates.
+const badgeEndpointFileMode = 0o600
+
+// badgeColor returns a color the way shields.io reads it, without the '#' a
+// hex color is usually written with.
+func badgeColor(color string) string {
+	return strings.TrimPrefix(strings.TrimSpace(color), "#")
+}
+
+// escapeBadgeText writes text as one part of a static badge path.
+func escapeBadgeText(text string) string {
+	text = strings.NewReplacer("-", "--", "_", "__", " ", "_").Replace(text)
+	// Parentheses are fine in a path but not in a markdown link destination
+	// that does not balance them.
+	return strings.NewReplacer("(", "%28", ")", "%29").Replace(url.PathEscape(text))
+}
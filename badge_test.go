@@ -0,0 +1,136 @@
+package markdown
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBadgeString(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		badge Badge
+		want  string
+	}{
+		"label and message": {
+			badge: Badge{Label: "build", Message: "passing", Color: "brightgreen"},
+			want:  "![build: passing](https://img.shields.io/badge/build-passing-brightgreen)",
+		},
+		"message only": {
+			badge: Badge{Message: "stable", Color: "blue"},
+			want:  "![stable](https://img.shields.io/badge/stable-blue)",
+		},
+		"default color": {
+			badge: Badge{Label: "go", Message: "1.23"},
+			want:  "![go: 1.23](https://img.shields.io/badge/go-1.23-lightgrey)",
+		},
+		"dashes, underscores and spaces": {
+			badge: Badge{Label: "code coverage", Message: "96%_of-all (lines)", Color: "#4c1"},
+			want:  "![code coverage: 96%\\_of-all (lines)](https://img.shields.io/badge/code_coverage-96%25__of--all_%28lines%29-4c1)",
+		},
+		"style, logo and colors": {
+			badge: Badge{
+				Label: "go", Message: "reference", Color: "007d9c", LabelColor: "#555",
+				Style: BadgeStyleForTheBadge, Logo: "go", LogoColor: "white",
+			},
+			want: "![go: reference](https://img.shields.io/badge/go-reference-007d9c?labelColor=555&logo=go&logoColor=white&style=for-the-badge)",
+		},
+		"link": {
+			badge: Badge{Label: "docs", Message: "latest", Color: "blue", Link: "https://pkg.go.dev"},
+			want:  "[![docs: latest](https://img.shields.io/badge/docs-latest-blue)](https://pkg.go.dev)",
+		},
+		"endpoint": {
+			badge: Badge{Label: "coverage", Endpoint: "https://example.com/coverage.json", Style: BadgeStyleFlatSquare},
+			want:  "![coverage](https://img.shields.io/endpoint?style=flat-square&url=https%3A%2F%2Fexample.com%2Fcoverage.json)",
+		},
+		"alternative text is escaped": {
+			badge: Badge{Label: "[x]", Message: "y", Color: "red"},
+			want:  "![\\[x\\]: y](https://img.shields.io/badge/%5Bx%5D-y-red)",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, tt.badge.String()); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMarkdownBadges(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).Badges(
+		Badge{Label: "build", Message: "passing", Color: "green"},
+		Badge{Label: "empty"},
+		Badge{Label: "license", Message: "MIT", Style: "shiny"},
+		Badge{Label: "license", Message: "MIT"},
+	).Badge(Badge{Message: "alone"})
+
+	want := "![build: passing](https://img.shields.io/badge/build-passing-green) ![license: MIT](https://img.shields.io/badge/license-MIT-lightgrey)\n" +
+		"![alone](https://img.shields.io/badge/alone-lightgrey)"
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	err := m.Error()
+	for _, msg := range []string{`badge "empty" has no message`, `unknown badge style: "shiny"`} {
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Error() = %v, want it to contain %q", err, msg)
+		}
+	}
+}
+
+func TestBadgeEndpointJSON(t *testing.T) {
+	t.Parallel()
+
+	b := Badge{Label: "coverage", Message: "96%", Color: "#4c1", Logo: "go", Link: "https://example.com"}
+	got, err := b.EndpointJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"schemaVersion\": 1,\n  \"label\": \"coverage\",\n  \"message\": \"96%\",\n  \"color\": \"4c1\",\n  \"namedLogo\": \"go\"\n}\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := (Badge{Label: "coverage", Endpoint: "https://example.com/c.json"}).EndpointJSON(); err == nil {
+		t.Error("EndpointJSON() without a message = nil error, want one")
+	}
+	if _, err := (Badge{Message: "x", Style: "shiny"}).EndpointJSON(); err == nil {
+		t.Error("EndpointJSON() with an unknown style = nil error, want one")
+	}
+}
+
+func TestBadgeWriteEndpointFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	if err := (Badge{Label: "coverage", Message: "80%", Color: "yellow"}).WriteEndpointFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // the path is the test's own
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"schemaVersion": float64(1), "label": "coverage", "message": "80%", "color": "yellow"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := (Badge{Message: "x"}).WriteEndpointFile(filepath.Join(t.TempDir(), "missing", "x.json")); err == nil {
+		t.Error("WriteEndpointFile() into a missing directory = nil error, want one")
+	}
+}
@@ -0,0 +1,637 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// Block is one block of a document: a heading, a paragraph, a list, a table
+// and so on. Every method of the chain that writes a block appends one, and
+// [Markdown.Blocks], [Markdown.Walk] and [Markdown.Transform] hand them back
+// so a document can be inspected and rewritten before it is built.
+//
+// The set of blocks is closed: the types in this package are the only ones.
+// [Raw] holds anything the others cannot say.
+//
+// A block is a pointer, so changing one that Walk hands out changes the
+// document it came from.
+type Block interface {
+	// String returns the block as markdown, on its own.
+	String() string
+	// render returns the block as markdown, written for the document r builds.
+	render(r *blockRenderer) string
+	// kind returns the spacing class of the block, given its rendered text.
+	kind(text string) blockKind
+}
+
+// blockRenderer carries what a block needs to know about the document it is
+// written into.
+type blockRenderer struct {
+	// blockSpacing separates every block with a blank line.
+	blockSpacing bool
+	// dialect is the markdown flavor the blocks are written in.
+	dialect Dialect
+	// headingIDStyle is the way explicit heading IDs are written.
+	headingIDStyle HeadingIDStyle
+	// lineWidth is the column prose is wrapped at, or 0 for none.
+	lineWidth int
+}
+
+// renderedBlock is a block written out, with the class the join reads.
+type renderedBlock struct {
+	text string
+	kind blockKind
+}
+
+// render writes out every block.
+func (r *blockRenderer) render(blocks []Block) []renderedBlock {
+	out := make([]renderedBlock, 0, len(blocks))
+	for _, b := range blocks {
+		text := b.render(r)
+		kind := kindBlank
+		if strings.TrimSpace(text) != "" {
+			kind = b.kind(text)
+		}
+		out = append(out, renderedBlock{text: text, kind: kind})
+	}
+	return out
+}
+
+// blockKind is the class of a block as far as the blank lines around it are
+// concerned.
+type blockKind int
+
+const (
+	// kindText is a block without spacing rules of its own.
+	kindText blockKind = iota
+	// kindBlank is a whitespace-only entry, which separates blocks by itself.
+	kindBlank
+	// kindComment is an HTML comment, which renders as nothing.
+	kindComment
+	// kindQuote is a blockquote or an alert, which swallows the next line.
+	kindQuote
+	// kindBulletList is a bullet list.
+	kindBulletList
+	// kindOrderedList is a numbered list.
+	kindOrderedList
+	// kindCheckBoxList is a task list.
+	kindCheckBoxList
+)
+
+// isList reports whether the kind is one of the three list kinds.
+func (k blockKind) isList() bool {
+	return k == kindBulletList || k == kindOrderedList || k == kindCheckBoxList
+}
+
+// textKind classifies markdown whose block type is unknown, by looking at how
+// it starts. It is what a paragraph or raw text gets, because either can hold
+// a list or a quote the caller wrote by hand.
+func textKind(text string) blockKind {
+	switch {
+	case strings.TrimSpace(text) == "":
+		return kindBlank
+	case strings.HasPrefix(text, "<!--"):
+		return kindComment
+	case strings.HasPrefix(text, ">"):
+		return kindQuote
+	}
+	return listKind(text)
+}
+
+// Heading is a heading of level 1 to 6, written by H1 to H6.
+type Heading struct {
+	// Level is the heading level, from 1 to 6.
+	Level int
+	// Text is the heading text.
+	Text string
+	// ID is the explicit ID of the heading, written the way
+	// WithHeadingIDStyle says, or "" for none. See H1WithID.
+	ID string
+
+	source
+}
+
+// String returns the heading as markdown.
+func (h *Heading) String() string { return h.render(&blockRenderer{}) }
+
+func (h *Heading) render(r *blockRenderer) string {
+	if text, ok := h.verbatim(h.Level, h.Text, h.ID); ok {
+		return text
+	}
+	marks := strings.Repeat("#", clampHeadingLevel(h.Level))
+	switch {
+	case h.ID == "":
+		return fmt.Sprintf("%s %s", marks, h.Text)
+	case r.headingIDStyle == HeadingIDAttribute:
+		return fmt.Sprintf("%s %s {#%s}", marks, h.Text, h.ID)
+	default:
+		return fmt.Sprintf("%s <a id=\"%s\"></a>%s", marks, h.ID, h.Text)
+	}
+}
+
+func (h *Heading) kind(_ string) blockKind { return kindText }
+
+// clampHeadingLevel keeps a level inside the six markdown has.
+func clampHeadingLevel(level int) int {
+	return max(int(TableOfContentsDepthH1), min(level, int(TableOfContentsDepthH6)))
+}
+
+// Paragraph is text written as it is, by PlainText.
+type Paragraph struct {
+	// Text is the markdown of the paragraph.
+	Text string
+
+	source
+}
+
+// String returns the paragraph as markdown.
+func (p *Paragraph) String() string { return p.render(&blockRenderer{}) }
+
+func (p *Paragraph) render(r *blockRenderer) string {
+	if text, ok := p.verbatim(p.Text); ok {
+		return text
+	}
+	return r.wrap(p.Text, 0, "")
+}
+
+func (p *Paragraph) kind(text string) blockKind { return textKind(text) }
+
+// ListStyle is the kind of list a List is written as.
+type ListStyle int
+
+const (
+	// ListStyleBullet is a bullet list, written with "- ".
+	ListStyleBullet ListStyle = iota
+	// ListStyleOrdered is a numbered list, written with "1. ".
+	ListStyleOrdered
+	// ListStyleCheckBox is a task list, written with "- [ ] " or "- [x] ".
+	ListStyleCheckBox
+)
+
+// List is a list of any of the three kinds, nested or not.
+type List struct {
+	// Style is the kind of list.
+	Style ListStyle
+	// Items are the items of the list.
+	Items []ListItem
+
+	// flat writes every item as "marker text" and nothing else, which is what
+	// BulletList, OrderedList and CheckBox have always written. Their text is
+	// not indented under the marker, so a caller's multi-line item keeps the
+	// bytes it had before lists could nest.
+	flat bool
+}
+
+// String returns the list as markdown.
+func (l *List) String() string { return l.render(&blockRenderer{}) }
+
+func (l *List) render(r *blockRenderer) string {
+	if l.flat {
+		lines := make([]string, 0, len(l.Items))
+		for i, item := range l.Items {
+			marker := l.Style.marker(i, item)
+			indent := l.Style.indent(marker)
+			lines = append(lines, marker+indentContinuation(r.wrap(normalizeLineFeeds(item.Text), len(marker), ""), indent))
+		}
+		return strings.Join(lines, internal.LineFeed())
+	}
+	return renderListTree(r, l.Style, l.Items)
+}
+
+func (l *List) kind(_ string) blockKind {
+	switch l.Style {
+	case ListStyleOrdered:
+		return kindOrderedList
+	case ListStyleCheckBox:
+		return kindCheckBoxList
+	case ListStyleBullet:
+		return kindBulletList
+	}
+	return kindBulletList
+}
+
+// Table is a table, written by Table or, with Options set, by CustomTable.
+type Table struct {
+	// Set is the content of the table.
+	Set TableSet
+	// Options are the CustomTable options. Nil writes the table the way
+	// Table does.
+	Options *TableOptions
+}
+
+// String returns the table as markdown.
+func (t *Table) String() string { return t.render(&blockRenderer{}) }
+
+func (t *Table) render(_ *blockRenderer) string {
+	if t.Options != nil {
+		// The table rendered once when it was appended, which is where its
+		// error was recorded; a table that fails now was changed since.
+		text, _ := renderCustomTable(t.Set, *t.Options)
+		return text
+	}
+	return renderTable(t.Set)
+}
+
+func (t *Table) kind(_ string) blockKind { return kindText }
+
+// CodeBlock is a fenced code block, written by CodeBlocks.
+type CodeBlock struct {
+	// Lang is the language the block is highlighted as.
+	Lang SyntaxHighlight
+	// Code is the content of the block.
+	Code string
+	// Options are the info-string attributes CustomCodeBlock writes, or nil.
+	Options *CodeBlockOptions
+
+	source
+}
+
+// String returns the code block as markdown.
+func (c *CodeBlock) String() string { return c.render(&blockRenderer{}) }
+
+func (c *CodeBlock) render(r *blockRenderer) string {
+	if text, ok := c.verbatim(c.Lang, c.Code); ok && c.Options == nil {
+		return text
+	}
+	lf := internal.LineFeed()
+	if c.Lang == SyntaxHighlightMermaid && r.dialect == DialectAzureDevOps {
+		// Azure DevOps draws diagrams from its own container syntax only.
+		return fmt.Sprintf("::: mermaid%s%s%s:::", lf, c.Code, lf)
+	}
+	info := string(c.Lang)
+	if c.Options != nil {
+		info = c.Options.infoString(c.Lang)
+	}
+	fence := codeFence(c.Code, info)
+	return fence + info + lf + c.Code + lf + fence
+}
+
+func (c *CodeBlock) kind(_ string) blockKind { return kindText }
+
+// Blockquote is a quote, written by Blockquote.
+type Blockquote struct {
+	// Text is the quoted text. Every line of it is quoted.
+	Text string
+}
+
+// String returns the quote as markdown.
+func (q *Blockquote) String() string { return q.render(&blockRenderer{}) }
+
+func (q *Blockquote) render(r *blockRenderer) string {
+	// Split on "\n" after dropping "\r": splitting on internal.LineFeed() meant
+	// a plain Go literal containing "\n" was never split on Windows, and the
+	// quote silently covered only its first line.
+	lines := strings.Split(strings.ReplaceAll(r.wrap(q.Text, len("> "), ""), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("> %s", line)
+	}
+	return strings.Join(lines, internal.LineFeed())
+}
+
+func (q *Blockquote) kind(_ string) blockKind { return kindQuote }
+
+// AlertKind is the kind of a GitHub alert.
+type AlertKind string
+
+const (
+	// AlertKindNote is written by Note.
+	AlertKindNote AlertKind = "NOTE"
+	// AlertKindTip is written by Tip.
+	AlertKindTip AlertKind = "TIP"
+	// AlertKindImportant is written by Important.
+	AlertKindImportant AlertKind = "IMPORTANT"
+	// AlertKindWarning is written by Warning.
+	AlertKindWarning AlertKind = "WARNING"
+	// AlertKindCaution is written by Caution.
+	AlertKindCaution AlertKind = "CAUTION"
+)
+
+// title returns the kind the way the alert heading shows it, such as "Note".
+func (k AlertKind) title() string {
+	if k == "" {
+		return ""
+	}
+	return strings.ToUpper(string(k[:1])) + strings.ToLower(string(k[1:]))
+}
+
+// Alert is a GitHub alert, written by Note, Tip, Important, Warning, Caution
+// and AlertBlocks. Other dialects spell it their own way; see WithDialect.
+type Alert struct {
+	// Kind is the kind of alert.
+	Kind AlertKind
+	// Title replaces the name of the kind at the top of the alert, or is ""
+	// for the name. GitHub alerts have no title, so it is only written in the
+	// other dialects.
+	Title string
+	// Text is the text inside the alert. Every line of it is quoted.
+	Text string
+	// Body holds blocks, such as a table or a code block, written after the
+	// text inside the alert. It may be nil.
+	Body *Markdown
+}
+
+// String returns the alert as markdown.
+func (a *Alert) String() string { return a.render(&blockRenderer{}) }
+
+func (a *Alert) render(r *blockRenderer) string {
+	return alert(r.dialect, a.Kind, a.Title, r.wrap(a.Text, len("> "), ""), renderBody(r.indented(len("> ")), a.Body))
+}
+
+func (a *Alert) kind(_ string) blockKind { return kindQuote }
+
+// Details is a collapsible section, written by Details and DetailsBlocks.
+type Details struct {
+	// Summary is the line shown while the section is collapsed.
+	Summary string
+	// Text is the content of the section.
+	Text string
+	// Body holds blocks written after the text inside the section. It may be
+	// nil.
+	Body *Markdown
+	// Open shows the section expanded until the reader collapses it.
+	Open bool
+}
+
+// String returns the section as markdown.
+func (d *Details) String() string { return d.render(&blockRenderer{}) }
+
+func (d *Details) render(r *blockRenderer) string {
+	lf := internal.LineFeed()
+	tag := "<details>"
+	if d.Open {
+		tag = "<details open>"
+	}
+	content := d.Text
+	if blocks := renderBody(r, d.Body); blocks != "" {
+		if content != "" {
+			content += lf + lf
+		}
+		content += blocks
+	}
+	return fmt.Sprintf("%s%s<summary>%s</summary>%s%s%s%s%s</details>%s",
+		tag, lf, d.Summary, lf, lf, content, lf, lf, lf)
+}
+
+// renderBody writes the blocks a block holds, joined the way the document
+// joins its own, or "" for none.
+func renderBody(r *blockRenderer, body *Markdown) string {
+	if body == nil {
+		return ""
+	}
+	// A table or a details block ends with a line feed of its own, which the
+	// block holding it writes for itself.
+	return strings.TrimRight(joinBlocks(r.render(body.body), r.blockSpacing), "\r\n")
+}
+
+func (d *Details) kind(_ string) blockKind { return kindText }
+
+// Picture is an image written by CustomImage as a block of its own.
+type Picture struct {
+	// Text is the alternative text of the image.
+	Text string
+	// URL is the address of the image.
+	URL string
+	// Options are the size, alignment, dark variant, title and link.
+	Options ImageOptions
+}
+
+// String returns the image as markdown.
+func (p *Picture) String() string { return p.render(&blockRenderer{}) }
+
+func (p *Picture) render(_ *blockRenderer) string {
+	image := CustomImage(p.Text, p.URL, p.Options)
+	if p.Options.needsHTML() {
+		return image + internal.LineFeed()
+	}
+	return image
+}
+
+func (p *Picture) kind(_ string) blockKind { return kindText }
+
+// HorizontalRule is a thematic break, written by HorizontalRule.
+type HorizontalRule struct{}
+
+// String returns the rule as markdown.
+func (h *HorizontalRule) String() string { return h.render(&blockRenderer{}) }
+
+func (h *HorizontalRule) render(_ *blockRenderer) string { return "---" }
+
+func (h *HorizontalRule) kind(_ string) blockKind { return kindText }
+
+// Raw is markdown written exactly as it is: the blank line BlankLine writes,
+// the line break LF writes, the table of contents markers, and the blocks of a
+// parsed document that no other type describes.
+type Raw struct {
+	// Text is the markdown.
+	Text string
+
+	source
+}
+
+// String returns the text.
+func (r *Raw) String() string { return r.render(&blockRenderer{}) }
+
+func (r *Raw) render(_ *blockRenderer) string { return r.Text }
+
+func (r *Raw) kind(text string) blockKind { return textKind(text) }
+
+// source is the text a parsed block was read from, along with the fields it was
+// read as.
+//
+// A parsed block is written back as its source for as long as its fields still
+// hold what was read, which is what makes an untouched document round-trip:
+// "Title\n=====" and "## Title ##" are headings this package would write
+// differently. A block whose fields were changed is written the usual way.
+type source struct {
+	text   string
+	fields []any
+	// headings are the headings the text holds, for a block that is more than
+	// one heading.
+	headings []headerInfo
+}
+
+// verbatim returns the source text when fields still match what was read.
+func (s source) verbatim(fields ...any) (string, bool) {
+	if s.fields == nil || len(fields) != len(s.fields) {
+		return "", false
+	}
+	for i := range fields {
+		if fields[i] != s.fields[i] {
+			return "", false
+		}
+	}
+	return s.text, true
+}
+
+// Blocks returns the top-level blocks of the document, in order.
+//
+// The slice is a copy but the blocks are not: changing a field of one changes
+// the document. Use Transform or ReplaceBlocks to add or remove blocks.
+func (m *Markdown) Blocks() []Block {
+	blocks := make([]Block, len(m.body))
+	copy(blocks, m.body)
+	return blocks
+}
+
+// AddBlocks appends blocks built by hand, or taken from another document.
+//
+// A heading whose level is not between 1 and 6 or whose ID H1WithID would
+// refuse, and a table whose rows do not match its header, are recorded as
+// errors and left out. The errors recorded by the bodies of a list's items
+// are recorded too, as BulletListTree does, and their cross references are
+// checked against this document. The footnotes and reference links of those
+// bodies, and of the body of an alert or a details block, are registered here
+// and renumbered in a copy of the block when their numbers are taken, as
+// AlertBlocks does.
+func (m *Markdown) AddBlocks(blocks ...Block) *Markdown {
+	for _, b := range blocks {
+		if b == nil {
+			continue
+		}
+		switch b := b.(type) {
+		case *Heading:
+			if b.Level < int(TableOfContentsDepthH1) || b.Level > int(TableOfContentsDepthH6) {
+				m.addError(fmt.Errorf("invalid heading level: %d (must be between 1 and 6)", b.Level))
+				continue
+			}
+			if b.ID != "" {
+				if err := validateHeadingID(b.ID); err != nil {
+					m.addError(err)
+					continue
+				}
+			}
+		case *CodeBlock:
+			if b.Options != nil {
+				if err := b.Options.validate(); err != nil {
+					m.addError(err)
+					continue
+				}
+			}
+		case *Table:
+			if err := b.Set.ValidateColumns(); err != nil {
+				m.addError(fmt.Errorf("failed to validate columns: %w", err))
+				continue
+			}
+		case *List:
+			if items, ok := m.adoptItems(b.Items); ok {
+				l := *b
+				l.Items = items
+				m.add(&l)
+				continue
+			}
+		case *Alert:
+			if body, ok := m.adoptBody(b.Body); ok {
+				a := *b
+				a.Body = body
+				m.add(&a)
+				continue
+			}
+		case *Details:
+			if body, ok := m.adoptBody(b.Body); ok {
+				d := *b
+				d.Body = body
+				m.add(&d)
+				continue
+			}
+		}
+		m.add(b)
+	}
+	return m
+}
+
+// Walk calls fn for every block of the document in order, going into the
+// blocks held by the items of a list, an alert or a details block after the
+// block itself. It stops as soon as fn returns false.
+//
+// fn may change the block it is given; that is the way to rewrite a link in
+// every paragraph, for example. Adding or removing blocks is Transform's job.
+func (m *Markdown) Walk(fn func(Block) bool) {
+	walkBlocks(m.body, fn)
+}
+
+// walkBlocks walks blocks and reports whether the walk should go on.
+func walkBlocks(blocks []Block, fn func(Block) bool) bool {
+	for _, b := range blocks {
+		if !fn(b) {
+			return false
+		}
+		switch b := b.(type) {
+		case *List:
+			if !walkItems(b.Items, fn) {
+				return false
+			}
+		case *Alert:
+			if b.Body != nil && !walkBlocks(b.Body.body, fn) {
+				return false
+			}
+		case *Details:
+			if b.Body != nil && !walkBlocks(b.Body.body, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// walkItems walks the blocks held by list items, depth first.
+func walkItems(items []ListItem, fn func(Block) bool) bool {
+	for _, item := range items {
+		if item.Body != nil && !walkBlocks(item.Body.body, fn) {
+			return false
+		}
+		if !walkItems(item.Items, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Transform replaces every block Walk would visit with the blocks fn returns
+// for it: the block itself to keep it, nothing to drop it, or several to put
+// in its place. A list, an alert or a details block is transformed after the
+// blocks it holds.
+//
+// Dropping a heading takes it out of the table of contents, and a heading fn
+// returns joins it, because the table of contents is built from the blocks
+// when the document is.
+func (m *Markdown) Transform(fn func(Block) []Block) *Markdown {
+	m.body = transformBlocks(m.body, fn)
+	return m
+}
+
+// transformBlocks returns blocks with fn applied to each, inside out.
+func transformBlocks(blocks []Block, fn func(Block) []Block) []Block {
+	out := make([]Block, 0, len(blocks))
+	for _, b := range blocks {
+		switch b := b.(type) {
+		case *List:
+			transformItems(b.Items, fn)
+		case *Alert:
+			if b.Body != nil {
+				b.Body.body = transformBlocks(b.Body.body, fn)
+			}
+		case *Details:
+			if b.Body != nil {
+				b.Body.body = transformBlocks(b.Body.body, fn)
+			}
+		}
+		for _, replacement := range fn(b) {
+			if replacement != nil {
+				out = append(out, replacement)
+			}
+		}
+	}
+	return out
+}
+
+// transformItems applies fn to the blocks held by list items.
+func transformItems(items []ListItem, fn func(Block) []Block) {
+	for i := range items {
+		if items[i].Body != nil {
+			items[i].Body.body = transformBlocks(items[i].Body.body, fn)
+		}
+		transformItems(items[i].Items, fn)
+	}
+}
@@ -0,0 +1,388 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// blockTexts returns the markdown of every block of m, as BlockText does.
+func blockTexts(m *Markdown) []string {
+	texts := make([]string, 0, m.BlockCount())
+	for i := 0; i < m.BlockCount(); i++ {
+		texts = append(texts, m.BlockText(i))
+	}
+	return texts
+}
+
+// rawBlocks returns one Raw block per text.
+func rawBlocks(texts ...string) []Block {
+	blocks := make([]Block, 0, len(texts))
+	for _, text := range texts {
+		blocks = append(blocks, &Raw{Text: text})
+	}
+	return blocks
+}
+
+func TestChainMethodsAppendTypedBlocks(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).
+		H2("Title").
+		PlainText("text").
+		BulletList("a", "b").
+		OrderedList("one").
+		CheckBox([]CheckBoxSet{{Checked: true, Text: "done"}}).
+		Table(TableSet{Header: []string{"h"}, Rows: [][]string{{"c"}}}).
+		CodeBlocks(SyntaxHighlightGo, "x := 1").
+		Blockquote("quote").
+		Note("note").
+		Details("summary", "body").
+		HorizontalRule().
+		LF().
+		BlankLine()
+
+	want := []Block{
+		&Heading{Level: 2, Text: "Title"},
+		&Paragraph{Text: "text"},
+		&List{Style: ListStyleBullet, Items: []ListItem{{Text: "a"}, {Text: "b"}}, flat: true},
+		&List{Style: ListStyleOrdered, Items: []ListItem{{Text: "one"}}, flat: true},
+		&List{Style: ListStyleCheckBox, Items: []ListItem{{Text: "done", Checked: true}}, flat: true},
+		&Table{Set: TableSet{Header: []string{"h"}, Rows: [][]string{{"c"}}}},
+		&CodeBlock{Lang: SyntaxHighlightGo, Code: "x := 1"},
+		&Blockquote{Text: "quote"},
+		&Alert{Kind: AlertKindNote, Text: "note"},
+		&Details{Summary: "summary", Text: "body"},
+		&HorizontalRule{},
+		&Raw{Text: "  "},
+		&Raw{Text: ""},
+	}
+	opts := cmp.AllowUnexported(Heading{}, Paragraph{}, CodeBlock{}, Raw{}, List{}, source{})
+	if diff := cmp.Diff(want, m.Blocks(), opts); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBlocksRenderAsTheChainDid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		block Block
+		want  string
+	}{
+		"heading":        {block: &Heading{Level: 3, Text: "Title"}, want: "### Title"},
+		"bullet list":    {block: &List{Style: ListStyleBullet, Items: []ListItem{Item("a", Item("b"))}}, want: "- a" + lf() + "  - b"},
+		"code block":     {block: &CodeBlock{Lang: SyntaxHighlightGo, Code: "x"}, want: "```go" + lf() + "x" + lf() + "```"},
+		"quote":          {block: &Blockquote{Text: "a\nb"}, want: "> a" + lf() + "> b"},
+		"alert":          {block: &Alert{Kind: AlertKindTip, Text: "t"}, want: "> [!TIP]  " + lf() + "> t"},
+		"rule":           {block: &HorizontalRule{}, want: "---"},
+		"table":          {block: &Table{Set: TableSet{Header: []string{"h"}}}, want: "| h |" + lf() + "|---------|" + lf()},
+		"custom table":   {block: &Table{Set: TableSet{Header: []string{"h"}, Rows: [][]string{{"c"}}}, Options: &TableOptions{}}, want: "| h |" + lf() + "|---|" + lf() + "| c |" + lf()},
+		"heading past 6": {block: &Heading{Level: 9, Text: "deep"}, want: "###### deep"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.block.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddBlocks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("blocks join the document and its table of contents", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).
+			TableOfContents(TableOfContentsDepthH2).
+			AddBlocks(&Heading{Level: 2, Text: "Added"}, nil, &Paragraph{Text: "text"})
+
+		want := strings.Join([]string{
+			TableOfContentsMarkerBegin,
+			"- [Added](#added)",
+			TableOfContentsMarkerEnd,
+			"",
+			"## Added",
+			"text",
+		}, lf())
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("a heading out of range is an error", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).AddBlocks(&Heading{Level: 0, Text: "x"})
+		if m.Error() == nil {
+			t.Error("expected an error")
+		}
+		if m.BlockCount() != 0 {
+			t.Errorf("the heading was added: %q", blockTexts(m))
+		}
+	})
+
+	t.Run("a table with a short row is an error", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).AddBlocks(&Table{Set: TableSet{Header: []string{"a", "b"}, Rows: [][]string{{"a"}}}})
+		if !errors.Is(m.Error(), ErrMismatchColumn) {
+			t.Errorf("Error() = %v, want %v", m.Error(), ErrMismatchColumn)
+		}
+	})
+
+	t.Run("the error of a list item body is recorded", func(t *testing.T) {
+		t.Parallel()
+
+		body := NewMarkdown(nil).AddBlocks(&Heading{Level: 7})
+		m := NewMarkdown(nil).AddBlocks(&List{Items: []ListItem{{Text: "a", Body: body}}})
+		if m.Error() == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("the footnotes of bodies are the document's", func(t *testing.T) {
+		t.Parallel()
+
+		note := func(text string) *Markdown {
+			body := NewMarkdown(nil)
+			body.PlainText(text + body.Footnote(text))
+			return body
+		}
+		m := NewMarkdown(nil)
+		m.PlainText("a" + m.Footnote("a"))
+		m.AddBlocks(
+			&List{Items: []ListItem{{Text: "item", Body: note("b")}}},
+			&Alert{Kind: AlertKindNote, Body: note("c")},
+			&Details{Summary: "More", Body: note("d")},
+		)
+
+		got := m.String()
+		for _, want := range []string{"  b[^2]", "> c[^3]", "d[^4]", "[^1]: a\n[^2]: b\n[^3]: c\n[^4]: d"} {
+			if !strings.Contains(got, normalizeLineFeeds(want)) {
+				t.Errorf("String() = %q, want it to hold %q", got, want)
+			}
+		}
+	})
+}
+
+func TestBlocksReturnsACopy(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).PlainText("a")
+	blocks := m.Blocks()
+	blocks[0] = &Paragraph{Text: "b"}
+
+	if got := m.String(); got != "a" {
+		t.Errorf("String() = %q, want %q", got, "a")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	inner := NewMarkdown(nil).CodeBlocks(SyntaxHighlightGo, "x")
+	m := NewMarkdown(nil).
+		H1("Title").
+		BulletListTree(Item("a", ListItem{Text: "b", Body: inner})).
+		AlertBlocks(AlertKindNote, "", func(m *Markdown) { m.H2("In the alert") }).
+		DetailsBlocks("more", false, func(m *Markdown) { m.H3("In the details") }).
+		PlainText("end")
+
+	t.Run("visits the blocks inside list items, alerts and details", func(t *testing.T) {
+		t.Parallel()
+
+		visited := []string{}
+		m.Walk(func(b Block) bool {
+			visited = append(visited, strings.SplitN(b.String(), lf(), 2)[0])
+			return true
+		})
+		want := []string{"# Title", "- a", "```go", "> [!NOTE]  ", "## In the alert", "<details>", "### In the details", "end"}
+		if diff := cmp.Diff(want, visited); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("stops when fn returns false", func(t *testing.T) {
+		t.Parallel()
+
+		count := 0
+		m.Walk(func(Block) bool {
+			count++
+			return count < 2
+		})
+		if count != 2 {
+			t.Errorf("visited %d blocks, want 2", count)
+		}
+	})
+}
+
+func TestWalkChangesTheDocument(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).
+		PlainText("see [docs](http://old.example.com/docs)").
+		H2("Old")
+
+	m.Walk(func(b Block) bool {
+		switch b := b.(type) {
+		case *Paragraph:
+			b.Text = strings.ReplaceAll(b.Text, "http://old.example.com", "https://example.com")
+		case *Heading:
+			b.Text = "New"
+		}
+		return true
+	})
+
+	want := "see [docs](https://example.com/docs)" + lf() + "## New"
+	if got := m.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if _, _, ok := m.SectionBounds("New"); !ok {
+		t.Error("the renamed heading is not found")
+	}
+}
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drops, keeps and expands blocks", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).
+			H1("Title").
+			TableOfContents(TableOfContentsDepthH2).
+			H2("Internal").
+			H2("Public").
+			Transform(func(b Block) []Block {
+				if h, ok := b.(*Heading); ok {
+					switch h.Text {
+					case "Internal":
+						return nil
+					case "Public":
+						return []Block{h, &Paragraph{Text: "added"}}
+					}
+				}
+				return []Block{b}
+			})
+
+		want := strings.Join([]string{
+			"# Title",
+			TableOfContentsMarkerBegin,
+			"- [Title](#title)",
+			"  - [Public](#public)",
+			TableOfContentsMarkerEnd,
+			"",
+			"## Public",
+			"added",
+		}, lf())
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("reaches the blocks inside list items", func(t *testing.T) {
+		t.Parallel()
+
+		inner := NewMarkdown(nil).PlainText("drop me").PlainText("keep me")
+		m := NewMarkdown(nil).
+			BulletListTree(ListItem{Text: "a", Body: inner}).
+			Transform(func(b Block) []Block {
+				if p, ok := b.(*Paragraph); ok && p.Text == "drop me" {
+					return nil
+				}
+				return []Block{b}
+			})
+
+		want := "- a" + lf() + "  keep me"
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestParseReadsTypedBlocks(t *testing.T) {
+	t.Parallel()
+
+	src := "Title\n=====\n\ntext\n\n~~~go\nx := 1\n~~~\n\n---\n\n***\n\n- a\n- b\n"
+	m, err := Parse(strings.NewReader(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []string{}
+	for _, b := range m.Blocks() {
+		switch b.(type) {
+		case *Heading:
+			kinds = append(kinds, "heading")
+		case *Paragraph:
+			kinds = append(kinds, "paragraph")
+		case *CodeBlock:
+			kinds = append(kinds, "code")
+		case *HorizontalRule:
+			kinds = append(kinds, "rule")
+		case *Raw:
+			kinds = append(kinds, "raw")
+		default:
+			kinds = append(kinds, "other")
+		}
+	}
+	want := []string{"heading", "raw", "paragraph", "raw", "code", "raw", "rule", "raw", "raw", "raw", "raw"}
+	if diff := cmp.Diff(want, kinds); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("an untouched block keeps its source", func(t *testing.T) {
+		t.Parallel()
+
+		if got := m.String(); got != strings.TrimSuffix(src, "\n") {
+			t.Errorf("String() = %q, want the source", got)
+		}
+	})
+
+	t.Run("a changed block is written the usual way", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := Parse(strings.NewReader(src), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		heading, _ := m.Blocks()[0].(*Heading)
+		heading.Level = 2
+		code, _ := m.Blocks()[4].(*CodeBlock)
+		code.Code = "y := 2"
+
+		if got, want := m.BlockText(0), "## Title"; got != want {
+			t.Errorf("BlockText(0) = %q, want %q", got, want)
+		}
+		if got, want := m.BlockText(4), "```go"+lf()+"y := 2"+lf()+"```"; got != want {
+			t.Errorf("BlockText(4) = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestParsedHeadingsInsideRawTextFollowTheText(t *testing.T) {
+	t.Parallel()
+
+	// The heading sits on the line after the list, so the two are one block.
+	m, err := Parse(strings.NewReader("- a\n## Next\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := m.SectionBounds("Next"); !ok {
+		t.Fatal("the heading inside the list block is not found")
+	}
+
+	raw, _ := m.Blocks()[0].(*Raw)
+	raw.Text = "- a"
+	if _, _, ok := m.SectionBounds("Next"); ok {
+		t.Error("the heading is still found after the text changed")
+	}
+}
@@ -0,0 +1,184 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// CodeBlockFormat is the documentation tool whose info-string attributes a
+// code block is written for. GitHub reads none of them.
+type CodeBlockFormat int
+
+const (
+	// CodeBlockFormatMkDocs writes attributes the way mkdocs-material reads
+	// them: title="main.go" linenums="1" hl_lines="2 4-6".
+	CodeBlockFormatMkDocs CodeBlockFormat = iota + 1
+	// CodeBlockFormatDocusaurus writes attributes the way Docusaurus reads
+	// them: title="main.go" {2,4-6} showLineNumbers.
+	CodeBlockFormatDocusaurus
+	// CodeBlockFormatHugo writes attributes the way Hugo reads them:
+	// {title="main.go" linenos=true hl_lines="2 4-6"}.
+	CodeBlockFormatHugo
+)
+
+// CodeBlockOptions are the attributes of a code block written by
+// CustomCodeBlock.
+type CodeBlockOptions struct {
+	// Format is the tool the attributes are written for. It is required as
+	// soon as any other field is set.
+	Format CodeBlockFormat
+	// Title is the file name or caption shown above the block. It cannot hold
+	// a double quote or a line break, which none of the tools can escape.
+	Title string
+	// HighlightLines are the lines to highlight, counted from 1 whatever
+	// LineNumberStart says. Runs of lines are written as ranges.
+	HighlightLines []int
+	// LineNumbers shows line numbers.
+	LineNumbers bool
+	// LineNumberStart is the number of the first line when LineNumbers is set.
+	// Zero means 1.
+	LineNumberStart int
+}
+
+// CustomCodeBlock is a code block with info-string attributes: a title,
+// highlighted lines and line numbers, written for the tool options.Format
+// names.
+//
+// Options that do not make sense are recorded as an error and the block is not
+// added: attributes without a format, a title holding a double quote or a line
+// break, a highlighted line below 1, and a negative LineNumberStart.
+func (m *Markdown) CustomCodeBlock(lang SyntaxHighlight, text string, options CodeBlockOptions) *Markdown {
+	if err := options.validate(); err != nil {
+		m.addError(err)
+		return m
+	}
+	return m.add(&CodeBlock{Lang: lang, Code: text, Options: &options})
+}
+
+// validate reports options that cannot be written.
+func (o *CodeBlockOptions) validate() error {
+	if o.Format == 0 {
+		if o.Title != "" || len(o.HighlightLines) > 0 || o.LineNumbers || o.LineNumberStart != 0 {
+			return errors.New("code block attributes need a format")
+		}
+		return nil
+	}
+	if o.Format < CodeBlockFormatMkDocs || o.Format > CodeBlockFormatHugo {
+		return fmt.Errorf("unknown code block format: %d", int(o.Format))
+	}
+	if strings.ContainsAny(o.Title, "\"\r\n") {
+		return fmt.Errorf("invalid code block title %q: it holds a double quote or a line break", o.Title)
+	}
+	for _, line := range o.HighlightLines {
+		if line < 1 {
+			return fmt.Errorf("invalid highlighted line: %d (must be 1 or more)", line)
+		}
+	}
+	if o.LineNumberStart < 0 {
+		return fmt.Errorf("invalid line number start: %d (must be 0 or more)", o.LineNumberStart)
+	}
+	return nil
+}
+
+// infoString returns the info string of a block in lang with these attributes.
+func (o *CodeBlockOptions) infoString(lang SyntaxHighlight) string {
+	start := o.LineNumberStart
+	if start == 0 {
+		start = 1
+	}
+	ranges := lineRanges(o.HighlightLines)
+
+	attrs := []string{}
+	switch o.Format {
+	case CodeBlockFormatMkDocs:
+		if o.Title != "" {
+			attrs = append(attrs, fmt.Sprintf("title=%q", o.Title))
+		}
+		if o.LineNumbers {
+			attrs = append(attrs, fmt.Sprintf("linenums=\"%d\"", start))
+		}
+		if len(ranges) > 0 {
+			attrs = append(attrs, fmt.Sprintf("hl_lines=\"%s\"", strings.Join(ranges, " ")))
+		}
+	case CodeBlockFormatDocusaurus:
+		if o.Title != "" {
+			attrs = append(attrs, fmt.Sprintf("title=%q", o.Title))
+		}
+		if len(ranges) > 0 {
+			attrs = append(attrs, "{"+strings.Join(ranges, ",")+"}")
+		}
+		switch {
+		case o.LineNumbers && start != 1:
+			attrs = append(attrs, fmt.Sprintf("showLineNumbers=%d", start))
+		case o.LineNumbers:
+			attrs = append(attrs, "showLineNumbers")
+		}
+	case CodeBlockFormatHugo:
+		hugo := []string{}
+		if o.Title != "" {
+			hugo = append(hugo, fmt.Sprintf("title=%q", o.Title))
+		}
+		if o.LineNumbers {
+			hugo = append(hugo, "linenos=true")
+			if start != 1 {
+				hugo = append(hugo, fmt.Sprintf("linenostart=%d", start))
+			}
+		}
+		if len(ranges) > 0 {
+			hugo = append(hugo, fmt.Sprintf("hl_lines=\"%s\"", strings.Join(ranges, " ")))
+		}
+		if len(hugo) > 0 {
+			attrs = append(attrs, "{"+strings.Join(hugo, " ")+"}")
+		}
+	}
+
+	if len(attrs) == 0 {
+		return string(lang)
+	}
+	if lang == "" {
+		return strings.Join(attrs, " ")
+	}
+	return string(lang) + " " + strings.Join(attrs, " ")
+}
+
+// lineRanges returns the lines sorted, without repeats, with every run of
+// consecutive lines written as "first-last".
+func lineRanges(lines []int) []string {
+	sorted := append([]int{}, lines...)
+	sort.Ints(sorted)
+
+	ranges := []string{}
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] <= sorted[j]+1 {
+			j++
+		}
+		if sorted[i] == sorted[j] {
+			ranges = append(ranges, strconv.Itoa(sorted[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+	return ranges
+}
+
+// codeFence returns the fence for a block holding code under info.
+//
+// A fence of three backticks is closed by any line of the code that starts
+// with three backticks, and everything after that line renders as prose. The
+// fence is therefore one backtick longer than the longest run of backticks in
+// the code. An info string holding a backtick cannot follow a backtick fence
+// at all, so that block is fenced with tildes, by the same rule.
+func codeFence(code, info string) string {
+	mark := "`"
+	if strings.Contains(info, "`") {
+		mark = "~"
+	}
+	return strings.Repeat(mark, max(3, internal.LongestRun(code, mark[0])+1)) //nolint:mnd // the shortest fence markdown has
+}
@@ -0,0 +1,142 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodeBlockFence(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		code string
+		info string
+		want string
+	}{
+		"plain code":                       {code: "x := 1", info: "go", want: "```"},
+		"a fence inside":                   {code: "```go\nx\n```", info: "md", want: "````"},
+		"a longer run anywhere":            {code: "a ````` b", info: "", want: "``````"},
+		"tildes do not matter":             {code: "~~~~", info: "", want: "```"},
+		"a backtick in the info string":    {code: "x", info: "a`b", want: "~~~"},
+		"tildes in the content then":       {code: "~~~~~\n```", info: "a`b", want: "~~~~~~"},
+		"runs shorter than three are fine": {code: "`a` and ``b``", info: "", want: "```"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := codeFence(tt.code, tt.info); got != tt.want {
+				t.Errorf("codeFence(%q, %q) = %q, want %q", tt.code, tt.info, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomCodeBlock(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		options CodeBlockOptions
+		want    string
+	}{
+		"mkdocs-material": {
+			options: CodeBlockOptions{Format: CodeBlockFormatMkDocs, Title: "main.go", LineNumbers: true, HighlightLines: []int{5, 2, 3, 4, 7, 2}},
+			want:    `go title="main.go" linenums="1" hl_lines="2-5 7"`,
+		},
+		"mkdocs-material counting from 10": {
+			options: CodeBlockOptions{Format: CodeBlockFormatMkDocs, LineNumbers: true, LineNumberStart: 10},
+			want:    `go linenums="10"`,
+		},
+		"Docusaurus": {
+			options: CodeBlockOptions{Format: CodeBlockFormatDocusaurus, Title: "main.go", LineNumbers: true, HighlightLines: []int{1, 4, 5, 6}},
+			want:    `go title="main.go" {1,4-6} showLineNumbers`,
+		},
+		"Docusaurus counting from 10": {
+			options: CodeBlockOptions{Format: CodeBlockFormatDocusaurus, LineNumbers: true, LineNumberStart: 10},
+			want:    `go showLineNumbers=10`,
+		},
+		"Hugo": {
+			options: CodeBlockOptions{Format: CodeBlockFormatHugo, Title: "main.go", LineNumbers: true, LineNumberStart: 3, HighlightLines: []int{8, 15, 16, 17}},
+			want:    `go {title="main.go" linenos=true linenostart=3 hl_lines="8 15-17"}`,
+		},
+		"a format with nothing to say": {
+			options: CodeBlockOptions{Format: CodeBlockFormatHugo},
+			want:    `go`,
+		},
+		"no options at all": {
+			want: `go`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil).CustomCodeBlock(SyntaxHighlightGo, "x := 1", tt.options)
+			if err := m.Error(); err != nil {
+				t.Fatal(err)
+			}
+			want := "```" + tt.want + lf() + "x := 1" + lf() + "```"
+			if got := m.String(); got != want {
+				t.Errorf("String() = %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("without a language", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).CustomCodeBlock("", "x", CodeBlockOptions{Format: CodeBlockFormatMkDocs, Title: "out.txt"})
+		if got, want := m.String(), "```title=\"out.txt\""+lf()+"x"+lf()+"```"; got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestCustomCodeBlockErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]CodeBlockOptions{
+		"attributes without a format": {Title: "main.go"},
+		"an unknown format":           {Format: CodeBlockFormat(9)},
+		"a quote in the title":        {Format: CodeBlockFormatMkDocs, Title: `say "hi"`},
+		"a line break in the title":   {Format: CodeBlockFormatHugo, Title: "a\nb"},
+		"line zero":                   {Format: CodeBlockFormatDocusaurus, HighlightLines: []int{0}},
+		"a negative start":            {Format: CodeBlockFormatMkDocs, LineNumbers: true, LineNumberStart: -1},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil).CustomCodeBlock(SyntaxHighlightGo, "x", options)
+			if m.Error() == nil {
+				t.Error("expected an error")
+			}
+			if m.BlockCount() != 0 {
+				t.Errorf("the block was added: %q", m.String())
+			}
+
+			added := NewMarkdown(nil).AddBlocks(&CodeBlock{Lang: SyntaxHighlightGo, Code: "x", Options: &options})
+			if added.Error() == nil || added.BlockCount() != 0 {
+				t.Errorf("AddBlocks took the block: %v, %q", added.Error(), added.String())
+			}
+		})
+	}
+}
+
+func TestParsedCodeBlockWithOptionsIsRewritten(t *testing.T) {
+	t.Parallel()
+
+	m, err := Parse(strings.NewReader("~~~go\nx\n~~~\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := m.Blocks()[0].(*CodeBlock)
+	code.Options = &CodeBlockOptions{Format: CodeBlockFormatMkDocs, Title: "x.go"}
+
+	if got, want := m.String(), "```go title=\"x.go\""+lf()+"x"+lf()+"```"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
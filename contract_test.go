@@ -691,6 +691,69 @@ func TestListsParseWithTheItemsTheyWereBuiltWith(t *testing.T) {
 	}
 }
 
+// TestNestedListsParseAsNestedLists pins that a tree reaches the parser as the
+// tree it was built as: every nested item sits in a list inside its parent's
+// item rather than next to it, and a block in an item's body stays inside it.
+func TestNestedListsParseAsNestedLists(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(*markdown.Markdown) *markdown.Markdown{
+		"bullet": func(m *markdown.Markdown) *markdown.Markdown {
+			return m.BulletListTree(markdown.Item("a", markdown.Item("b", markdown.Item("c"))))
+		},
+		"ordered": func(m *markdown.Markdown) *markdown.Markdown {
+			return m.OrderedListTree(markdown.Item("a", markdown.Item("b", markdown.Item("c"))))
+		},
+		"checkbox": func(m *markdown.Markdown) *markdown.Markdown {
+			return m.CheckBoxTree(markdown.Item("a", markdown.Item("b", markdown.Item("c"))))
+		},
+	}
+
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root, _ := parse(t, build(t, fn))
+			if got := len(nodesOfKind(root, ast.KindList)); got != 3 {
+				t.Fatalf("parsed %d lists, want 3", got)
+			}
+
+			depth := 0
+			for n := nodesOfKind(root, ast.KindList)[2]; n != nil; n = n.Parent() {
+				if n.Kind() == ast.KindList {
+					depth++
+				}
+			}
+			if depth != 3 {
+				t.Errorf("the innermost list is nested %d deep, want 3", depth)
+			}
+		})
+	}
+
+	t.Run("body", func(t *testing.T) {
+		t.Parallel()
+
+		root, _ := parse(t, build(t, func(m *markdown.Markdown) *markdown.Markdown {
+			return m.OrderedListTree(
+				markdown.ListItem{
+					Text: "a",
+					Body: markdown.NewMarkdown(nil).CodeBlocks(markdown.SyntaxHighlightGo, "x := 1"),
+				},
+				markdown.Item("b"),
+			)
+		}))
+
+		code := firstOfKind(t, root, ast.KindFencedCodeBlock)
+		if code.Parent().Kind() != ast.KindListItem {
+			t.Errorf("the code block is a child of %s, want a list item", code.Parent().Kind())
+		}
+		list := firstOfKind(t, root, ast.KindList)
+		if got := list.ChildCount(); got != 2 {
+			t.Errorf("the list parsed with %d items, want 2", got)
+		}
+	})
+}
+
 func TestCheckboxesParseAsTaskItems(t *testing.T) {
 	t.Parallel()
 
@@ -780,6 +843,66 @@ func TestAMultiLineAlertStaysOneBlockquote(t *testing.T) {
 	}
 }
 
+func TestBlocksInsideAnAlertStayInsideIt(t *testing.T) {
+	t.Parallel()
+
+	document := build(t, func(m *markdown.Markdown) *markdown.Markdown {
+		return m.AlertBlocks(markdown.AlertKindWarning, "", func(m *markdown.Markdown) {
+			m.Table(markdown.TableSet{Header: []string{"a"}, Rows: [][]string{{"1"}}}).
+				CodeBlocks(markdown.SyntaxHighlightGo, "x := 1").
+				BulletList("one", "two").
+				Blockquote("quoted")
+		}).PlainText("after")
+	})
+
+	root, _ := parse(t, document)
+	var quote ast.Node
+	for n := root.FirstChild(); n != nil; n = n.NextSibling() {
+		if n.Kind() == ast.KindBlockquote {
+			if quote != nil {
+				t.Fatalf("parsed more than one top-level blockquote:\n%s", document)
+			}
+			quote = n
+		}
+	}
+	if quote == nil {
+		t.Fatalf("parsed no blockquote:\n%s", document)
+	}
+	for _, kind := range []ast.NodeKind{extast.KindTable, ast.KindFencedCodeBlock, ast.KindList} {
+		if got := len(nodesOfKind(quote, kind)); got != 1 {
+			t.Errorf("the alert holds %d %s, want 1:\n%s", got, kind, document)
+		}
+	}
+	if got := len(nodesOfKind(quote, ast.KindBlockquote)); got != 2 {
+		t.Errorf("the alert holds %d blockquotes, itself included, want 2:\n%s", got, document)
+	}
+	if root.LastChild().Kind() != ast.KindParagraph {
+		t.Errorf("the paragraph after the alert was swallowed:\n%s", document)
+	}
+}
+
+func TestBlocksInsideDetailsAreMarkdown(t *testing.T) {
+	t.Parallel()
+
+	document := build(t, func(m *markdown.Markdown) *markdown.Markdown {
+		return m.DetailsBlocks("More", true, func(m *markdown.Markdown) {
+			m.CodeBlocks(markdown.SyntaxHighlightGo, "x := 1").
+				Table(markdown.TableSet{Header: []string{"a"}, Rows: [][]string{{"1"}}})
+		}).PlainText("after")
+	})
+
+	root, _ := parse(t, document)
+	if got := len(nodesOfKind(root, ast.KindFencedCodeBlock)); got != 1 {
+		t.Errorf("parsed %d code blocks, want 1:\n%s", got, document)
+	}
+	if got := len(nodesOfKind(root, extast.KindTable)); got != 1 {
+		t.Errorf("parsed %d tables, want 1:\n%s", got, document)
+	}
+	if root.LastChild().Kind() != ast.KindParagraph {
+		t.Errorf("the paragraph after the details was swallowed:\n%s", document)
+	}
+}
+
 func TestLinksAndImagesParseWithTheirDestination(t *testing.T) {
 	t.Parallel()
 
@@ -1167,23 +1290,16 @@ func frontMatter(diagram string) (string, bool) {
 }
 
 // FuzzCodeBlockContent asserts that content placed in a fenced block comes back
-// out of a parser unchanged.
-//
-// The property only holds for content that does not open a fence of its own:
-// three backticks at the start of a line end the block, and the rest of the
-// document spills onto the page as prose. That case is a documented limitation
-// of CodeBlocks rather than something this target can assert, so it is skipped
-// here and pinned separately by TestACodeBlockEndsAtAFenceInItsContent.
+// out of a parser unchanged, fences of its own included: the block's fence is
+// longer than any run of backticks in the content.
 func FuzzCodeBlockContent(f *testing.F) {
 	for _, seed := range boundarySeeds() {
 		f.Add(seed)
 	}
+	f.Add("before\n```\nafter")
+	f.Add("````go\nx\n````")
 
 	f.Fuzz(func(t *testing.T, content string) {
-		if holdsFence(content) {
-			return
-		}
-
 		document := build(t, func(m *markdown.Markdown) *markdown.Markdown {
 			return m.CodeBlocks(markdown.SyntaxHighlightGo, content)
 		})
@@ -1215,16 +1331,6 @@ func FuzzCodeBlockContent(f *testing.F) {
 	})
 }
 
-// holdsFence reports whether content has a line that opens or closes a fence.
-func holdsFence(content string) bool {
-	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
-		if strings.HasPrefix(strings.TrimLeft(line, " "), "```") {
-			return true
-		}
-	}
-	return false
-}
-
 // normalizeContent puts code block content in the one shape a round trip can be
 // compared in: every line ending resolved to "\n", and the trailing one dropped.
 //
@@ -1239,12 +1345,11 @@ func normalizeContent(s string) string {
 	return strings.TrimRight(s, "\n")
 }
 
-// TestACodeBlockEndsAtAFenceInItsContent pins the limitation FuzzCodeBlockContent
-// skips. CodeBlocks writes a three backtick fence, so content holding a line of
-// three backticks closes the block early and everything after it renders as
-// prose. Callers whose content can hold a fence have to say so themselves; this
-// test exists so the limitation is a stated one rather than a surprise.
-func TestACodeBlockEndsAtAFenceInItsContent(t *testing.T) {
+// TestACodeBlockHoldsAFenceInItsContent pins what FuzzCodeBlockContent used to
+// skip. A line of three backticks in the content once closed the block early
+// and everything after it rendered as prose; the fence is now longer than any
+// run of backticks the content holds.
+func TestACodeBlockHoldsAFenceInItsContent(t *testing.T) {
 	t.Parallel()
 
 	document := build(t, func(m *markdown.Markdown) *markdown.Markdown {
@@ -1252,8 +1357,8 @@ func TestACodeBlockEndsAtAFenceInItsContent(t *testing.T) {
 	})
 
 	root, _ := parse(t, document)
-	if got := len(nodesOfKind(root, ast.KindFencedCodeBlock)); got < 2 {
-		t.Errorf("parsed %d fenced code blocks, want at least 2: the limitation this test documents no longer holds", got)
+	if got := len(nodesOfKind(root, ast.KindFencedCodeBlock)); got != 1 {
+		t.Errorf("parsed %d fenced code blocks, want 1:\n%s", got, document)
 	}
 }
 
@@ -1655,7 +1760,7 @@ func TestEveryExportedSymbolHasAnExample(t *testing.T) {
 	if err != nil {
 		t.Fatalf("list the mermaid subpackages: %v", err)
 	}
-	packages = append(packages, ".")
+	packages = append(packages, ".", "inline", "lint")
 
 	for _, dir := range packages {
 		t.Run(dir, func(t *testing.T) {
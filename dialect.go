@@ -0,0 +1,213 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// Dialect is the flavor of markdown a document is written for. Alerts, the
+// table of contents, heading anchors, mermaid diagrams and math are spelled
+// differently by each platform, and some of them are not there at all.
+type Dialect int
+
+const (
+	// DialectGFM is GitHub Flavored Markdown, the default.
+	DialectGFM Dialect = iota
+	// DialectCommonMark is plain CommonMark. It has no alerts, no heading
+	// anchors, no diagrams and no math, so those fall back to quotes and code
+	// blocks, and a table of contents is an error.
+	DialectCommonMark
+	// DialectGitLab is GitLab Flavored Markdown.
+	DialectGitLab
+	// DialectAzureDevOps is the markdown of Azure DevOps wikis and pull requests.
+	DialectAzureDevOps
+	// DialectBitbucket is the markdown of Bitbucket Cloud, which strips HTML.
+	DialectBitbucket
+)
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case DialectGFM:
+		return "GitHub Flavored Markdown"
+	case DialectCommonMark:
+		return "CommonMark"
+	case DialectGitLab:
+		return "GitLab Flavored Markdown"
+	case DialectAzureDevOps:
+		return "Azure DevOps"
+	case DialectBitbucket:
+		return "Bitbucket"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// WithDialect writes the document for another platform than GitHub.
+//
+// The blocks stay the same and are written the way the dialect spells them:
+//
+//   - Note, Tip, Important, Warning and Caution are GitHub alerts, lower-cased
+//     alerts on GitLab, and a quote opening with the bold kind elsewhere. The
+//     title AlertBlocks takes follows the marker on GitLab, replaces the kind
+//     elsewhere, and is an error on GitHub, whose alerts have no title.
+//   - TableOfContents is a list of links on GitHub and Bitbucket, [[_TOC_]] on
+//     GitLab and Azure DevOps, which draw the table themselves from every
+//     heading, and an error on CommonMark, which gives headings no anchors.
+//   - A mermaid code block is a ::: mermaid block on Azure DevOps, and an
+//     error on Bitbucket and CommonMark, which would show the source of the
+//     diagram instead of drawing it.
+//   - Details, and a CustomImage that needs HTML, are errors on Bitbucket,
+//     which shows the HTML tags as text.
+//
+// A block the dialect cannot say is recorded as ErrUnsupportedByDialect when it
+// is added, and written the GitHub way. The text inside blocks is not
+// rewritten; [Dialect.InlineMath], [Dialect.BlockMath] and [Dialect.Highlight]
+// spell those for the dialect, and [Markdown.Dialect] says which one a document
+// uses.
+func WithDialect(d Dialect) Option {
+	return func(m *Markdown) {
+		if d < DialectGFM || d > DialectBitbucket {
+			m.addError(fmt.Errorf("unknown dialect: %d", int(d)))
+			return
+		}
+		m.dialect = d
+	}
+}
+
+// Dialect returns the dialect the document is written for.
+func (m *Markdown) Dialect() Dialect {
+	return m.dialect
+}
+
+// InlineMath returns expression as inline math in the dialect.
+//
+// GitHub and Azure DevOps read $…$, written the way the package-level
+// InlineMath writes it. GitLab reads $`…`$, which needs no escaping. CommonMark
+// and Bitbucket have no math, so the expression is a code span there.
+func (d Dialect) InlineMath(expression string) string {
+	switch d {
+	case DialectGitLab:
+		return "$`" + expression + "`$"
+	case DialectCommonMark, DialectBitbucket:
+		return Code(expression)
+	case DialectGFM, DialectAzureDevOps:
+	}
+	return InlineMath(expression)
+}
+
+// BlockMath returns expression as display math in the dialect.
+//
+// GitHub and Azure DevOps read a $$ block, written the way the package-level
+// BlockMath writes it. GitLab reads a math code block. CommonMark and Bitbucket
+// have no math, so the expression is a math code block there too, which shows
+// it as it is.
+func (d Dialect) BlockMath(expression string) string {
+	switch d {
+	case DialectGitLab, DialectCommonMark, DialectBitbucket:
+		lf := internal.LineFeed()
+		return "```math" + lf + expression + lf + "```"
+	case DialectGFM, DialectAzureDevOps:
+	}
+	return BlockMath(expression)
+}
+
+// Highlight returns text highlighted in the dialect.
+//
+// The package-level Highlight writes ==text==, which none of these platforms
+// read. Every dialect but Bitbucket allows the <mark> element, which is what
+// this writes. Bitbucket strips HTML, so the text is bold there.
+func (d Dialect) Highlight(text string) string {
+	if d == DialectBitbucket {
+		return Bold(text)
+	}
+	return "<mark>" + text + "</mark>"
+}
+
+// anchor returns the anchor the platform gives a heading whose text is text,
+// before the suffix that tells apart headings with the same text.
+func (d Dialect) anchor(text string) string {
+	switch d {
+	case DialectGitLab:
+		return gitLabAnchor(text)
+	case DialectBitbucket:
+		return "markdown-header-" + gitLabAnchor(text)
+	case DialectGFM, DialectCommonMark, DialectAzureDevOps:
+	}
+	return generateGitHubAnchor(text)
+}
+
+// uniqueAnchor returns the anchor of the count-th earlier heading with the same
+// base anchor. Python-Markdown, which Bitbucket renders with, joins the count
+// with "_"; the others with "-".
+func (d Dialect) uniqueAnchor(base string, count int) string {
+	if count == 0 {
+		return base
+	}
+	if d == DialectBitbucket {
+		return fmt.Sprintf("%s_%d", base, count)
+	}
+	return fmt.Sprintf("%s-%d", base, count)
+}
+
+// gitLabAnchor follows GitLab's rule: lower case, everything but letters,
+// digits, "_", "-" and spaces dropped, spaces turned into hyphens, and a run of
+// hyphens squeezed into one.
+func gitLabAnchor(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ' || r == '-':
+			if !strings.HasSuffix(b.String(), "-") {
+				b.WriteRune('-')
+			}
+		case r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// unsupported returns the error a block is recorded with when the dialect has
+// no way to write it, or nil.
+func (d Dialect) unsupported(b Block) error {
+	var what string
+	switch b := b.(type) {
+	case *CodeBlock:
+		if b.Lang == SyntaxHighlightMermaid && (d == DialectCommonMark || d == DialectBitbucket) {
+			what = "a mermaid diagram"
+		}
+	case *Alert:
+		if b.Title != "" && d == DialectGFM {
+			what = "an alert title"
+		}
+	case *Details:
+		if d == DialectBitbucket {
+			what = "a details block"
+		}
+	case *Picture:
+		if b.Options.needsHTML() && d == DialectBitbucket {
+			what = "an image written as HTML"
+		}
+	case *Heading:
+		if b.ID != "" && d == DialectBitbucket {
+			what = "an explicit heading id"
+		}
+	}
+	if what == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s in %s", ErrUnsupportedByDialect, what, d)
+}
+
+// tableOfContentsDirective returns the line that asks the platform to draw the
+// table of contents itself, or "" when the document lists the headings.
+func (d Dialect) tableOfContentsDirective() string {
+	if d == DialectGitLab || d == DialectAzureDevOps {
+		return "[[_TOC_]]"
+	}
+	return ""
+}
@@ -0,0 +1,254 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDialectAlerts(t *testing.T) {
+	t.Parallel()
+
+	tests := map[Dialect]string{
+		DialectGFM:         "> [!TIP]  \n> Use a cache.\n>\n> - a",
+		DialectGitLab:      "> [!tip]\n> Use a cache.\n>\n> - a",
+		DialectCommonMark:  "> **Tip**\n>\n> Use a cache.\n>\n> - a",
+		DialectAzureDevOps: "> **Tip**\n>\n> Use a cache.\n>\n> - a",
+		DialectBitbucket:   "> **Tip**\n>\n> Use a cache.\n>\n> - a",
+	}
+
+	for d, want := range tests {
+		t.Run(d.String(), func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, WithDialect(d)).Tip("Use a cache.\n\n- a")
+			if err := m.Error(); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := m.String(), strings.ReplaceAll(want, "\n", lf()); got != want {
+				t.Errorf("String() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDialectTableOfContents(t *testing.T) {
+	t.Parallel()
+
+	build := func(d Dialect) *Markdown {
+		return NewMarkdown(nil, WithDialect(d)).
+			TableOfContents(TableOfContentsDepthH2).
+			H1("Set up: Linux & macOS").
+			H2("Step").
+			H2("Step")
+	}
+
+	tests := map[Dialect][]string{
+		DialectGFM: {
+			"- [Set up: Linux & macOS](#set-up-linux--macos)",
+			"  - [Step](#step)",
+			"  - [Step](#step-1)",
+		},
+		DialectBitbucket: {
+			"- [Set up: Linux & macOS](#markdown-header-set-up-linux-macos)",
+			"  - [Step](#markdown-header-step)",
+			"  - [Step](#markdown-header-step_1)",
+		},
+		DialectGitLab:      {"[[_TOC_]]"},
+		DialectAzureDevOps: {"[[_TOC_]]"},
+	}
+
+	for d, entries := range tests {
+		t.Run(d.String(), func(t *testing.T) {
+			t.Parallel()
+
+			m := build(d)
+			if err := m.Error(); err != nil {
+				t.Fatal(err)
+			}
+			toc := strings.Join(append(append([]string{TableOfContentsMarkerBegin}, entries...), TableOfContentsMarkerEnd), lf())
+			if got := m.String(); !strings.HasPrefix(got, toc+lf()) {
+				t.Errorf("String() = %q, want it to start with %q", got, toc)
+			}
+		})
+	}
+
+	t.Run("CommonMark has no anchors to link to", func(t *testing.T) {
+		t.Parallel()
+
+		if err := build(DialectCommonMark).Error(); !errors.Is(err, ErrUnsupportedByDialect) {
+			t.Errorf("Error() = %v, want %v", err, ErrUnsupportedByDialect)
+		}
+	})
+}
+
+func TestDialectAnchors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		text    string
+		want    string
+	}{
+		{dialect: DialectGFM, text: "Install & Run", want: "install--run"},
+		{dialect: DialectGitLab, text: "Install & Run", want: "install-run"},
+		{dialect: DialectGitLab, text: "snake_case -- API", want: "snake_case-api"},
+		{dialect: DialectBitbucket, text: "Install & Run", want: "markdown-header-install-run"},
+		{dialect: DialectAzureDevOps, text: "Install & Run", want: "install--run"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.anchor(tt.text); got != tt.want {
+			t.Errorf("%s: anchor(%q) = %q, want %q", tt.dialect, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDialectMermaid(t *testing.T) {
+	t.Parallel()
+
+	diagram := "graph TD\nA-->B"
+
+	t.Run("Azure DevOps uses its container syntax", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithDialect(DialectAzureDevOps)).CodeBlocks(SyntaxHighlightMermaid, diagram)
+		want := strings.Join([]string{"::: mermaid", "graph TD", "A-->B", ":::"}, lf())
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("GitLab uses a fence like GitHub", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithDialect(DialectGitLab)).CodeBlocks(SyntaxHighlightMermaid, "A")
+		if got, want := m.String(), "```mermaid"+lf()+"A"+lf()+"```"; got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	for _, d := range []Dialect{DialectCommonMark, DialectBitbucket} {
+		t.Run(d.String()+" cannot draw it", func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, WithDialect(d)).CodeBlocks(SyntaxHighlightMermaid, diagram)
+			if !errors.Is(m.Error(), ErrUnsupportedByDialect) {
+				t.Errorf("Error() = %v, want %v", m.Error(), ErrUnsupportedByDialect)
+			}
+		})
+	}
+}
+
+func TestDialectUnsupportedBlocks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("details on Bitbucket", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithDialect(DialectBitbucket)).Details("More", "text")
+		if !errors.Is(m.Error(), ErrUnsupportedByDialect) {
+			t.Errorf("Error() = %v, want %v", m.Error(), ErrUnsupportedByDialect)
+		}
+		if !strings.Contains(m.Error().Error(), "Bitbucket") {
+			t.Errorf("the error does not name the dialect: %v", m.Error())
+		}
+	})
+
+	t.Run("a block inside a list item", func(t *testing.T) {
+		t.Parallel()
+
+		body := NewMarkdown(nil).Details("More", "text")
+		m := NewMarkdown(nil, WithDialect(DialectBitbucket)).BulletListTree(ListItem{Text: "a", Body: body})
+		if !errors.Is(m.Error(), ErrUnsupportedByDialect) {
+			t.Errorf("Error() = %v, want %v", m.Error(), ErrUnsupportedByDialect)
+		}
+	})
+
+	t.Run("added blocks", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithDialect(DialectCommonMark)).AddBlocks(&CodeBlock{Lang: SyntaxHighlightMermaid, Code: "A"})
+		if !errors.Is(m.Error(), ErrUnsupportedByDialect) {
+			t.Errorf("Error() = %v, want %v", m.Error(), ErrUnsupportedByDialect)
+		}
+	})
+
+	t.Run("the default dialect supports everything", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).
+			Details("More", "text").
+			CodeBlocks(SyntaxHighlightMermaid, "A").
+			TableOfContents(TableOfContentsDepthH2)
+		if err := m.Error(); err != nil {
+			t.Errorf("Error() = %v, want nil", err)
+		}
+	})
+
+	t.Run("an unknown dialect", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithDialect(Dialect(42)))
+		if m.Error() == nil || m.Dialect() != DialectGFM {
+			t.Errorf("Error() = %v, Dialect() = %v, want an error and GitHub", m.Error(), m.Dialect())
+		}
+	})
+}
+
+func TestDialectInlineText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect   Dialect
+		math      string
+		highlight string
+	}{
+		{dialect: DialectGFM, math: `$a\$b$`, highlight: "<mark>x</mark>"},
+		{dialect: DialectGitLab, math: "$`a$b`$", highlight: "<mark>x</mark>"},
+		{dialect: DialectAzureDevOps, math: `$a\$b$`, highlight: "<mark>x</mark>"},
+		{dialect: DialectCommonMark, math: "`a$b`", highlight: "<mark>x</mark>"},
+		{dialect: DialectBitbucket, math: "`a$b`", highlight: "**x**"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.InlineMath("a$b"); got != tt.math {
+			t.Errorf("%s: InlineMath() = %q, want %q", tt.dialect, got, tt.math)
+		}
+		if got := tt.dialect.Highlight("x"); got != tt.highlight {
+			t.Errorf("%s: Highlight() = %q, want %q", tt.dialect, got, tt.highlight)
+		}
+	}
+
+	for d, want := range map[Dialect]string{
+		DialectGFM:       "$$" + lf() + "x" + lf() + "$$",
+		DialectGitLab:    "```math" + lf() + "x" + lf() + "```",
+		DialectBitbucket: "```math" + lf() + "x" + lf() + "```",
+	} {
+		if got := d.BlockMath("x"); got != want {
+			t.Errorf("%s: BlockMath() = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDialectHTMLAnchorsMatchTheTableOfContents(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil, WithDialect(DialectBitbucket)).
+		TableOfContents(TableOfContentsDepthH2).
+		H2("Install & Run").
+		H2("Install & Run")
+
+	got := renderHTML(t, m)
+	for _, anchor := range []string{"markdown-header-install-run", "markdown-header-install-run_1"} {
+		if !strings.Contains(got, `href="#`+anchor+`"`) || !strings.Contains(got, `id="`+anchor+`"`) {
+			t.Errorf("anchor %q is not both linked and defined:\n%s", anchor, got)
+		}
+	}
+
+	// GitHub reads the lower-case marker the GitLab dialect writes, too.
+	gitLab := renderHTML(t, NewMarkdown(nil, WithDialect(DialectGitLab)).Note("lower case"))
+	if !strings.Contains(gitLab, "markdown-alert-note") {
+		t.Errorf("the lower-case alert is not an alert:\n%s", gitLab)
+	}
+}
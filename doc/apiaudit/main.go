@@ -88,7 +88,7 @@ func main() {
 		panic(err)
 	}
 	sort.Strings(dirs)
-	dirs = append([]string{"../.."}, dirs...)
+	dirs = append([]string{"../..", "../../inline", "../../lint"}, dirs...)
 
 	packages := make([]pkg, 0, len(dirs))
 	for _, dir := range dirs {
@@ -671,5 +671,9 @@ func importPath(p pkg) string {
 	if p.dir == "../.." {
 		return "github.com/nao1215/markdown"
 	}
-	return "github.com/nao1215/markdown/mermaid/" + filepath.Base(p.dir)
+	rel, err := filepath.Rel("../..", p.dir)
+	if err != nil {
+		panic(err)
+	}
+	return "github.com/nao1215/markdown/" + filepath.ToSlash(rel)
 }
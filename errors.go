@@ -11,4 +11,29 @@ var (
 	ErrCreateMarkdownIndex = errors.New("markdown index can't be created")
 	// ErrWriteMarkdownIndex is returned when the index can't be written.
 	ErrWriteMarkdownIndex = errors.New("markdown index can't be written")
+	// ErrInvalidFrontMatter is recorded when front matter is given something other
+	// than a map with string keys or a struct.
+	ErrInvalidFrontMatter = errors.New("front matter must be a map with string keys or a struct")
+	// ErrUnsupportedByDialect is recorded when a block is added that the dialect
+	// chosen with WithDialect has no way to write.
+	ErrUnsupportedByDialect = errors.New("block is not supported by the markdown dialect")
+	// ErrStreamingUnsupported is recorded when a streaming document is asked for
+	// something that needs the whole document.
+	ErrStreamingUnsupported = errors.New("not supported by a streaming document")
+	// ErrRegionNotFound is returned when a managed region to update has no
+	// markers in the document.
+	ErrRegionNotFound = errors.New("managed region not found")
+	// ErrUnbalancedRegion is returned when the markers of managed regions do not
+	// pair up.
+	ErrUnbalancedRegion = errors.New("managed region markers are unbalanced")
+	// ErrUndefinedCrossReference is recorded by Build when CrossReference linked
+	// to an ID no heading of the document defines.
+	ErrUndefinedCrossReference = errors.New("cross reference to an undefined heading id")
+	// ErrDuplicateHeadingID is recorded by Build when CrossReference linked to an
+	// ID more than one heading defines.
+	ErrDuplicateHeadingID = errors.New("heading id is defined more than once")
+
+	// errTableOfContentsGenerated is recorded when a second table of contents is
+	// asked for.
+	errTableOfContentsGenerated = errors.New("table of contents has already been generated")
 )
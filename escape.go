@@ -0,0 +1,153 @@
+package markdown
+
+import (
+	"html"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// WithEscaping makes the builder write the text it is given as literal text.
+//
+// Issue titles, commit messages and other data from elsewhere often hold
+// characters markdown reads as syntax: a title starting with "#" or "1." turns
+// into a heading or a list, a "|" splits a table cell, and a "*" or "_" starts
+// emphasis. With the option set, the text of headings, paragraphs, list items,
+// quotes, alerts and details is escaped for where it is written, as
+// EscapeHeading, EscapeParagraph and EscapeListItem describe. The cells of
+// tables have the markdown punctuation of every line escaped, and are then
+// escaped as EscapeTableCell describes, which on its own leaves emphasis and
+// links alone. None of it changes the document's structure, and a list item
+// that holds a blank line stays one item.
+//
+// The text is escaped whole, so markup built with Bold, Link, Footnote and the
+// like shows as typed. Build such text with Paragraph and the inline package,
+// which escape the text and keep the markup, or add it with AddBlocks, which
+// the option leaves alone. Code blocks already hold their text literally.
+func WithEscaping() Option {
+	return func(m *Markdown) {
+		m.escaping = true
+	}
+}
+
+// EscapeHeading makes text safe to use as the text of a heading.
+//
+// A heading is one line, so a line break becomes a space. The markdown
+// punctuation is escaped, as EscapeParagraph escapes it, and so are '{', which
+// MkDocs and Hugo read as the start of attributes, and a closing run of '#',
+// which markdown drops: "Fix issue #" would otherwise lose its "#".
+func EscapeHeading(text string) string {
+	text = internal.EscapeInline(strings.TrimSpace(oneLine(text)))
+	return escapeClosingHashes(strings.ReplaceAll(text, "{", `\{`))
+}
+
+// escapeClosingHashes escapes a run of '#' that ends heading text after a
+// space, which an ATX heading would read as its closing sequence and drop.
+func escapeClosingHashes(text string) string {
+	if closing := strings.TrimRight(text, "#"); closing != text &&
+		(closing == "" || strings.HasSuffix(closing, " ") || strings.HasSuffix(closing, "\t")) {
+		text = closing + `\` + text[len(closing):]
+	}
+	return text
+}
+
+// EscapeParagraph makes text safe to use as a paragraph, so it reads exactly
+// as given.
+//
+// The characters markdown reads as syntax within a line are escaped: the
+// backslash, '`', '*', '_', '[', ']', '<', '~', '$', '|', and '&' where it would
+// start an entity. So is what would start a block at the start of a line: '#',
+// '>', '-', '+', '=' and the '.' or ')' of "1." or "1)". The spaces and tabs at
+// either end of a line are dropped, since markdown drops them anyway except
+// where they make a code block or a line break.
+//
+// A blank line still ends the paragraph and starts another.
+func EscapeParagraph(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = internal.EscapeLineStart(internal.EscapeInline(strings.Trim(line, " \t\r")))
+	}
+	return strings.Join(lines, internal.LineFeed())
+}
+
+// EscapeListItem makes text safe to use as the text of a list item. The text
+// after a list marker is a paragraph of its own, where a leading "-" or "1."
+// would start a nested list, so it is escaped as EscapeParagraph escapes it.
+func EscapeListItem(text string) string {
+	return EscapeParagraph(text)
+}
+
+// EscapeLinkText makes text safe to use as the text of a link, in Link or
+// ReferenceLink. A line break becomes a space, and the markdown punctuation is
+// escaped as EscapeParagraph escapes it, brackets included, so the text cannot
+// close the link early.
+func EscapeLinkText(text string) string {
+	return internal.EscapeInline(oneLine(text))
+}
+
+// oneLine returns text with each line break replaced by a space.
+func oneLine(text string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+}
+
+// escapeCell makes text safe to use as a table cell and reads as given: its
+// markdown punctuation is escaped, and then it is escaped as EscapeTableCell
+// escapes it.
+func escapeCell(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = internal.EscapeInline(strings.TrimRight(line, "\r"))
+	}
+	return EscapeTableCell(strings.Join(lines, "\n"))
+}
+
+// literal returns text escaped by escape when the builder escapes its text.
+func (m *Markdown) literal(text string, escape func(string) string) string {
+	if !m.escaping {
+		return text
+	}
+	return escape(text)
+}
+
+// literalItems returns the items with their text, and the text of the items
+// nested in them, escaped when the builder escapes its text. The caller's
+// items are left untouched.
+func (m *Markdown) literalItems(items []ListItem) []ListItem {
+	if !m.escaping {
+		return items
+	}
+	escaped := make([]ListItem, len(items))
+	for i, item := range items {
+		item.Text = EscapeListItem(item.Text)
+		item.Items = m.literalItems(item.Items)
+		escaped[i] = item
+	}
+	return escaped
+}
+
+// literalTable returns the table with every cell escaped when the builder
+// escapes its text. The caller's slices are left untouched.
+func (m *Markdown) literalTable(t TableSet) TableSet {
+	if !m.escaping {
+		return t
+	}
+	header := make([]string, len(t.Header))
+	for i, cell := range t.Header {
+		header[i] = escapeCell(cell)
+	}
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i] = make([]string, len(row))
+		for j, cell := range row {
+			rows[i][j] = escapeCell(cell)
+		}
+	}
+	return TableSet{Header: header, Rows: rows, Alignment: t.Alignment}
+}
+
+// escapeSummary makes text safe to use as the summary of a details block,
+// which is HTML: its markup is escaped and a line break, which could end the
+// HTML block, becomes a space.
+func escapeSummary(text string) string {
+	return html.EscapeString(oneLine(text))
+}
@@ -0,0 +1,197 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeHelpers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		escape func(string) string
+		text   string
+		want   string
+	}{
+		"heading punctuation":     {escape: EscapeHeading, text: "Fix *all* the [bugs] | <now>", want: `Fix \*all\* the \[bugs\] \| \<now>`},
+		"heading over lines":      {escape: EscapeHeading, text: "one\r\ntwo\nthree", want: "one two three"},
+		"heading closing hashes":  {escape: EscapeHeading, text: "Fix issue ##", want: `Fix issue \##`},
+		"heading only hashes":     {escape: EscapeHeading, text: "#", want: `\#`},
+		"heading inner hash":      {escape: EscapeHeading, text: "C# and #1", want: "C# and #1"},
+		"heading attributes":      {escape: EscapeHeading, text: "Intro {#intro}", want: `Intro \{#intro}`},
+		"heading leading list":    {escape: EscapeHeading, text: "1. first", want: "1. first"},
+		"paragraph heading mark":  {escape: EscapeParagraph, text: "# not a heading", want: `\# not a heading`},
+		"paragraph list marks":    {escape: EscapeParagraph, text: "1. a\n2) b\n- c\n+ d", want: `1\. a` + lf() + `2\) b` + lf() + `\- c` + lf() + `\+ d`},
+		"paragraph indentation":   {escape: EscapeParagraph, text: "a\n    > b  ", want: "a" + lf() + `\> b`},
+		"paragraph breaks":        {escape: EscapeParagraph, text: `a\` + "\nb", want: `a\\` + lf() + "b"},
+		"paragraph entity":        {escape: EscapeParagraph, text: "&copy; & co", want: `\&copy; & co`},
+		"paragraph rule":          {escape: EscapeParagraph, text: "---\n***\n___", want: `\---` + lf() + `\*\*\*` + lf() + `\_\_\_`},
+		"paragraph blank line":    {escape: EscapeParagraph, text: "a\n\nb", want: "a" + lf() + lf() + "b"},
+		"list item nested marker": {escape: EscapeListItem, text: "- [ ] not a task", want: `\- \[ \] not a task`},
+		"link text brackets":      {escape: EscapeLinkText, text: "a] (b) [c", want: `a\] (b) \[c`},
+		"link text over lines":    {escape: EscapeLinkText, text: "a\n# b", want: "a # b"},
+		"cell":                    {escape: escapeCell, text: "a|*b*\nc", want: `a\|\*b\*<br>c`},
+		"cell already escaped":    {escape: escapeCell, text: `a\|b`, want: `a\\\|b`},
+		"summary":                 {escape: escapeSummary, text: "<b>bold</b> & \"q\"\nnext", want: "&lt;b&gt;bold&lt;/b&gt; &amp; &#34;q&#34; next"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.escape(tt.text); got != tt.want {
+				t.Errorf("escape(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithEscaping(t *testing.T) {
+	t.Parallel()
+
+	const hostile = "1. *Fix* | [link](x) <b>"
+	tests := map[string]struct {
+		build func(m *Markdown)
+		want  string
+	}{
+		"heading": {
+			build: func(m *Markdown) { m.H2(hostile) },
+			want:  `## 1. \*Fix\* \| \[link\](x) \<b>`,
+		},
+		"heading with an id": {
+			build: func(m *Markdown) { m.H2WithID("#", "x") },
+			want:  `## <a id="x"></a>\#`,
+		},
+		"paragraph": {
+			build: func(m *Markdown) { m.PlainTextf("%s", hostile) },
+			want:  `1\. \*Fix\* \| \[link\](x) \<b>`,
+		},
+		"bullet list": {
+			build: func(m *Markdown) { m.BulletList("- a", "b_c") },
+			want:  `- \- a` + lf() + `- b\_c`,
+		},
+		"bullet list with a blank line": {
+			build: func(m *Markdown) { m.BulletList("a\n\n# evil", "b") },
+			want:  "- a" + lf() + lf() + `  \# evil` + lf() + "- b",
+		},
+		"ordered list tree": {
+			build: func(m *Markdown) { m.OrderedListTree(Item("# a", Item("1) b"))) },
+			want:  `1. \# a` + lf() + `   1. 1\) b`,
+		},
+		"check box": {
+			build: func(m *Markdown) { m.CheckBox([]CheckBoxSet{{Text: "[x] done", Checked: false}}) },
+			want:  `- [ ] \[x\] done`,
+		},
+		"quote": {
+			build: func(m *Markdown) { m.Blockquote("> a\n- b") },
+			want:  `> \> a` + lf() + `> \- b`,
+		},
+		"alert": {
+			build: func(m *Markdown) { m.Note("> already quoted") },
+			want:  "> [!NOTE]  " + lf() + `> \> already quoted`,
+		},
+		"details": {
+			build: func(m *Markdown) { m.Details("<b>x</b>", "# y") },
+			want:  "<details>" + lf() + "<summary>&lt;b&gt;x&lt;/b&gt;</summary>" + lf() + lf() + `\# y` + lf() + lf() + "</details>" + lf(),
+		},
+		"table": {
+			build: func(m *Markdown) { m.Table(TableSet{Header: []string{"a|b"}, Rows: [][]string{{"*c*"}}}) },
+			want:  `| a\|b |` + lf() + "|---------|" + lf() + `| \*c\* |` + lf(),
+		},
+		"code block": {
+			build: func(m *Markdown) { m.CodeBlocks(SyntaxHighlightNone, "# *x*") },
+			want:  "```" + lf() + "# *x*" + lf() + "```",
+		},
+		"paragraph of inline nodes": {
+			build: func(m *Markdown) { m.Paragraph() },
+			want:  "",
+		},
+		"added blocks": {
+			build: func(m *Markdown) { m.AddBlocks(&Paragraph{Text: "**bold**"}) },
+			want:  "**bold**",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, WithEscaping())
+			tt.build(m)
+			if got := m.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithEscapingLeavesTheCallerAlone(t *testing.T) {
+	t.Parallel()
+
+	items := []ListItem{Item("*a*", Item("*b*"))}
+	set := TableSet{Header: []string{"*h*"}, Rows: [][]string{{"*c*"}}}
+	NewMarkdown(nil, WithEscaping()).BulletListTree(items...).Table(set)
+
+	if items[0].Text != "*a*" || items[0].Items[0].Text != "*b*" {
+		t.Errorf("the items were changed: %+v", items)
+	}
+	if set.Header[0] != "*h*" || set.Rows[0][0] != "*c*" {
+		t.Errorf("the table was changed: %+v", set)
+	}
+}
+
+// TestWithEscapingKeepsTheStructure renders a document built from hostile text
+// and checks that it holds exactly the blocks the builder was asked for.
+func TestWithEscapingKeepsTheStructure(t *testing.T) {
+	t.Parallel()
+
+	const hostile = "# 1. - > *a* _b_ `c` [d](e) <f> | ~~g~~ $h$ &amp; \\"
+	m := NewMarkdown(nil, WithEscaping()).
+		H2(hostile).
+		PlainText(hostile).
+		BulletList(hostile, hostile).
+		Table(TableSet{Header: []string{hostile}, Rows: [][]string{{hostile}}}).
+		CheckBox([]CheckBoxSet{{Text: "x\n\n" + hostile}})
+
+	got := renderHTML(t, m)
+	for tag, want := range map[string]int{
+		"<h2": 1, "<p>": 3, "<li>": 3, "<ul>": 2, "<th>": 1, "<td>": 1,
+		"<em>": 0, "<code>": 0, "<a ": 0, "<del>": 0, "<ol": 0, "<blockquote>": 0, "<h1": 0,
+	} {
+		if n := strings.Count(got, tag); n != want {
+			t.Errorf("the html has %d %s, want %d:\n%s", n, tag, want, got)
+		}
+	}
+	if n := strings.Count(html.UnescapeString(got), hostile); n != 7 {
+		t.Errorf("the text appears %d times, want 7:\n%s", n, got)
+	}
+}
+
+// htmlTagInOutput matches an HTML tag in rendered output.
+var htmlTagInOutput = regexp.MustCompile(`<[^>]*>`)
+
+// FuzzEscapeParagraph asserts that any line of text, escaped, reads back as
+// itself and stays one paragraph.
+func FuzzEscapeParagraph(f *testing.F) {
+	for _, seed := range []string{"# a", "1) b", "- c", "*d*", "`e`", "[f](g)", "<h>", "&amp;", "| i |", "~~j~~", "k\\", "==="} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		text = strings.Trim(text, " \t")
+		if text == "" || !utf8.ValidString(text) || strings.ContainsAny(text, "\r\n\x00") {
+			return
+		}
+
+		got := strings.TrimSpace(renderHTML(t, NewMarkdown(nil, WithEscaping()).PlainText(text)))
+		if !strings.HasPrefix(got, "<p>") || strings.Count(got, "<p>") != 1 {
+			t.Fatalf("PlainText(%q) is not one paragraph: %s", text, got)
+		}
+		if read := html.UnescapeString(htmlTagInOutput.ReplaceAllString(got, "")); read != text {
+			t.Errorf("PlainText(%q) reads back as %q", text, read)
+		}
+	})
+}
@@ -4,12 +4,18 @@ package markdown_test
 
 import (
 	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/template"
 
 	md "github.com/nao1215/markdown"
+	"github.com/nao1215/markdown/inline"
 	"github.com/nao1215/markdown/mermaid/piechart"
 	"github.com/nao1215/markdown/mermaid/sequence"
 )
@@ -226,6 +232,85 @@ func ExampleWithBlockSpacing() {
 	// Re-run the previous release job.
 }
 
+// ExampleWithLineWidth wraps prose at a column, so that a changed sentence is a
+// changed line in the diff of the generated document.
+func ExampleWithLineWidth() {
+	_ = md.NewMarkdown(os.Stdout, md.WithLineWidth(40)).
+		PlainText("Releases are cut from main every Tuesday, see [the release guide](docs/release.md) for details.").
+		BulletList("Tag the commit with `git tag -s vX.Y.Z` and push the tag to the origin remote.").
+		Build()
+
+	// Output:
+	// Releases are cut from main every
+	// Tuesday, see
+	// [the release guide](docs/release.md) for
+	// details.
+	// - Tag the commit with
+	//   `git tag -s vX.Y.Z` and push the tag
+	//   to the origin remote.
+}
+
+// ExampleWithFrontMatter writes YAML front matter above the document. A
+// string that YAML would read as something else is quoted.
+func ExampleWithFrontMatter() {
+	type page struct {
+		Title string   `yaml:"title"`
+		Draft bool     `yaml:"draft"`
+		Tags  []string `yaml:"tags,omitempty"`
+	}
+
+	_ = md.NewMarkdown(os.Stdout, md.WithFrontMatter(page{Title: "Checkout: API", Tags: []string{"api", "yes"}})).
+		H1("Checkout API").
+		Build()
+
+	// Output:
+	// ---
+	// title: 'Checkout: API'
+	// draft: false
+	// tags:
+	//   - api
+	//   - "yes"
+	// ---
+	// # Checkout API
+}
+
+// ExampleWithTOMLFrontMatter writes the front matter as TOML, the form Hugo
+// uses by default.
+func ExampleWithTOMLFrontMatter() {
+	_ = md.NewMarkdown(os.Stdout, md.WithTOMLFrontMatter(map[string]any{
+		"title":  "Release notes",
+		"weight": 10,
+		"params": map[string]string{"author": "Ana"},
+	})).
+		H1("Release notes").
+		Build()
+
+	// Output:
+	// +++
+	// title = "Release notes"
+	// weight = 10
+	//
+	// [params]
+	// author = "Ana"
+	// +++
+	// # Release notes
+}
+
+// ExampleWithStreaming writes each block as soon as it is added, so a report
+// of any length is never held in memory. Build ends the document.
+func ExampleWithStreaming() {
+	m := md.NewMarkdown(os.Stdout, md.WithStreaming()).H2("Failed jobs")
+	for _, job := range []string{"lint", "e2e"} {
+		m.PlainTextf("- %s", job)
+	}
+	_ = m.Build()
+
+	// Output:
+	// ## Failed jobs
+	// - lint
+	// - e2e
+}
+
 // ExampleMarkdown_H1 writes a level 1 heading.
 func ExampleMarkdown_H1() {
 	_ = md.NewMarkdown(os.Stdout).H1("Heading").Build()
@@ -322,6 +407,108 @@ func ExampleMarkdown_H6f() {
 	// ###### Heading 6
 }
 
+// ExampleMarkdown_H1WithID writes a heading with an explicit ID, which links
+// keep reaching when the heading is reworded.
+func ExampleMarkdown_H1WithID() {
+	_ = md.NewMarkdown(os.Stdout).H1WithID("Getting started", "start").Build()
+
+	// Output:
+	// # <a id="start"></a>Getting started
+}
+
+// ExampleMarkdown_H2WithID writes the ID as an attribute, the form MkDocs,
+// Hugo and Docusaurus read.
+func ExampleMarkdown_H2WithID() {
+	_ = md.NewMarkdown(os.Stdout, md.WithHeadingIDStyle(md.HeadingIDAttribute)).
+		H2WithID("Installation", "install").
+		Build()
+
+	// Output:
+	// ## Installation {#install}
+}
+
+// ExampleMarkdown_H3WithID writes a level 3 heading with an explicit ID.
+func ExampleMarkdown_H3WithID() {
+	_ = md.NewMarkdown(os.Stdout).H3WithID("Options", "options").Build()
+
+	// Output:
+	// ### <a id="options"></a>Options
+}
+
+// ExampleMarkdown_H4WithID writes a level 4 heading with an explicit ID.
+func ExampleMarkdown_H4WithID() {
+	_ = md.NewMarkdown(os.Stdout).H4WithID("--verbose", "flag-verbose").Build()
+
+	// Output:
+	// #### <a id="flag-verbose"></a>--verbose
+}
+
+// ExampleMarkdown_H5WithID writes a level 5 heading with an explicit ID.
+func ExampleMarkdown_H5WithID() {
+	_ = md.NewMarkdown(os.Stdout).H5WithID("Exit codes", "exit-codes").Build()
+
+	// Output:
+	// ##### <a id="exit-codes"></a>Exit codes
+}
+
+// ExampleMarkdown_H6WithID writes a level 6 heading with an explicit ID.
+func ExampleMarkdown_H6WithID() {
+	_ = md.NewMarkdown(os.Stdout).H6WithID("v1.2.0", "v1.2.0").Build()
+
+	// Output:
+	// ###### <a id="v1.2.0"></a>v1.2.0
+}
+
+// ExampleHeadingIDStyle compares the two ways an explicit ID is written.
+func ExampleHeadingIDStyle() {
+	for _, style := range []md.HeadingIDStyle{md.HeadingIDAnchor, md.HeadingIDAttribute} {
+		fmt.Println(md.NewMarkdown(nil, md.WithHeadingIDStyle(style)).H2WithID("Usage", "usage").String())
+	}
+
+	// Output:
+	// ## <a id="usage"></a>Usage
+	// ## Usage {#usage}
+}
+
+// ExampleWithHeadingIDStyle makes the table of contents link to the explicit
+// IDs, written for a site generator.
+func ExampleWithHeadingIDStyle() {
+	_ = md.NewMarkdown(os.Stdout, md.WithHeadingIDStyle(md.HeadingIDAttribute)).
+		TableOfContents(md.TableOfContentsDepthH2).
+		H2WithID("Install", "install").
+		H2WithID("Configure", "config").
+		Build()
+
+	// Output:
+	// <!-- BEGIN_TOC -->
+	// - [Install](#install)
+	// - [Configure](#config)
+	// <!-- END_TOC -->
+	//
+	// ## Install {#install}
+	// ## Configure {#config}
+}
+
+// ExampleMarkdown_CrossReference links to a heading by its ID. A link to an ID
+// no heading defines is reported by Build.
+func ExampleMarkdown_CrossReference() {
+	m := md.NewMarkdown(os.Stdout)
+	m.PlainText("Read "+m.CrossReference("the install guide", "install")+" first.").
+		H2WithID("Install", "install").
+		PlainText("See " + m.CrossReference("the FAQ", "faq") + ".")
+
+	err := m.Build()
+	fmt.Println(errors.Is(err, md.ErrUndefinedCrossReference))
+	fmt.Println(err)
+
+	// Output:
+	// Read [the install guide](#install) first.
+	// ## <a id="install"></a>Install
+	// See [the FAQ](#faq).
+	// true
+	// cross reference to #faq: cross reference to an undefined heading id
+}
+
 // ExampleMarkdown_PlainText writes a paragraph.
 func ExampleMarkdown_PlainText() {
 	_ = md.NewMarkdown(os.Stdout).PlainText("A paragraph of text.").Build()
@@ -338,6 +525,23 @@ func ExampleMarkdown_PlainTextf() {
 	// Built 3 documents in 2s.
 }
 
+// ExampleMarkdown_Paragraph builds a paragraph from inline nodes, each escaped
+// for where it is written.
+func ExampleMarkdown_Paragraph() {
+	_ = md.NewMarkdown(os.Stdout).
+		Paragraph(
+			inline.Text("Set "),
+			inline.Code("GOFLAGS=-mod=mod"),
+			inline.Text(" and see "),
+			inline.Link("https://go.dev/ref/mod#build-commands", inline.Emph(inline.Text("build commands"))),
+			inline.Text(" (rule #1: 2 * 3 = 6)."),
+		).
+		Build()
+
+	// Output:
+	// Set `GOFLAGS=-mod=mod` and see [*build commands*](https://go.dev/ref/mod#build-commands) (rule #1: 2 \* 3 = 6).
+}
+
 // ExampleMarkdown_Blockquote writes a quotation. Each line of the text is
 // prefixed, so a quotation spanning lines stays one block.
 func ExampleMarkdown_Blockquote() {
@@ -362,6 +566,74 @@ func ExampleMarkdown_CodeBlocks() {
 	// ```
 }
 
+// ExampleMarkdown_CodeBlocks_fenceInTheContent shows that content holding a
+// fence of its own gets a longer fence, so it cannot end the block early.
+func ExampleMarkdown_CodeBlocks_fenceInTheContent() {
+	_ = md.NewMarkdown(os.Stdout).
+		CodeBlocks("markdown", "```go\nx := 1\n```").
+		Build()
+
+	// Output:
+	// ````markdown
+	// ```go
+	// x := 1
+	// ```
+	// ````
+}
+
+// ExampleMarkdown_CustomCodeBlock writes a code block with a title and
+// highlighted lines, the way mkdocs-material reads them.
+func ExampleMarkdown_CustomCodeBlock() {
+	_ = md.NewMarkdown(os.Stdout).
+		CustomCodeBlock(md.SyntaxHighlightGo, "a := 1\nb := 2\nc := a + b", md.CodeBlockOptions{
+			Format:         md.CodeBlockFormatMkDocs,
+			Title:          "sum.go",
+			LineNumbers:    true,
+			HighlightLines: []int{1, 2},
+		}).
+		Build()
+
+	// Output:
+	// ```go title="sum.go" linenums="1" hl_lines="1-2"
+	// a := 1
+	// b := 2
+	// c := a + b
+	// ```
+}
+
+// ExampleCodeBlockOptions writes the same attributes for Docusaurus.
+func ExampleCodeBlockOptions() {
+	_ = md.NewMarkdown(os.Stdout).
+		CustomCodeBlock(md.SyntaxHighlightJavaScript, "console.log(1)", md.CodeBlockOptions{
+			Format:         md.CodeBlockFormatDocusaurus,
+			Title:          "index.js",
+			LineNumbers:    true,
+			HighlightLines: []int{1},
+		}).
+		Build()
+
+	// Output:
+	// ```javascript title="index.js" {1} showLineNumbers
+	// console.log(1)
+	// ```
+}
+
+// ExampleCodeBlockFormat writes line numbers starting at 40 for Hugo.
+func ExampleCodeBlockFormat() {
+	_ = md.NewMarkdown(os.Stdout).
+		CustomCodeBlock(md.SyntaxHighlightGo, "return nil", md.CodeBlockOptions{
+			Format:          md.CodeBlockFormatHugo,
+			LineNumbers:     true,
+			LineNumberStart: 40,
+		}).
+		Build()
+
+	// Output:
+	// ```go {linenos=true linenostart=40}
+	// return nil
+	// ```
+}
+
 // ExampleMarkdown_HorizontalRule writes a thematic break.
 func ExampleMarkdown_HorizontalRule() {
 	_ = md.NewMarkdown(os.Stdout).
@@ -426,6 +698,31 @@ func ExampleMarkdown_Details() {
 	// </details>
 }
 
+// ExampleMarkdown_DetailsBlocks puts a table inside a collapsible section
+// that starts expanded.
+func ExampleMarkdown_DetailsBlocks() {
+	_ = md.NewMarkdown(os.Stdout).
+		DetailsBlocks("Benchmarks", true, func(m *md.Markdown) {
+			m.PlainText("Run on the CI machine.").
+				Table(md.TableSet{
+					Header: []string{"Name", "ns/op"},
+					Rows:   [][]string{{"Build", "1200"}},
+				})
+		}).
+		Build()
+
+	// Output:
+	// <details open>
+	// <summary>Benchmarks</summary>
+	//
+	// Run on the CI machine.
+	// | Name | ns/op |
+	// |---------|---------|
+	// | Build | 1200 |
+	//
+	// </details>
+}
+
 // ExampleMarkdown_Detailsf writes a collapsible section from a format string.
 func ExampleMarkdown_Detailsf() {
 	_ = md.NewMarkdown(os.Stdout).
@@ -502,6 +799,157 @@ func ExampleMarkdown_Table() {
 	// | John | 30 |
 }
 
+// ExampleTableSetFromStructs builds a table from a slice of structs, with the
+// md tags naming, aligning and formatting the columns.
+func ExampleTableSetFromStructs() {
+	type release struct {
+		Version   string  `md:"Version"`
+		Downloads int     `md:"Downloads,align=right"`
+		Size      float64 `md:"Size (MB),align=right,format=%.1f"`
+		Notes     string  `md:",omitempty"`
+		internal  bool
+	}
+
+	t, err := md.TableSetFromStructs([]release{
+		{Version: "v1.1.0", Downloads: 1200, Size: 4.25, Notes: "adds HTML"},
+		{Version: "v1.0.0", Downloads: 9800, Size: 4.1},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_ = md.NewMarkdown(os.Stdout).CustomTable(t, md.TableOptions{}).Build()
+
+	// Output:
+	// | Version | Downloads | Size (MB) |   Notes   |
+	// |---------|----------:|----------:|-----------|
+	// | v1.1.0  | 1200      | 4.2       | adds HTML |
+	// | v1.0.0  | 9800      | 4.1       |           |
+}
+
+// ExampleTableSetFromCSV builds a table from CSV. The cells are escaped, so a
+// pipe in the data does not split a cell.
+func ExampleTableSetFromCSV() {
+	data := "name,role\nana,admin | owner\nbo,viewer\n"
+
+	t, err := md.TableSetFromCSV(csv.NewReader(strings.NewReader(data)))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_ = md.NewMarkdown(os.Stdout).CustomTable(t, md.TableOptions{}).Build()
+
+	// Output:
+	// | name |      role      |
+	// |------|----------------|
+	// | ana  | admin \| owner |
+	// | bo   | viewer         |
+}
+
+// ExampleTableSetFromTSV builds a table from tab-separated values.
+func ExampleTableSetFromTSV() {
+	data := "os\tarch\nlinux\tamd64\ndarwin\tarm64\n"
+
+	t, err := md.TableSetFromTSV(strings.NewReader(data))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_ = md.NewMarkdown(os.Stdout).CustomTable(t, md.TableOptions{}).Build()
+
+	// Output:
+	// |   os   | arch  |
+	// |--------|-------|
+	// | linux  | amd64 |
+	// | darwin | arm64 |
+}
+
+// ExampleTableSetFromSQLRows builds a table from the result of a query. The
+// driver here is a test double that answers with the table its data source
+// name spells out; any database/sql driver works the same way.
+func ExampleTableSetFromSQLRows() {
+	db, err := sql.Open("markdown-test", "id\tuser\n1\tana\n2\tNULL")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, user FROM accounts")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t, err := md.TableSetFromSQLRows(rows)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_ = md.NewMarkdown(os.Stdout).CustomTable(t, md.TableOptions{}).Build()
+
+	// Output:
+	// | id | user |
+	// |----|------|
+	// | 1  | ana  |
+	// | 2  |      |
+}
+
+// ExampleTableReadOption passes options to a table read from elsewhere.
+func ExampleTableReadOption() {
+	opts := []md.TableReadOption{md.WithMaxRows(1), md.WithTruncationNote("+%d hidden")}
+
+	t, err := md.TableSetFromTSV(strings.NewReader("n\n1\n2\n"), opts...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(t.Rows)
+
+	// Output:
+	// [[1] [+1 hidden]]
+}
+
+// ExampleWithMaxRows keeps the first rows of a long input and notes how many
+// were left out.
+func ExampleWithMaxRows() {
+	t, err := md.TableSetFromCSV(
+		csv.NewReader(strings.NewReader("n\n1\n2\n3\n4\n5\n")),
+		md.WithMaxRows(2),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_ = md.NewMarkdown(os.Stdout).CustomTable(t, md.TableOptions{}).Build()
+
+	// Output:
+	// |       n       |
+	// |---------------|
+	// | 1             |
+	// | 2             |
+	// | … 3 more rows |
+}
+
+// ExampleWithTruncationNote replaces the note row WithMaxRows adds.
+func ExampleWithTruncationNote() {
+	t, err := md.TableSetFromCSV(
+		csv.NewReader(strings.NewReader("n,square\n1,1\n2,4\n3,9\n")),
+		md.WithMaxRows(1),
+		md.WithTruncationNote("see the full list for %d more"),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_ = md.NewMarkdown(os.Stdout).CustomTable(t, md.TableOptions{}).Build()
+
+	// Output:
+	// |              n               | square |
+	// |------------------------------|--------|
+	// | 1                            | 1      |
+	// | see the full list for 2 more |        |
+}
+
 // ExampleMarkdown_CustomTable writes a table with the alignment row spelled
 // out. Without the options every column is left aligned.
 func ExampleMarkdown_CustomTable() {
@@ -551,6 +999,112 @@ func ExampleEscapeTableCell() {
 	// multi<br>line
 }
 
+// ExampleWithEscaping writes text from elsewhere, such as issue titles, as
+// literal text: nothing in it can start a heading, a list or a table cell.
+func ExampleWithEscaping() {
+	title := "1. Crash when the path holds a | or a *"
+	_ = md.NewMarkdown(os.Stdout, md.WithEscaping()).
+		H2(title).
+		BulletList(title, "# not a heading").
+		Build()
+
+	// Output:
+	// ## 1. Crash when the path holds a \| or a \*
+	// - 1\. Crash when the path holds a \| or a \*
+	// - \# not a heading
+}
+
+func ExampleEscapeHeading() {
+	fmt.Println("## " + md.EscapeHeading("Support for C# and *nix {beta} #"))
+
+	// Output:
+	// ## Support for C# and \*nix \{beta} \#
+}
+
+func ExampleEscapeParagraph() {
+	fmt.Println(md.EscapeParagraph("2024. A year of <progress>\n- and [links]"))
+
+	// Output:
+	// 2024\. A year of \<progress>
+	// \- and \[links\]
+}
+
+func ExampleEscapeListItem() {
+	fmt.Println("- " + md.EscapeListItem("- [ ] not a nested task"))
+
+	// Output:
+	// - \- \[ \] not a nested task
+}
+
+func ExampleEscapeLinkText() {
+	fmt.Println(md.Link(md.EscapeLinkText("[WIP] fix_it"), "https://example.com/pull/1"))
+
+	// Output:
+	// [\[WIP\] fix\_it](https://example.com/pull/1)
+}
+
+// ExampleMarkdown_Lint checks the document as markdownlint would, so a test
+// can fail on a finding.
+func ExampleMarkdown_Lint() {
+	m := md.NewMarkdown(io.Discard).
+		H1("Report").
+		H3("Details").
+		CodeBlocks(md.SyntaxHighlightNone, "raw output")
+
+	for _, f := range m.Lint() {
+		fmt.Println(f)
+	}
+
+	// Output:
+	// 2: MD001/heading-increment heading level jumps from h1 to h3
+	// 3: MD040/fenced-code-language fenced code block has no language
+}
+
+// ExampleMarkdown_CheckLinks checks the links of the document before it is
+// written. Anchors follow the headings the document has, and files are looked
+// up in the directory the document is going to be written to.
+func ExampleMarkdown_CheckLinks() {
+	m := md.NewMarkdown(io.Discard).
+		H1("Guide").
+		H2("Install").
+		PlainText("See " + md.Link("usage", "#usage") + " and " + md.Link("the changelog", "CHANGELOG.md") + ".").
+		PlainText(md.Link("Go", "https:///go.dev"))
+
+	broken, err := m.CheckLinks(os.TempDir())
+	if err != nil {
+		fmt.Println("check:", err)
+		return
+	}
+	for _, b := range broken {
+		fmt.Println(b)
+	}
+
+	// Output:
+	// 3: #usage: no heading or anchor with the id usage
+	// 3: CHANGELOG.md: no such file or directory
+	// 4: https:///go.dev: URL has no host
+}
+
+// ExampleBrokenLink shows the fields of a broken link.
+func ExampleBrokenLink() {
+	b := md.BrokenLink{File: "docs/guide.md", Line: 12, Target: "setup.md", Reason: "no such file or directory"}
+	fmt.Println(b.File, b.Line, b.Target)
+
+	// Output:
+	// docs/guide.md 12 setup.md
+}
+
+// ExampleBrokenLink_String prints a broken link the way a compiler reports a
+// position, so editors can jump to it.
+func ExampleBrokenLink_String() {
+	fmt.Println(md.BrokenLink{File: "docs/guide.md", Line: 12, Target: "#setup", Reason: "no heading or anchor with the id setup"})
+	fmt.Println(md.BrokenLink{Line: 3, Target: "faq.md", Reason: "no such file or directory"})
+
+	// Output:
+	// docs/guide.md:12: #setup: no heading or anchor with the id setup
+	// 3: faq.md: no such file or directory
+}
+
 // ExampleMarkdown_TableOfContents writes a table of contents built from the
 // headings of the document. It may be called before the headings it lists:
 // the list is filled in at Build.
@@ -745,6 +1299,26 @@ func ExampleMarkdown_Caution() {
 	// "> [!CAUTION]  \n> Advises about the risks of an action.\n"
 }
 
+// ExampleMarkdown_AlertBlocks puts a list and a code block inside an alert,
+// with a title GitLab shows instead of the name of the kind.
+func ExampleMarkdown_AlertBlocks() {
+	_ = md.NewMarkdown(os.Stdout, md.WithDialect(md.DialectGitLab)).
+		AlertBlocks(md.AlertKindWarning, "Before you upgrade", func(m *md.Markdown) {
+			m.BulletList("Back up the database.", "Stop the workers.").
+				CodeBlocks(md.SyntaxHighlightShell, "make migrate")
+		}).
+		Build()
+
+	// Output:
+	// > [!warning] Before you upgrade
+	// > - Back up the database.
+	// > - Stop the workers.
+	// >
+	// > ```shell
+	// > make migrate
+	// > ```
+}
+
 // ExampleMarkdown_Cautionf writes a GitHub CAUTION alert from a format string.
 func ExampleMarkdown_Cautionf() {
 	buf := &bytes.Buffer{}
@@ -829,40 +1403,204 @@ func ExampleMarkdown_BlueBadgef() {
 	// ![Badge](https://img.shields.io/badge/coverage 96%-blue)
 }
 
-// ExampleBold returns the inline markup rather than writing it, so it can be
-// put inside any text a builder takes.
-func ExampleBold() {
-	_ = md.NewMarkdown(os.Stdout).
-		PlainTextf("This word is %s.", md.Bold("important")).
-		Build()
+// ExampleBadge describes a badge with a label, a color, a style, a logo and a
+// link, and writes it as markdown.
+func ExampleBadge() {
+	b := md.Badge{
+		Label:   "go",
+		Message: "reference",
+		Color:   "#007d9c",
+		Style:   md.BadgeStyleFlatSquare,
+		Logo:    "go",
+		Link:    "https://pkg.go.dev/github.com/nao1215/markdown",
+	}
+	fmt.Println(b)
 
 	// Output:
-	// This word is **important**.
+	// [![go: reference](https://img.shields.io/badge/go-reference-007d9c?logo=go&style=flat-square)](https://pkg.go.dev/github.com/nao1215/markdown)
 }
 
-// ExampleItalic returns the inline markup rather than writing it, so it can be
-// put inside any text a builder takes.
-func ExampleItalic() {
-	_ = md.NewMarkdown(os.Stdout).
-		PlainTextf("This word is %s.", md.Italic("emphasis")).
-		Build()
+// ExampleBadgeStyle draws the same badge in the larger style.
+func ExampleBadgeStyle() {
+	fmt.Println(md.Badge{Label: "license", Message: "MIT", Color: "blue", Style: md.BadgeStyleForTheBadge}.URL())
 
 	// Output:
-	// This word is *emphasis*.
+	// https://img.shields.io/badge/license-MIT-blue?style=for-the-badge
 }
 
-// ExampleBoldItalic returns the inline markup rather than writing it, so it can be
-// put inside any text a builder takes.
-func ExampleBoldItalic() {
-	_ = md.NewMarkdown(os.Stdout).
-		PlainTextf("This word is %s.", md.BoldItalic("both")).
-		Build()
+// ExampleBadge_URL escapes the label and the message into the path the way
+// shields.io reads them: a dash and an underscore are doubled, and a space is
+// an underscore.
+func ExampleBadge_URL() {
+	fmt.Println(md.Badge{Label: "code coverage", Message: "96%", Color: "brightgreen"}.URL())
+	fmt.Println(md.Badge{Label: "go-version", Message: "1.23_or_later"}.URL())
+	fmt.Println(md.Badge{Label: "coverage", Endpoint: "https://example.com/coverage.json"}.URL())
 
 	// Output:
-	// This word is ***both***.
+	// https://img.shields.io/badge/code_coverage-96%25-brightgreen
+	// https://img.shields.io/badge/go--version-1.23__or__later-lightgrey
+	// https://img.shields.io/endpoint?url=https%3A%2F%2Fexample.com%2Fcoverage.json
 }
 
-// ExampleCode returns the inline markup rather than writing it, so it can be
+// ExampleBadge_String writes a badge as an image, inside a link when it has
+// one.
+func ExampleBadge_String() {
+	fmt.Println(md.Badge{Label: "build", Message: "passing", Color: "green"}.String())
+	fmt.Println(md.Badge{Message: "docs", Color: "blue", Link: "https://example.com/docs"}.String())
+
+	// Output:
+	// ![build: passing](https://img.shields.io/badge/build-passing-green)
+	// [![docs](https://img.shields.io/badge/docs-blue)](https://example.com/docs)
+}
+
+// ExampleBadge_EndpointJSON writes the file a dynamic badge reads its label,
+// message and color from.
+func ExampleBadge_EndpointJSON() {
+	out, err := md.Badge{Label: "coverage", Message: "96%", Color: "brightgreen"}.EndpointJSON()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(string(out))
+
+	// Output:
+	// {
+	//   "schemaVersion": 1,
+	//   "label": "coverage",
+	//   "message": "96%",
+	//   "color": "brightgreen"
+	// }
+}
+
+// ExampleBadge_WriteEndpointFile writes the endpoint JSON CI publishes for a
+// dynamic badge.
+func ExampleBadge_WriteEndpointFile() {
+	dir, err := os.MkdirTemp("", "markdown-badges")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "coverage.json")
+	if err := (md.Badge{Label: "coverage", Message: "81%", Color: "yellow"}).WriteEndpointFile(path); err != nil {
+		fmt.Println(err)
+		return
+	}
+	out, err := os.ReadFile(path) //nolint:gosec // the path is the example's own
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(string(out))
+
+	// Output:
+	// {
+	//   "schemaVersion": 1,
+	//   "label": "coverage",
+	//   "message": "81%",
+	//   "color": "yellow"
+	// }
+}
+
+// ExampleMarkdown_Badge writes one badge as a paragraph of its own.
+func ExampleMarkdown_Badge() {
+	_ = md.NewMarkdown(os.Stdout).
+		Badge(md.Badge{Label: "build", Message: "passing", Color: "brightgreen", Logo: "github"}).
+		Build()
+
+	// Output:
+	// ![build: passing](https://img.shields.io/badge/build-passing-brightgreen?logo=github)
+}
+
+func ExampleCustomImage() {
+	fmt.Println(md.CustomImage("logo", "logo.png", md.ImageOptions{Link: "https://example.com"}))
+	fmt.Println(md.CustomImage("logo", "logo.png", md.ImageOptions{Width: "120", Align: md.AlignRight}))
+
+	// Output:
+	// [![logo](logo.png)](https://example.com)
+	// <img src="logo.png" alt="logo" width="120" align="right">
+}
+
+func ExampleImageOptions() {
+	fmt.Println(md.CustomImage("logo", "light.png", md.ImageOptions{
+		DarkURL: "dark.png",
+		Width:   "200",
+		Align:   md.AlignCenter,
+	}))
+
+	// Output:
+	// <p align="center">
+	// <picture>
+	//   <source media="(prefers-color-scheme: dark)" srcset="dark.png">
+	//   <img src="light.png" alt="logo" width="200">
+	// </picture>
+	// </p>
+}
+
+func ExampleMarkdown_CustomImage() {
+	_ = md.NewMarkdown(os.Stdout).
+		H1("Project").
+		CustomImage("Screenshot", "screenshot.png", md.ImageOptions{Width: "50%", Link: "screenshot.png"}).
+		PlainText("A screenshot of the project.").
+		Build()
+
+	// Output:
+	// # Project
+	// <a href="screenshot.png"><img src="screenshot.png" alt="Screenshot" width="50%"></a>
+	//
+	// A screenshot of the project.
+}
+
+// ExampleMarkdown_Badges writes the row of badges at the top of a README. A
+// badge without a message is recorded as an error and left out.
+func ExampleMarkdown_Badges() {
+	m := md.NewMarkdown(os.Stdout).Badges(
+		md.Badge{Label: "build", Message: "passing", Color: "green"},
+		md.Badge{Label: "license", Message: "MIT", Color: "blue"},
+		md.Badge{Label: "forgotten"},
+	)
+	fmt.Println(m.Build())
+
+	// Output:
+	// ![build: passing](https://img.shields.io/badge/build-passing-green) ![license: MIT](https://img.shields.io/badge/license-MIT-blue)
+	// badge "forgotten" has no message
+}
+
+// ExampleBold returns the inline markup rather than writing it, so it can be
+// put inside any text a builder takes.
+func ExampleBold() {
+	_ = md.NewMarkdown(os.Stdout).
+		PlainTextf("This word is %s.", md.Bold("important")).
+		Build()
+
+	// Output:
+	// This word is **important**.
+}
+
+// ExampleItalic returns the inline markup rather than writing it, so it can be
+// put inside any text a builder takes.
+func ExampleItalic() {
+	_ = md.NewMarkdown(os.Stdout).
+		PlainTextf("This word is %s.", md.Italic("emphasis")).
+		Build()
+
+	// Output:
+	// This word is *emphasis*.
+}
+
+// ExampleBoldItalic returns the inline markup rather than writing it, so it can be
+// put inside any text a builder takes.
+func ExampleBoldItalic() {
+	_ = md.NewMarkdown(os.Stdout).
+		PlainTextf("This word is %s.", md.BoldItalic("both")).
+		Build()
+
+	// Output:
+	// This word is ***both***.
+}
+
+// ExampleCode returns the inline markup rather than writing it, so it can be
 // put inside any text a builder takes.
 func ExampleCode() {
 	_ = md.NewMarkdown(os.Stdout).
@@ -939,6 +1677,44 @@ func ExampleReferenceLinkDefinition() {
 	// [go]: https://go.dev "The Go website"
 }
 
+// ExampleMarkdown_Footnote numbers footnotes as they are used and writes their
+// definitions at the end of the document.
+func ExampleMarkdown_Footnote() {
+	m := md.NewMarkdown(os.Stdout)
+	m.PlainText("Markdown was created in 2004" + m.Footnote("Gruber, Daring Fireball.") + ".").
+		PlainText("CommonMark specified it in 2014" + m.Footnote("commonmark.org") + ".").
+		PlainText("Both are widely used" + m.Footnote("Gruber, Daring Fireball.") + ".")
+	_ = m.Build()
+
+	// Output:
+	// Markdown was created in 2004[^1].
+	// CommonMark specified it in 2014[^2].
+	// Both are widely used[^1].
+	//
+	// [^1]: Gruber, Daring Fireball.
+	// [^2]: commonmark.org
+}
+
+// ExampleMarkdown_ReferenceLink writes reference links whose definitions are
+// collected at the end of the document, one per URL.
+func ExampleMarkdown_ReferenceLink() {
+	m := md.NewMarkdown(os.Stdout)
+	m.BulletList(
+		m.ReferenceLink("Go", "https://go.dev", "The Go website"),
+		m.ReferenceLink("packages", "https://pkg.go.dev"),
+		m.ReferenceLink("the Go website", "https://go.dev"),
+	)
+	_ = m.Build()
+
+	// Output:
+	// - [Go][1]
+	// - [packages][2]
+	// - [the Go website][1]
+	//
+	// [1]: https://go.dev "The Go website"
+	// [2]: https://pkg.go.dev
+}
+
 // ExampleFootnoteReference returns the marker that points at a footnote.
 func ExampleFootnoteReference() {
 	_ = md.NewMarkdown(os.Stdout).
@@ -982,6 +1758,88 @@ func ExampleBlockMath() {
 	// $$
 }
 
+// ExampleDialect names the platforms a document can be written for.
+func ExampleDialect() {
+	for _, d := range []md.Dialect{md.DialectGFM, md.DialectGitLab, md.DialectAzureDevOps} {
+		fmt.Println(d)
+	}
+
+	// Output:
+	// GitHub Flavored Markdown
+	// GitLab Flavored Markdown
+	// Azure DevOps
+}
+
+// ExampleDialect_String prints the name of a dialect, as error messages do.
+func ExampleDialect_String() {
+	fmt.Println(md.DialectBitbucket.String())
+
+	// Output:
+	// Bitbucket
+}
+
+// ExampleWithDialect writes the same blocks for GitLab: the alert marker is
+// lower case, and GitLab draws the table of contents itself.
+func ExampleWithDialect() {
+	_ = md.NewMarkdown(os.Stdout, md.WithDialect(md.DialectGitLab)).
+		H1("Runbook").
+		TableOfContents(md.TableOfContentsDepthH2).
+		H2("Restart").
+		Warning("Drain the node first.").
+		Build()
+
+	// Output:
+	// # Runbook
+	// <!-- BEGIN_TOC -->
+	// [[_TOC_]]
+	// <!-- END_TOC -->
+	//
+	// ## Restart
+	// > [!warning]
+	// > Drain the node first.
+}
+
+// ExampleMarkdown_Dialect spells inline text for the platform the document is
+// written for.
+func ExampleMarkdown_Dialect() {
+	m := md.NewMarkdown(os.Stdout, md.WithDialect(md.DialectBitbucket))
+	_ = m.PlainTextf("Only %s is kept.", m.Dialect().Highlight("the latest")).Build()
+
+	// Output:
+	// Only **the latest** is kept.
+}
+
+// ExampleDialect_InlineMath writes inline math the way GitLab reads it, and
+// as a code span where there is no math.
+func ExampleDialect_InlineMath() {
+	fmt.Println(md.DialectGitLab.InlineMath("a^2+b^2"))
+	fmt.Println(md.DialectCommonMark.InlineMath("a^2+b^2"))
+
+	// Output:
+	// $`a^2+b^2`$
+	// `a^2+b^2`
+}
+
+// ExampleDialect_BlockMath writes display math as a math code block, which is
+// how GitLab reads it.
+func ExampleDialect_BlockMath() {
+	fmt.Println(md.DialectGitLab.BlockMath("e^{i\\pi} = -1"))
+
+	// Output:
+	// ```math
+	// e^{i\pi} = -1
+	// ```
+}
+
+// ExampleDialect_Highlight highlights text with the <mark> element, which
+// GitHub reads where it ignores ==text==.
+func ExampleDialect_Highlight() {
+	fmt.Println(md.DialectGFM.Highlight("breaking"))
+
+	// Output:
+	// <mark>breaking</mark>
+}
+
 // ExampleMarkdown_Build writes the document and reports the first error the
 // chain recorded. Nothing in the chain panics on bad input, so one check at
 // the end is enough.
@@ -1022,41 +1880,110 @@ func ExampleMarkdown_String() {
 	// "## Summary\nAll green."
 }
 
-// ExampleGenerateIndex writes an index of the markdown files under a directory.
-// WithWriter sends it somewhere other than the index.md the function would
-// otherwise create, which is what makes the output here worth showing.
-func ExampleGenerateIndex() {
-	parent, err := os.MkdirTemp("", "markdown-index")
+// ExampleRegionMarkerBegin prints the marker lines a hand-written document
+// places around a region a generator keeps up to date.
+func ExampleRegionMarkerBegin() {
+	fmt.Println(md.RegionMarkerBegin("api"))
+	fmt.Println(md.RegionMarkerEnd("api"))
+
+	// Output:
+	// <!-- BEGIN_GENERATED:api -->
+	// <!-- END_GENERATED:api -->
+}
+
+// ExampleRegionMarkerEnd opens a document with an empty region, ready for
+// UpdateRegions to fill.
+func ExampleRegionMarkerEnd() {
+	_ = md.NewMarkdown(os.Stdout).
+		H2("Flags").
+		PlainText(md.RegionMarkerBegin("flags")).
+		PlainText(md.RegionMarkerEnd("flags")).
+		Build()
+
+	// Output:
+	// ## Flags
+	// <!-- BEGIN_GENERATED:flags -->
+	// <!-- END_GENERATED:flags -->
+}
+
+// ExampleUpdateRegions replaces what is between the markers of a region and
+// keeps everything else as the author wrote it.
+func ExampleUpdateRegions() {
+	readme := `# tool
+
+Hand-written introduction.
+
+<!-- BEGIN_GENERATED:flags -->
+stale
+<!-- END_GENERATED:flags -->
+
+Hand-written footer.
+`
+	flags := md.NewMarkdown(nil).CustomTable(md.TableSet{
+		Header: []string{"Flag", "Meaning"},
+		Rows:   [][]string{{"-v", "verbose"}},
+	}, md.TableOptions{})
+
+	updated, err := md.UpdateRegions(readme, map[string]*md.Markdown{"flags": flags})
 	if err != nil {
-		fmt.Println("temp dir:", err)
+		fmt.Println(err)
 		return
 	}
-	defer func() { _ = os.RemoveAll(parent) }()
+	fmt.Print(updated)
 
-	// The index is headed with the name of the directory it describes, so the
-	// directory is named here rather than left as the random one MkdirTemp
-	// makes, which would put a different heading in the output on every run.
-	dir := filepath.Join(parent, "guide")
-	if err := os.Mkdir(dir, 0o750); err != nil {
-		fmt.Println("mkdir:", err)
+	// Output:
+	// # tool
+	//
+	// Hand-written introduction.
+	//
+	// <!-- BEGIN_GENERATED:flags -->
+	// | Flag | Meaning |
+	// |------|---------|
+	// | -v   | verbose |
+	// <!-- END_GENERATED:flags -->
+	//
+	// Hand-written footer.
+}
+
+// ExampleUpdateFileRegions rewrites the regions of a file in place.
+func ExampleUpdateFileRegions() {
+	dir, err := os.MkdirTemp("", "markdown-regions")
+	if err != nil {
+		fmt.Println("temp dir:", err)
 		return
 	}
-	if err := os.WriteFile(filepath.Join(dir, "install.md"), []byte("# Install\n"), 0o600); err != nil {
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "README.md")
+	text := "# tool\n" + md.RegionMarkerBegin("version") + "\n" + md.RegionMarkerEnd("version") + "\n"
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
 		fmt.Println("write:", err)
 		return
 	}
 
-	if err := md.GenerateIndex(dir, md.WithWriter(os.Stdout)); err != nil {
-		fmt.Println("generate:", err)
+	version := md.NewMarkdown(nil).PlainTextf("Latest release: %s", "v1.4.0")
+	if err := md.UpdateFileRegions(path, map[string]*md.Markdown{"version": version}); err != nil {
+		fmt.Println(err)
+		return
+	}
+	out, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		fmt.Println("read:", err)
+		return
 	}
+	fmt.Print(string(out))
 
 	// Output:
-	// ### guide
-	// - [Install](install.md)
+	// # tool
+	// <!-- BEGIN_GENERATED:version -->
+	// Latest release: v1.4.0
+	// <!-- END_GENERATED:version -->
 }
 
-// ExampleWithTitle sets the heading the generated index opens with.
-func ExampleWithTitle() {
+// ExampleGenerateIndex writes an index of the markdown files under a directory.
+// WithWriter sends it somewhere other than the index.md the function would
+// otherwise create, which is what makes the output here worth showing.
+func ExampleGenerateIndex() {
 	parent, err := os.MkdirTemp("", "markdown-index")
 	if err != nil {
 		fmt.Println("temp dir:", err)
@@ -1077,99 +2004,453 @@ func ExampleWithTitle() {
 		return
 	}
 
-	if err := md.GenerateIndex(dir, md.WithTitle("Documentation"), md.WithWriter(os.Stdout)); err != nil {
+	if err := md.GenerateIndex(dir, md.WithWriter(os.Stdout)); err != nil {
 		fmt.Println("generate:", err)
 	}
 
 	// Output:
-	// ## Documentation
 	// ### guide
 	// - [Install](install.md)
 }
 
-// ExampleWithDescription sets the paragraphs written under the index heading.
-func ExampleWithDescription() {
-	parent, err := os.MkdirTemp("", "markdown-index")
+// ExampleCheckLinksInDir checks every markdown file under a directory and
+// reports the links that point nowhere, with the file and line they are on.
+func ExampleCheckLinksInDir() {
+	dir, err := os.MkdirTemp("", "markdown-links")
 	if err != nil {
 		fmt.Println("temp dir:", err)
 		return
 	}
-	defer func() { _ = os.RemoveAll(parent) }()
+	defer func() { _ = os.RemoveAll(dir) }()
 
-	// The index is headed with the name of the directory it describes, so the
-	// directory is named here rather than left as the random one MkdirTemp
-	// makes, which would put a different heading in the output on every run.
-	dir := filepath.Join(parent, "guide")
-	if err := os.Mkdir(dir, 0o750); err != nil {
-		fmt.Println("mkdir:", err)
-		return
+	files := map[string]string{
+		"README.md": "# Project\n\nRead [the guide](guide.md#usage) and [the FAQ](faq.md).\n",
+		"guide.md":  "# Guide\n\n## Install\n\nBack to [the project](README.md#project).\n",
 	}
-	if err := os.WriteFile(filepath.Join(dir, "install.md"), []byte("# Install\n"), 0o600); err != nil {
-		fmt.Println("write:", err)
-		return
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			fmt.Println("write:", err)
+			return
+		}
 	}
 
-	buf := &bytes.Buffer{}
-	err = md.GenerateIndex(dir,
-		md.WithDescription([]string{"Every page in this directory.", "Regenerated on each release."}),
-		md.WithWriter(buf),
-	)
+	broken, err := md.CheckLinksInDir(dir)
 	if err != nil {
-		fmt.Println("generate:", err)
+		fmt.Println("check:", err)
 		return
 	}
-	// Printed quoted because each description line ends with the two spaces
-	// markdown reads as a hard line break, and a godoc Output block cannot hold
-	// trailing whitespace.
-	fmt.Printf("%q\n", buf.String())
+	for _, b := range broken {
+		fmt.Println(b)
+	}
 
 	// Output:
-	// "Every page in this directory.\n  \nRegenerated on each release.\n  \n### guide\n- [Install](install.md)\n  \n"
+	// README.md:3: guide.md#usage: no heading or anchor with the id usage
+	// README.md:3: faq.md: no such file or directory
 }
 
-// ExampleWithWriter sends the index somewhere other than the index.md the
-// function creates by default, so it can be inspected or embedded rather than
-// written to disk.
-func ExampleWithWriter() {
-	parent, err := os.MkdirTemp("", "markdown-index")
+// ExampleFormat rewrites hand-written markdown the way this package writes it.
+func ExampleFormat() {
+	source := "Usage\n=====\n* build\n* test\n\n|Flag|Meaning|\n|-|-|\n|-v|verbose|\n```\nmake\n```\n"
+
+	fmt.Print(md.Format(source))
+
+	// Output:
+	// # Usage
+	//
+	// - build
+	// - test
+	//
+	// | Flag | Meaning |
+	// |------|---------|
+	// | -v   | verbose |
+	//
+	// ```text
+	// make
+	// ```
+}
+
+// ExampleCheckFormat shows the diff a CI job prints for a file that is not
+// formatted.
+func ExampleCheckFormat() {
+	fmt.Print(md.CheckFormat("docs/guide.md", "# Guide\n* step one\n* step two\n"))
+
+	// Output:
+	// --- a/docs/guide.md
+	// +++ b/docs/guide.md
+	// @@ -1,3 +1,4 @@
+	//  # Guide
+	// -* step one
+	// -* step two
+	// +
+	// +- step one
+	// +- step two
+}
+
+// ExampleFormatInDir formats every markdown file under a directory in place.
+func ExampleFormatInDir() {
+	dir, err := os.MkdirTemp("", "markdown-format")
 	if err != nil {
 		fmt.Println("temp dir:", err)
 		return
 	}
-	defer func() { _ = os.RemoveAll(parent) }()
+	defer func() { _ = os.RemoveAll(dir) }()
 
-	// The index is headed with the name of the directory it describes, so the
-	// directory is named here rather than left as the random one MkdirTemp
-	// makes, which would put a different heading in the output on every run.
-	dir := filepath.Join(parent, "guide")
-	if err := os.Mkdir(dir, 0o750); err != nil {
-		fmt.Println("mkdir:", err)
-		return
+	files := map[string]string{
+		"README.md": "# Project\n\n- done\n",
+		"guide.md":  "Guide\n-----\n+ install\n+ run\n",
 	}
-	if err := os.WriteFile(filepath.Join(dir, "install.md"), []byte("# Install\n"), 0o600); err != nil {
-		fmt.Println("write:", err)
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			fmt.Println("write:", err)
+			return
+		}
+	}
+
+	changed, err := md.FormatInDir(dir)
+	if err != nil {
+		fmt.Println("format:", err)
 		return
 	}
+	fmt.Println(changed)
 
-	buf := &bytes.Buffer{}
-	if err := md.GenerateIndex(dir, md.WithWriter(buf)); err != nil {
-		fmt.Println("generate:", err)
+	guide, err := os.ReadFile(filepath.Join(dir, "guide.md"))
+	if err != nil {
+		fmt.Println("read:", err)
 		return
 	}
-	fmt.Print(buf.String())
+	fmt.Print(string(guide))
 
 	// Output:
-	// ### guide
-	// - [Install](install.md)
+	// [guide.md]
+	// ## Guide
+	//
+	// - install
+	// - run
 }
 
-// ExampleTableSet shows the shape a table is described with. Every row must
-// have as many cells as the header.
-func ExampleTableSet() {
-	set := md.TableSet{
-		Header: []string{"Package", "Coverage"},
-		Rows: [][]string{
-			{"markdown", "96%"},
+// ExampleCheckFormatInDir checks a directory the way a CI job would, without
+// changing anything.
+func ExampleCheckFormatInDir() {
+	dir, err := os.MkdirTemp("", "markdown-format")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	files := map[string]string{
+		"README.md": "# Project\n\n- done\n",
+		"guide.md":  "# Guide\n```\nmake\n```\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			fmt.Println("write:", err)
+			return
+		}
+	}
+
+	diff, err := md.CheckFormatInDir(dir)
+	if err != nil {
+		fmt.Println("check:", err)
+		return
+	}
+	fmt.Print(diff)
+
+	// Output:
+	// --- a/guide.md
+	// +++ b/guide.md
+	// @@ -1,4 +1,5 @@
+	//  # Guide
+	// -```
+	// +
+	// +```text
+	//  make
+	//  ```
+}
+
+// ExampleMarkdown_Template mixes a template of mostly prose with the chain.
+// The piechart builder goes into the template as data.
+func ExampleMarkdown_Template() {
+	type release struct {
+		Version  string
+		Breaking string
+		Coverage *piechart.PieChart
+	}
+	data := release{
+		Version:  "v2.0.0",
+		Breaking: "Config files moved to *config/*.",
+		Coverage: piechart.NewPieChart(io.Discard, piechart.WithTitle("Coverage")).
+			LabelAndIntValue("covered", 92).
+			LabelAndIntValue("uncovered", 8),
+	}
+
+	notes := `## {{ .Version }}
+
+{{ bold "Breaking:" }} {{ escapeParagraph .Breaking }}
+
+{{ mermaid .Coverage }}
+`
+	err := md.NewMarkdown(os.Stdout, md.WithBlockSpacing()).
+		H1("Release notes").
+		Template(notes, data).
+		PlainText("Thanks to everyone who contributed.").
+		Build()
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// # Release notes
+	//
+	// ## v2.0.0
+	//
+	// **Breaking:** Config files moved to \*config/\*.
+	//
+	// ```mermaid
+	// %%{init: {"pie": {"textPosition": 0.75}, "themeVariables": {"pieOuterStrokeWidth": "5px"}} }%%
+	// pie
+	//     title Coverage
+	//     "covered" : 92
+	//     "uncovered" : 8
+	// ```
+	//
+	// Thanks to everyone who contributed.
+}
+
+// ExampleMarkdown_TemplateFile renders a template file into the document.
+func ExampleMarkdown_TemplateFile() {
+	dir, err := os.MkdirTemp("", "markdown-template")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "status.md.tmpl")
+	text := "## Status\n\n{{ greenBadge .Badge }}\n\n{{ table .Jobs }}\n"
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		fmt.Println("write:", err)
+		return
+	}
+
+	type job struct {
+		Name   string
+		Result string
+	}
+	err = md.NewMarkdown(os.Stdout, md.WithBlockSpacing()).
+		TemplateFile(path, map[string]any{
+			"Badge": "build-passing",
+			"Jobs":  []job{{Name: "test", Result: "ok"}, {Name: "lint", Result: "ok"}},
+		}).
+		Build()
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// ## Status
+	//
+	// ![Badge](https://img.shields.io/badge/build-passing-green)
+	//
+	// | Name | Result |
+	// |---------|---------|
+	// | test | ok |
+	// | lint | ok |
+}
+
+// ExampleMarkdown_FuncMap hands the functions to a template the caller parses
+// and executes. An error a function runs into is recorded by the builder.
+func ExampleMarkdown_FuncMap() {
+	m := md.NewMarkdown(nil)
+	tmpl := template.Must(template.New("readme").Funcs(m.FuncMap()).
+		Parse("See {{ link \"the docs\" \"docs/\" }} and {{ bold \"star\" }} the repo.\n{{ table .Jobs }}"))
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, map[string]any{"Jobs": 42}); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(out.String())
+	fmt.Println(m.Error())
+
+	// Output:
+	// See [the docs](docs/) and **star** the repo.
+	// table rows must be a slice of structs, got int
+}
+
+// ExampleMarkdown_Include builds a chapter as a document of its own and
+// includes it one level down, footnotes and table of contents entries
+// included.
+func ExampleMarkdown_Include() {
+	chapter := md.NewMarkdown(nil)
+	chapter.H1("Install").
+		PlainText("Run go install" + chapter.Footnote("Go 1.23 or later.") + ".").
+		H2("Linux")
+
+	book := md.NewMarkdown(os.Stdout).
+		H1("Guide").
+		TableOfContents(md.TableOfContentsDepthH3)
+	_ = book.Include(chapter, 1).Build()
+
+	// Output:
+	// # Guide
+	// <!-- BEGIN_TOC -->
+	// - [Guide](#guide)
+	//   - [Install](#install)
+	//     - [Linux](#linux)
+	// <!-- END_TOC -->
+	//
+	// ## Install
+	// Run go install[^1].
+	// ### Linux
+	//
+	// [^1]: Go 1.23 or later.
+}
+
+// ExampleMarkdown_Section writes the same part of a document at two depths:
+// each Section is one level below the one it is called in.
+func ExampleMarkdown_Section() {
+	install := func(m *md.Markdown) {
+		m.PlainText("Run go install.").
+			Section("Linux", func(m *md.Markdown) {
+				m.PlainText("Use the package manager.")
+			})
+	}
+
+	_ = md.NewMarkdown(os.Stdout).
+		Section("Install", install).
+		Section("Guide", func(m *md.Markdown) {
+			m.Section("Install", install)
+		}).
+		Build()
+
+	// Output:
+	// # Install
+	// Run go install.
+	// ## Linux
+	// Use the package manager.
+	// # Guide
+	// ## Install
+	// Run go install.
+	// ### Linux
+	// Use the package manager.
+}
+
+// ExampleWithTitle sets the heading the generated index opens with.
+func ExampleWithTitle() {
+	parent, err := os.MkdirTemp("", "markdown-index")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+
+	// The index is headed with the name of the directory it describes, so the
+	// directory is named here rather than left as the random one MkdirTemp
+	// makes, which would put a different heading in the output on every run.
+	dir := filepath.Join(parent, "guide")
+	if err := os.Mkdir(dir, 0o750); err != nil {
+		fmt.Println("mkdir:", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, "install.md"), []byte("# Install\n"), 0o600); err != nil {
+		fmt.Println("write:", err)
+		return
+	}
+
+	if err := md.GenerateIndex(dir, md.WithTitle("Documentation"), md.WithWriter(os.Stdout)); err != nil {
+		fmt.Println("generate:", err)
+	}
+
+	// Output:
+	// ## Documentation
+	// ### guide
+	// - [Install](install.md)
+}
+
+// ExampleWithDescription sets the paragraphs written under the index heading.
+func ExampleWithDescription() {
+	parent, err := os.MkdirTemp("", "markdown-index")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+
+	// The index is headed with the name of the directory it describes, so the
+	// directory is named here rather than left as the random one MkdirTemp
+	// makes, which would put a different heading in the output on every run.
+	dir := filepath.Join(parent, "guide")
+	if err := os.Mkdir(dir, 0o750); err != nil {
+		fmt.Println("mkdir:", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, "install.md"), []byte("# Install\n"), 0o600); err != nil {
+		fmt.Println("write:", err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = md.GenerateIndex(dir,
+		md.WithDescription([]string{"Every page in this directory.", "Regenerated on each release."}),
+		md.WithWriter(buf),
+	)
+	if err != nil {
+		fmt.Println("generate:", err)
+		return
+	}
+	// Printed quoted because each description line ends with the two spaces
+	// markdown reads as a hard line break, and a godoc Output block cannot hold
+	// trailing whitespace.
+	fmt.Printf("%q\n", buf.String())
+
+	// Output:
+	// "Every page in this directory.\n  \nRegenerated on each release.\n  \n### guide\n- [Install](install.md)\n  \n"
+}
+
+// ExampleWithWriter sends the index somewhere other than the index.md the
+// function creates by default, so it can be inspected or embedded rather than
+// written to disk.
+func ExampleWithWriter() {
+	parent, err := os.MkdirTemp("", "markdown-index")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer func() { _ = os.RemoveAll(parent) }()
+
+	// The index is headed with the name of the directory it describes, so the
+	// directory is named here rather than left as the random one MkdirTemp
+	// makes, which would put a different heading in the output on every run.
+	dir := filepath.Join(parent, "guide")
+	if err := os.Mkdir(dir, 0o750); err != nil {
+		fmt.Println("mkdir:", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, "install.md"), []byte("# Install\n"), 0o600); err != nil {
+		fmt.Println("write:", err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := md.GenerateIndex(dir, md.WithWriter(buf)); err != nil {
+		fmt.Println("generate:", err)
+		return
+	}
+	fmt.Print(buf.String())
+
+	// Output:
+	// ### guide
+	// - [Install](install.md)
+}
+
+// ExampleTableSet shows the shape a table is described with. Every row must
+// have as many cells as the header.
+func ExampleTableSet() {
+	set := md.TableSet{
+		Header: []string{"Package", "Coverage"},
+		Rows: [][]string{
+			{"markdown", "96%"},
 			{"internal", "100%"},
 		},
 	}
@@ -1400,3 +2681,688 @@ func Example_gitHubActionsJobSummary() {
 	//     "uncovered" : 8
 	// ```
 }
+
+// ExampleListItem describes a list nested inside a list item as a tree. The
+// nested items are written as a list of the same kind, indented under the
+// text of their parent.
+func ExampleListItem() {
+	_ = md.NewMarkdown(os.Stdout).
+		BulletListTree(
+			md.ListItem{
+				Text: "Added",
+				Items: []md.ListItem{
+					{Text: "Nested lists"},
+					{Text: "Task lists with sub-tasks"},
+				},
+			},
+			md.ListItem{Text: "Fixed"},
+		).
+		Build()
+
+	// Output:
+	// - Added
+	//   - Nested lists
+	//   - Task lists with sub-tasks
+	// - Fixed
+}
+
+// ExampleItem builds the same tree as a ListItem literal, in less space.
+func ExampleItem() {
+	_ = md.NewMarkdown(os.Stdout).
+		BulletListTree(
+			md.Item("Backend",
+				md.Item("API"),
+				md.Item("Database", md.Item("Migrations")),
+			),
+			md.Item("Frontend"),
+		).
+		Build()
+
+	// Output:
+	// - Backend
+	//   - API
+	//   - Database
+	//     - Migrations
+	// - Frontend
+}
+
+// ExampleMarkdown_BulletListTree writes a bullet list whose item holds a code
+// block. The block is indented under the item so it stays inside it.
+func ExampleMarkdown_BulletListTree() {
+	_ = md.NewMarkdown(os.Stdout).
+		BulletListTree(
+			md.ListItem{
+				Text: "Install the tool:",
+				Body: md.NewMarkdown(nil).CodeBlocks(md.SyntaxHighlightShell, "go install ./..."),
+			},
+			md.Item("Run it"),
+		).
+		Build()
+
+	// Output:
+	// - Install the tool:
+	//   ```shell
+	//   go install ./...
+	//   ```
+	// - Run it
+}
+
+// ExampleMarkdown_OrderedListTree writes a numbered list with numbered steps
+// nested under one of its items. Every level is numbered from 1.
+func ExampleMarkdown_OrderedListTree() {
+	_ = md.NewMarkdown(os.Stdout).
+		OrderedListTree(
+			md.Item("Prepare",
+				md.Item("Tag the release"),
+				md.Item("Write the notes"),
+			),
+			md.Item("Publish"),
+		).
+		Build()
+
+	// Output:
+	// 1. Prepare
+	//    1. Tag the release
+	//    2. Write the notes
+	// 2. Publish
+}
+
+// ExampleMarkdown_CheckBoxTree writes a task list with sub-tasks.
+func ExampleMarkdown_CheckBoxTree() {
+	_ = md.NewMarkdown(os.Stdout).
+		CheckBoxTree(
+			md.ListItem{
+				Text: "Release v2",
+				Items: []md.ListItem{
+					{Text: "Update the changelog", Checked: true},
+					{Text: "Push the tag"},
+				},
+			},
+		).
+		Build()
+
+	// Output:
+	// - [ ] Release v2
+	//   - [x] Update the changelog
+	//   - [ ] Push the tag
+}
+
+// ExampleParse loads an existing document, replaces one section, and writes it
+// back. The blocks nobody touched are written exactly as they were read.
+func ExampleParse() {
+	readme := `# Tool
+
+## Install
+
+    go install example.com/tool@v1
+
+## License
+
+MIT
+`
+	m, err := md.Parse(strings.NewReader(readme), os.Stdout)
+	if err != nil {
+		fmt.Println("parse:", err)
+		return
+	}
+
+	start, end, _ := m.SectionBounds("Install")
+	m.ReplaceBlocks(start, end, md.NewMarkdown(nil).
+		H2("Install").
+		BlankLine().
+		CodeBlocks(md.SyntaxHighlightShell, "go install example.com/tool@v2").
+		BlankLine())
+
+	if err := m.Build(); err != nil {
+		fmt.Println("build:", err)
+	}
+
+	// Output:
+	// # Tool
+	//
+	// ## Install
+	//
+	// ```shell
+	// go install example.com/tool@v2
+	// ```
+	//
+	// ## License
+	//
+	// MIT
+}
+
+// ExampleMarkdown_BlockCount counts the blocks of a document. A blank line
+// between two blocks counts as one.
+func ExampleMarkdown_BlockCount() {
+	m, _ := md.Parse(strings.NewReader("# Title\n\nText.\n"), nil)
+	fmt.Println(m.BlockCount())
+
+	// Output:
+	// 3
+}
+
+// ExampleMarkdown_BlockText reads one block back as markdown.
+func ExampleMarkdown_BlockText() {
+	m, _ := md.Parse(strings.NewReader("# Title\n\n- a\n- b\n"), nil)
+	fmt.Println(m.BlockText(2))
+
+	// Output:
+	// - a
+	// - b
+}
+
+// ExampleMarkdown_SectionBounds finds the blocks of a section: from its heading
+// up to the next heading of the same or a higher level.
+func ExampleMarkdown_SectionBounds() {
+	m := md.NewMarkdown(nil).
+		H2("Usage").
+		PlainText("Run it.").
+		H3("Flags").
+		PlainText("None.").
+		H2("License")
+
+	start, end, ok := m.SectionBounds("Usage")
+	fmt.Println(start, end, ok)
+
+	// Output:
+	// 0 4 true
+}
+
+// ExampleMarkdown_ReplaceBlocks replaces a range of blocks with the blocks of
+// another builder. A nil builder removes the range.
+func ExampleMarkdown_ReplaceBlocks() {
+	_ = md.NewMarkdown(os.Stdout).
+		H2("Status").
+		PlainText("Unknown.").
+		ReplaceBlocks(1, 2, md.NewMarkdown(nil).PlainText("All systems go.")).
+		Build()
+
+	// Output:
+	// ## Status
+	// All systems go.
+}
+
+// ExampleBlock inspects the blocks of a document by type.
+func ExampleBlock() {
+	m := md.NewMarkdown(nil).
+		H1("Title").
+		PlainText("Text.").
+		BulletList("a", "b")
+
+	for _, b := range m.Blocks() {
+		fmt.Printf("%T\n", b)
+	}
+
+	// Output:
+	// *markdown.Heading
+	// *markdown.Paragraph
+	// *markdown.List
+}
+
+// ExampleHeading builds a heading by hand. H1 to H6 append the same block.
+func ExampleHeading() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.Heading{Level: 2, Text: "Usage"}).
+		Build()
+
+	// Output:
+	// ## Usage
+}
+
+// ExampleHeading_String writes one heading on its own.
+func ExampleHeading_String() {
+	fmt.Println((&md.Heading{Level: 3, Text: "Flags"}).String())
+
+	// Output:
+	// ### Flags
+}
+
+// ExampleParagraph is the block PlainText appends.
+func ExampleParagraph() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.Paragraph{Text: "Hello, **world**."}).
+		Build()
+
+	// Output:
+	// Hello, **world**.
+}
+
+// ExampleParagraph_String writes one paragraph on its own.
+func ExampleParagraph_String() {
+	fmt.Println((&md.Paragraph{Text: "Text."}).String())
+
+	// Output:
+	// Text.
+}
+
+// ExampleListStyle picks the kind of list a List is written as.
+func ExampleListStyle() {
+	items := []md.ListItem{md.Item("one"), md.Item("two")}
+	for _, style := range []md.ListStyle{md.ListStyleBullet, md.ListStyleOrdered, md.ListStyleCheckBox} {
+		fmt.Println((&md.List{Style: style, Items: items}).String())
+	}
+
+	// Output:
+	// - one
+	// - two
+	// 1. one
+	// 2. two
+	// - [ ] one
+	// - [ ] two
+}
+
+// ExampleList is the block BulletListTree, OrderedListTree and CheckBoxTree
+// append.
+func ExampleList() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.List{
+			Style: md.ListStyleOrdered,
+			Items: []md.ListItem{md.Item("Build", md.Item("Test"))},
+		}).
+		Build()
+
+	// Output:
+	// 1. Build
+	//    1. Test
+}
+
+// ExampleList_String writes one list on its own.
+func ExampleList_String() {
+	fmt.Println((&md.List{Items: []md.ListItem{md.Item("a"), md.Item("b")}}).String())
+
+	// Output:
+	// - a
+	// - b
+}
+
+// ExampleTable is the block Table appends, and with Options set, the block
+// CustomTable appends.
+func ExampleTable() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.Table{Set: md.TableSet{
+			Header: []string{"Name", "Age"},
+			Rows:   [][]string{{"David", "23"}},
+		}}).
+		Build()
+
+	// Output:
+	// | Name | Age |
+	// |---------|---------|
+	// | David | 23 |
+}
+
+// ExampleTable_String writes one table on its own.
+func ExampleTable_String() {
+	fmt.Print((&md.Table{
+		Set:     md.TableSet{Header: []string{"Name", "Age"}, Rows: [][]string{{"David", "23"}}},
+		Options: &md.TableOptions{},
+	}).String())
+
+	// Output:
+	// | Name  | Age |
+	// |-------|-----|
+	// | David | 23  |
+}
+
+// ExampleCodeBlock is the block CodeBlocks appends.
+func ExampleCodeBlock() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.CodeBlock{Lang: md.SyntaxHighlightGo, Code: `fmt.Println("Hello")`}).
+		Build()
+
+	// Output:
+	// ```go
+	// fmt.Println("Hello")
+	// ```
+}
+
+// ExampleCodeBlock_String writes one code block on its own.
+func ExampleCodeBlock_String() {
+	fmt.Println((&md.CodeBlock{Lang: md.SyntaxHighlightShell, Code: "go test ./..."}).String())
+
+	// Output:
+	// ```shell
+	// go test ./...
+	// ```
+}
+
+// ExampleBlockquote is the block Blockquote appends.
+func ExampleBlockquote() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.Blockquote{Text: "First line.\nSecond line."}).
+		Build()
+
+	// Output:
+	// > First line.
+	// > Second line.
+}
+
+// ExampleBlockquote_String writes one quote on its own.
+func ExampleBlockquote_String() {
+	fmt.Println((&md.Blockquote{Text: "Quoted."}).String())
+
+	// Output:
+	// > Quoted.
+}
+
+// ExampleAlertKind changes the kind of every alert in a document.
+func ExampleAlertKind() {
+	m := md.NewMarkdown(nil).Note("Read this.")
+	m.Walk(func(b md.Block) bool {
+		if a, ok := b.(*md.Alert); ok && a.Kind == md.AlertKindNote {
+			a.Kind = md.AlertKindWarning
+		}
+		return true
+	})
+	// Printed quoted because the keyword line ends with the two spaces
+	// markdown reads as a hard line break.
+	fmt.Printf("%q\n", m.String())
+
+	// Output:
+	// "> [!WARNING]  \n> Read this."
+}
+
+// ExampleAlert is the block Note, Tip, Important, Warning and Caution append.
+func ExampleAlert() {
+	m := md.NewMarkdown(nil).AddBlocks(&md.Alert{Kind: md.AlertKindTip, Text: "Run go vet."})
+	// Printed quoted because the keyword line ends with the two spaces
+	// markdown reads as a hard line break.
+	fmt.Printf("%q\n", m.String())
+
+	// Output:
+	// "> [!TIP]  \n> Run go vet."
+}
+
+// ExampleAlert_String writes one alert on its own.
+func ExampleAlert_String() {
+	fmt.Printf("%q\n", (&md.Alert{Kind: md.AlertKindCaution, Text: "Irreversible."}).String())
+
+	// Output:
+	// "> [!CAUTION]  \n> Irreversible."
+}
+
+// ExampleDetails is the block Details appends.
+func ExampleDetails() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.Details{Summary: "Logs", Text: "All green."}).
+		Build()
+
+	// Output:
+	// <details>
+	// <summary>Logs</summary>
+	//
+	// All green.
+	//
+	// </details>
+}
+
+// ExampleDetails_String writes one collapsible section on its own.
+func ExampleDetails_String() {
+	fmt.Print((&md.Details{Summary: "More", Text: "Hidden."}).String())
+
+	// Output:
+	// <details>
+	// <summary>More</summary>
+	//
+	// Hidden.
+	//
+	// </details>
+}
+
+// ExampleHorizontalRule is the block HorizontalRule appends.
+// ExamplePicture is the block CustomImage appends.
+func ExamplePicture() {
+	_ = md.NewMarkdown(os.Stdout).
+		AddBlocks(&md.Picture{Text: "logo", URL: "logo.png", Options: md.ImageOptions{Width: "64"}}).
+		PlainText("Text after the image.").
+		Build()
+
+	// Output:
+	// <img src="logo.png" alt="logo" width="64">
+	//
+	// Text after the image.
+}
+
+// ExamplePicture_String writes one image on its own.
+func ExamplePicture_String() {
+	fmt.Println((&md.Picture{Text: "logo", URL: "logo.png", Options: md.ImageOptions{Title: "Logo"}}).String())
+
+	// Output:
+	// ![logo](logo.png "Logo")
+}
+
+func ExampleHorizontalRule() {
+	_ = md.NewMarkdown(os.Stdout).
+		PlainText("Above.").
+		AddBlocks(&md.HorizontalRule{}).
+		PlainText("Below.").
+		Build()
+
+	// Output:
+	// Above.
+	// ---
+	// Below.
+}
+
+// ExampleHorizontalRule_String writes the rule on its own.
+func ExampleHorizontalRule_String() {
+	fmt.Println((&md.HorizontalRule{}).String())
+
+	// Output:
+	// ---
+}
+
+// ExampleRaw holds markdown no other block describes, such as a parsed table.
+func ExampleRaw() {
+	m, _ := md.Parse(strings.NewReader("| a |\n|---|\n| 1 |\n"), nil)
+	for _, b := range m.Blocks() {
+		if r, ok := b.(*md.Raw); ok {
+			fmt.Println(r.Text)
+		}
+	}
+
+	// Output:
+	// | a |
+	// |---|
+	// | 1 |
+}
+
+// ExampleRaw_String writes the text as it is.
+func ExampleRaw_String() {
+	fmt.Println((&md.Raw{Text: "<!-- generated -->"}).String())
+
+	// Output:
+	// <!-- generated -->
+}
+
+// ExampleMarkdown_Blocks lists the headings of a document.
+func ExampleMarkdown_Blocks() {
+	m := md.NewMarkdown(nil).
+		H1("Guide").
+		PlainText("Intro.").
+		H2("Install")
+
+	for _, b := range m.Blocks() {
+		if h, ok := b.(*md.Heading); ok {
+			fmt.Println(h.Level, h.Text)
+		}
+	}
+
+	// Output:
+	// 1 Guide
+	// 2 Install
+}
+
+// ExampleMarkdown_AddBlocks appends blocks taken from another document.
+func ExampleMarkdown_AddBlocks() {
+	shared := md.NewMarkdown(nil).H2("License").PlainText("MIT")
+
+	_ = md.NewMarkdown(os.Stdout).
+		H1("Project").
+		AddBlocks(shared.Blocks()...).
+		Build()
+
+	// Output:
+	// # Project
+	// ## License
+	// MIT
+}
+
+// ExampleMarkdown_Walk rewrites every link to an old host before the document
+// is built.
+func ExampleMarkdown_Walk() {
+	m := md.NewMarkdown(os.Stdout).
+		PlainText("See [the docs](http://old.example.com/docs).").
+		BulletListTree(md.ListItem{
+			Text: "Download",
+			Body: md.NewMarkdown(nil).PlainText("[latest](http://old.example.com/latest)"),
+		})
+
+	m.Walk(func(b md.Block) bool {
+		if p, ok := b.(*md.Paragraph); ok {
+			p.Text = strings.ReplaceAll(p.Text, "http://old.example.com", "https://example.com")
+		}
+		return true
+	})
+	_ = m.Build()
+
+	// Output:
+	// See [the docs](https://example.com/docs).
+	// - Download
+	//   [latest](https://example.com/latest)
+}
+
+// ExampleMarkdown_Transform drops an internal section, heading and all,
+// before the document is built.
+func ExampleMarkdown_Transform() {
+	m := md.NewMarkdown(os.Stdout).
+		H2("Usage").
+		PlainText("Run it.").
+		H2("Internal").
+		PlainText("Do not publish.").
+		H2("License").
+		PlainText("MIT")
+
+	dropping := false
+	_ = m.Transform(func(b md.Block) []md.Block {
+		if h, ok := b.(*md.Heading); ok {
+			dropping = h.Text == "Internal"
+		}
+		if dropping {
+			return nil
+		}
+		return []md.Block{b}
+	}).Build()
+
+	// Output:
+	// ## Usage
+	// Run it.
+	// ## License
+	// MIT
+}
+
+// ExampleMarkdown_HTML renders a document as an HTML fragment, the way GitHub
+// shows it.
+func ExampleMarkdown_HTML() {
+	out, err := md.NewMarkdown(nil).
+		H2("Status").
+		Note("Deployed.").
+		CheckBox([]md.CheckBoxSet{{Checked: true, Text: "Tests"}}).
+		HTML()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(out)
+
+	// Output:
+	// <h2 id="status">Status</h2>
+	// <div class="markdown-alert markdown-alert-note">
+	// <p class="markdown-alert-title">Note</p>
+	// <p>Deployed.</p>
+	// </div>
+	// <ul>
+	// <li><input checked="" disabled="" type="checkbox"> Tests</li>
+	// </ul>
+}
+
+// ExampleMarkdown_BuildHTML writes the HTML to the builder's writer instead of
+// the markdown.
+func ExampleMarkdown_BuildHTML() {
+	_ = md.NewMarkdown(os.Stdout).
+		CodeBlocks(md.SyntaxHighlightMermaid, "graph LR\n    A --> B").
+		BuildHTML()
+
+	// Output:
+	// <pre class="mermaid">graph LR
+	//     A --&gt; B
+	// </pre>
+}
+
+// ExampleHTMLOption shows what an HTMLOption is: a function that changes the
+// HTML a document is rendered as.
+func ExampleHTMLOption() {
+	options := []md.HTMLOption{md.WithHTMLPage("Report"), md.WithHTMLStylesheetURL("report.css")}
+
+	out, _ := md.NewMarkdown(nil).PlainText("Hello.").HTML(options...)
+	fmt.Print(out)
+
+	// Output:
+	// <!DOCTYPE html>
+	// <html>
+	// <head>
+	// <meta charset="utf-8">
+	// <meta name="viewport" content="width=device-width, initial-scale=1">
+	// <title>Report</title>
+	// <link rel="stylesheet" href="report.css">
+	// </head>
+	// <body>
+	// <article class="markdown-body">
+	// <p>Hello.</p>
+	// </article>
+	// </body>
+	// </html>
+}
+
+// ExampleWithHTMLPage renders a standalone page. The title is escaped, and the
+// default stylesheet is inlined into the head.
+func ExampleWithHTMLPage() {
+	out, _ := md.NewMarkdown(nil).H1("Nightly report").HTML(md.WithHTMLPage(""))
+	fmt.Println(strings.Contains(out, "<title>Nightly report</title>"))
+	fmt.Println(strings.Contains(out, "<style>"))
+
+	// Output:
+	// true
+	// true
+}
+
+// ExampleWithHTMLStylesheet inlines a stylesheet of your own.
+func ExampleWithHTMLStylesheet() {
+	out, _ := md.NewMarkdown(nil).PlainText("Hello.").
+		HTML(md.WithHTMLStylesheet("body { font-family: serif; }"))
+	fmt.Println(strings.Contains(out, "<style>\nbody { font-family: serif; }\n</style>"))
+
+	// Output:
+	// true
+}
+
+// ExampleWithHTMLStylesheetURL links the page to a stylesheet.
+func ExampleWithHTMLStylesheetURL() {
+	out, _ := md.NewMarkdown(nil).PlainText("Hello.").
+		HTML(md.WithHTMLStylesheetURL("https://example.com/portal.css"))
+	fmt.Println(strings.Contains(out, `<link rel="stylesheet" href="https://example.com/portal.css">`))
+
+	// Output:
+	// true
+}
+
+// ExampleWithHTMLHead loads mermaid.js, so the diagrams of the page are drawn.
+func ExampleWithHTMLHead() {
+	out, _ := md.NewMarkdown(nil).
+		CodeBlocks(md.SyntaxHighlightMermaid, "graph LR\n    A --> B").
+		HTML(md.WithHTMLHead(`<script type="module">import mermaid from "https://cdn.jsdelivr.net/npm/mermaid@11/dist/mermaid.esm.min.mjs";</script>`))
+	fmt.Println(strings.Contains(out, "mermaid.esm.min.mjs"))
+
+	// Output:
+	// true
+}
@@ -0,0 +1,514 @@
+package markdown
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Format returns the markdown source written the way this package writes a
+// document with WithBlockSpacing, so that hand-written files look like the
+// generated ones next to them:
+//
+//   - headings are written with "#", setext ones included;
+//   - one blank line separates blocks, and none the items of a tight list;
+//   - bullet lists use "- ", task lists "- [ ] " and "- [x] ", and numbered
+//     lists "1. ", "2. " and so on from the number the list starts at, with
+//     nested blocks indented to the width of the marker;
+//   - tables are aligned the way CustomTable aligns them;
+//   - code blocks are fenced and name a language, "text" when the source
+//     named none;
+//   - thematic breaks are "---" and quotes are written with "> ";
+//   - footnote and link reference definitions move to the end, footnotes
+//     first, as Footnote and ReferenceLink write them;
+//   - no line ends in spaces but the two of a hard line break;
+//   - the document ends with one line feed.
+//
+// Text inside a block, emphasis and links included, is kept as the source
+// wrote it, as are front matter and HTML. Two lists next to each other that
+// only differed in their markers become one list, because they no longer
+// differ. Formatting a formatted document changes nothing.
+func Format(source string) string {
+	lf := internal.LineFeed()
+	frontMatter, body := internal.SplitFrontMatter(strings.ReplaceAll(source, "\r\n", "\n"))
+
+	src := []byte(body)
+	root := formatParser().Parse(text.NewReader(src))
+	f := &formatter{source: src, renderer: &blockRenderer{blockSpacing: true}}
+	out := appendDefinitions(joinBlocks(f.blocks(root, false), true), f.definitions())
+
+	if frontMatter != "" {
+		frontMatter = normalizeLineFeeds(strings.TrimSuffix(frontMatter, "\n"))
+		if out == "" {
+			out = frontMatter
+		} else {
+			out = frontMatter + lf + lf + out
+		}
+	}
+	if out == "" {
+		return ""
+	}
+	return out + lf
+}
+
+// CheckFormat returns "" when source, the markdown of the file name, is
+// formatted, and otherwise the unified diff that Format would apply to it,
+// which a CI job can print before it fails. The diff names the file a/name and
+// b/name, as git does, so git apply takes it.
+func CheckFormat(name, source string) string {
+	formatted := Format(source)
+	if formatted == source {
+		return ""
+	}
+	name = filepath.ToSlash(name)
+	return internal.Diff("a/"+name, "b/"+name, source, formatted)
+}
+
+// FormatInDir formats every markdown file under dir in place, and returns the
+// files it changed, relative to dir and with forward slashes. A file is
+// replaced by renaming a formatted copy over it, so a failure part way through
+// writing leaves it as it was.
+func FormatInDir(dir string) ([]string, error) {
+	var changed []string
+	err := forEachMarkdownFile(dir, func(path, name, source string) error {
+		formatted := Format(source)
+		if formatted == source {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		if err := writeFileAtomically(path, []byte(formatted), info.Mode().Perm()); err != nil {
+			return err
+		}
+		changed = append(changed, name)
+		return nil
+	})
+	return changed, err
+}
+
+// CheckFormatInDir checks every markdown file under dir the way CheckFormat
+// checks one, and returns the diffs of the files that are not formatted, one
+// after the other, or "" when all of them are. The names in the diff are
+// relative to dir.
+func CheckFormatInDir(dir string) (string, error) {
+	var diffs strings.Builder
+	err := forEachMarkdownFile(dir, func(_, name, source string) error {
+		diffs.WriteString(CheckFormat(name, source))
+		return nil
+	})
+	return diffs.String(), err
+}
+
+// forEachMarkdownFile calls fn with the path, the name relative to dir and the
+// content of every markdown file under dir, in order.
+func forEachMarkdownFile(dir string, fn func(path, name, source string) error) error {
+	files, err := markdownFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		source, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			name = file
+		}
+		if err := fn(file, filepath.ToSlash(name), string(source)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatParser returns a GFM parser that reads footnote definitions.
+//
+// It leaves out the transformer the footnote extension adds, which drops every
+// footnote nothing refers to: formatting must not lose text.
+func formatParser() parser.Parser {
+	return goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithBlockParsers(
+			util.Prioritized(extension.NewFootnoteBlockParser(), 999), //nolint:mnd // the footnote extension's priority
+		)),
+	).Parser()
+}
+
+// formatter writes parsed markdown back in canonical form.
+type formatter struct {
+	source []byte
+	// renderer writes the blocks the formatter hands to the block types.
+	renderer *blockRenderer
+	// footnotes and references are the definitions found so far, written.
+	footnotes  []string
+	references []string
+}
+
+// blocks writes the children of parent, one entry per block, taking the
+// definitions out for the end of the document. tight is set for the items of
+// a tight list, whose blocks are joined without blank lines.
+func (f *formatter) blocks(parent ast.Node, tight bool) []renderedBlock {
+	var out []renderedBlock
+	// open is set after an HTML block that only a blank line ends, which the
+	// join would otherwise let swallow a comment after it.
+	open := false
+	for node := parent.FirstChild(); node != nil; node = node.NextSibling() {
+		var text string
+		switch n := node.(type) {
+		case *ast.LinkReferenceDefinition, *extast.FootnoteList:
+			f.define(node)
+			continue
+		case *ast.List:
+			// A list right after a list of the same kind differed from it only
+			// in its marker, which the formatter makes the same. Definitions
+			// between them move to the end, where they no longer part them.
+			lists := []*ast.List{n}
+			for next := node.NextSibling(); next != nil; next = next.NextSibling() {
+				if isDefinition(next) {
+					continue
+				}
+				l, ok := next.(*ast.List)
+				if !ok || l.IsOrdered() != n.IsOrdered() {
+					break
+				}
+				for between := node.NextSibling(); between != next; between = between.NextSibling() {
+					f.define(between)
+				}
+				lists = append(lists, l)
+				node = next
+			}
+			text = f.list(lists)
+		case *ast.Heading:
+			// A setext heading can end in what "#" would read as a closing
+			// sequence.
+			text = strings.TrimRight(strings.Repeat("#", n.Level)+" "+escapeClosingHashes(headingText(n, f.source)), " ")
+		case *ast.Paragraph, *ast.TextBlock:
+			text = f.paragraph(n)
+		case *ast.ThematicBreak:
+			text = "---"
+			if tight && isParagraph(node.PreviousSibling()) {
+				// On the line after text, "---" would make the text a heading.
+				text = "***"
+			}
+		case *ast.FencedCodeBlock:
+			lang := SyntaxHighlightText
+			if n.Info != nil {
+				if info := strings.TrimSpace(string(n.Info.Segment.Value(f.source))); info != "" {
+					lang = SyntaxHighlight(info)
+				}
+			}
+			text = f.code(n, lang)
+		case *ast.CodeBlock:
+			text = f.code(n, SyntaxHighlightText)
+		case *ast.Blockquote:
+			text = quote(joinBlocks(f.blocks(n, false), true))
+		case *extast.Table:
+			text = f.table(n)
+		default:
+			text = f.raw(node)
+		}
+		kind := kindBlank
+		if strings.TrimSpace(text) != "" {
+			kind = textKind(text)
+		}
+		if open {
+			out = append(out, renderedBlock{kind: kindBlank})
+		}
+		out = append(out, renderedBlock{text: text, kind: kind})
+		html, ok := node.(*ast.HTMLBlock)
+		open = ok && (html.HTMLBlockType == ast.HTMLBlockType6 || html.HTMLBlockType == ast.HTMLBlockType7)
+	}
+	return out
+}
+
+// isParagraph reports whether n is a paragraph, tight or not.
+func isParagraph(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.Paragraph, *ast.TextBlock:
+		return true
+	}
+	return false
+}
+
+// paragraph writes the lines of a paragraph with the spaces around them
+// trimmed, keeping the two spaces of a hard line break.
+//
+// A line after the first that would start a block once its indentation is
+// gone is indented by four spaces, which keeps it in the paragraph wherever
+// the paragraph is. So is a line that would underline the text above it once
+// it is moved into a list item with the rest of the paragraph.
+func (f *formatter) paragraph(n ast.Node) string {
+	lines := n.Lines()
+	out := make([]string, 0, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		line := strings.TrimRight(string(segment.Value(f.source)), "\r\n")
+		trimmed := strings.Trim(line, " \t")
+		if i > 0 && startsBlock(trimmed) {
+			trimmed = "    " + trimmed
+		}
+		if i < lines.Len()-1 && trimmed != "" {
+			switch {
+			case strings.HasSuffix(line, "  "):
+				trimmed += "  "
+			case strings.TrimRight(line, " \t") != line && trailingBackslashes(trimmed)%2 == 1:
+				// A backslash the trimmed spaces followed would now escape
+				// the line ending, which is a hard line break.
+				trimmed += `\`
+			}
+		}
+		out = append(out, trimmed)
+	}
+
+	if _, ok := n.FirstChild().(*extast.TaskCheckBox); ok && len(out) > 0 {
+		// "[X]" and "[ ]" with any spacing after it become "[x] " and "[ ] ".
+		box, rest, _ := strings.Cut(out[0], "]")
+		mark := "[ ] "
+		if strings.TrimSpace(strings.TrimPrefix(box, "[")) != "" {
+			mark = "[x] "
+		}
+		out[0] = mark + strings.TrimLeft(rest, " \t")
+	}
+	return strings.Join(out, internal.LineFeed())
+}
+
+// trailingBackslashes returns the number of backslashes s ends with.
+func trailingBackslashes(s string) int {
+	return len(s) - len(strings.TrimRight(s, `\`))
+}
+
+// startsBlock reports whether a line starting with s, below the first line of
+// a paragraph, would end the paragraph and start another block, or underline
+// it as a setext heading or a table header. It errs on the side of yes: a line
+// that did not need the care it gets changes nothing.
+func startsBlock(s string) bool {
+	switch {
+	case s == "":
+		return false
+	case strings.Trim(s, "=-*_|: \t") == "":
+		// A setext underline, a thematic break or a table delimiter row.
+		return true
+	case strings.HasPrefix(s, ">"), strings.HasPrefix(s, "```"), strings.HasPrefix(s, "~~~"), strings.HasPrefix(s, "[^"):
+		return true
+	case s[0] == '#':
+		hashes := len(s) - len(strings.TrimLeft(s, "#"))
+		return hashes <= 6 && markerEnds(s[hashes:])
+	case s[0] == '-' || s[0] == '+' || s[0] == '*':
+		return markerEnds(s[1:])
+	case s[0] >= '0' && s[0] <= '9':
+		digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+		return digits < len(s) && (s[digits] == '.' || s[digits] == ')') && markerEnds(s[digits+1:])
+	}
+	return htmlBlockStart.MatchString(s)
+}
+
+// markerEnds reports whether the marker of a heading or a list item ends where
+// rest starts, which takes a space, a tab or the end of the line.
+func markerEnds(rest string) bool {
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
+
+// htmlBlockStart matches the start of an HTML block that can end a paragraph:
+// a comment, a processing instruction, a declaration, CDATA or one of the
+// tags CommonMark names.
+var htmlBlockStart = regexp.MustCompile(`(?i)^(<[!?]|<(script|pre|style|textarea)(\s|>|$)|</?(address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|meta|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(\s|/?>|$))`) //nolint:gochecknoglobals // compiled once
+
+// code writes a code block, fenced or indented, as a fenced one.
+func (f *formatter) code(n ast.Node, lang SyntaxHighlight) string {
+	var code strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		code.Write(segment.Value(f.source))
+	}
+	if lines.Len() == 0 {
+		// CodeBlock writes a line for the code even when there is none.
+		fence := codeFence("", string(lang))
+		return fence + string(lang) + internal.LineFeed() + fence
+	}
+	return (&CodeBlock{Lang: lang, Code: normalizeLineFeeds(strings.TrimSuffix(code.String(), "\n"))}).render(f.renderer)
+}
+
+// quote quotes every line of text, leaving no space after the marker of a
+// blank line.
+func quote(text string) string {
+	lf := internal.LineFeed()
+	lines := strings.Split(text, lf)
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+			continue
+		}
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, lf)
+}
+
+// list writes lists as one, numbered from the number the first one starts
+// at. The items are separated by blank lines when any of the lists was loose.
+func (f *formatter) list(lists []*ast.List) string {
+	lf := internal.LineFeed()
+	loose := false
+	for _, l := range lists {
+		loose = loose || !l.IsTight
+	}
+
+	number := lists[0].Start
+	var items []string
+	for _, l := range lists {
+		for item := l.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "- "
+			if l.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			content := f.item(item, loose)
+			if content == "" {
+				items = append(items, strings.TrimRight(marker, " "))
+				continue
+			}
+			items = append(items, marker+indentContinuation(content, strings.Repeat(" ", len(marker))))
+		}
+	}
+	if loose {
+		return strings.Join(items, lf+lf)
+	}
+	return strings.Join(items, lf)
+}
+
+// define keeps the link reference definition or the footnotes of node for the
+// end of the document.
+func (f *formatter) define(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.LinkReferenceDefinition:
+		f.references = append(f.references, f.reference(n))
+	case *extast.FootnoteList:
+		for note := n.FirstChild(); note != nil; note = note.NextSibling() {
+			if note, ok := note.(*extast.Footnote); ok {
+				f.footnotes = append(f.footnotes, f.footnote(note))
+			}
+		}
+	}
+}
+
+// isDefinition reports whether node is a link reference definition or a list
+// of footnotes, which the formatter writes at the end of the document.
+func isDefinition(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.LinkReferenceDefinition, *extast.FootnoteList:
+		return true
+	}
+	return false
+}
+
+// item writes the blocks of a list item. The blocks of a loose list are
+// separated by blank lines; those of a tight one keep to consecutive lines,
+// as the source had them, since a blank line would make the list loose.
+func (f *formatter) item(item ast.Node, loose bool) string {
+	blocks := f.blocks(item, !loose)
+	if loose {
+		return joinBlocks(blocks, true)
+	}
+	texts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		texts = append(texts, b.text)
+	}
+	return strings.Join(texts, internal.LineFeed())
+}
+
+// table writes a table the way CustomTable does.
+func (f *formatter) table(n *extast.Table) string {
+	set := TableSet{}
+	for _, alignment := range n.Alignments {
+		switch alignment {
+		case extast.AlignLeft:
+			set.Alignment = append(set.Alignment, AlignLeft)
+		case extast.AlignCenter:
+			set.Alignment = append(set.Alignment, AlignCenter)
+		case extast.AlignRight:
+			set.Alignment = append(set.Alignment, AlignRight)
+		case extast.AlignNone:
+			set.Alignment = append(set.Alignment, AlignDefault)
+		}
+	}
+	for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, strings.TrimSpace(string(cell.Lines().Value(f.source))))
+		}
+		if _, ok := row.(*extast.TableHeader); ok {
+			set.Header = cells
+			continue
+		}
+		set.Rows = append(set.Rows, cells)
+	}
+
+	// tablewriter leaves out the delimiter row of a table without rows, which
+	// then is no table at all.
+	text, err := renderCustomTable(set, TableOptions{})
+	if err != nil || len(set.Rows) == 0 {
+		text = renderTable(set)
+	}
+	return strings.TrimRight(text, "\r\n")
+}
+
+// raw writes an HTML block, or any block the formatter has no rule for, as the
+// source wrote it.
+func (f *formatter) raw(n ast.Node) string {
+	var lines []string
+	add := func(segment text.Segment) {
+		lines = append(lines, strings.TrimRight(string(segment.Value(f.source)), "\r\n"))
+	}
+	for i := 0; i < n.Lines().Len(); i++ {
+		add(n.Lines().At(i))
+	}
+	if h, ok := n.(*ast.HTMLBlock); ok && h.HasClosure() {
+		add(h.ClosureLine)
+	}
+	return strings.Join(lines, internal.LineFeed())
+}
+
+// footnote writes a footnote definition the way Footnote does, with the lines
+// after the first indented under it.
+func (f *formatter) footnote(n *extast.Footnote) string {
+	content := joinBlocks(f.blocks(n, false), true)
+	return strings.TrimRight(FootnoteDefinition(string(n.Ref), indentContinuation(content, "    ")), " ")
+}
+
+// reference writes a link reference definition the way ReferenceLink does.
+func (f *formatter) reference(n *ast.LinkReferenceDefinition) string {
+	label := strings.Join(strings.Fields(string(n.Label)), " ")
+	destination := string(n.Destination)
+	if destination == "" || strings.ContainsAny(destination, " \t") {
+		destination = "<" + destination + ">"
+	}
+	return ReferenceLinkDefinition(label, destination, string(util.UnescapePunctuations(n.Title)))
+}
+
+// definitions returns the footnote definitions followed by the link reference
+// definitions, the way Markdown.definitions does.
+func (f *formatter) definitions() string {
+	lf := internal.LineFeed()
+	groups := []string{}
+	if len(f.footnotes) > 0 {
+		groups = append(groups, strings.Join(f.footnotes, lf))
+	}
+	if len(f.references) > 0 {
+		groups = append(groups, strings.Join(f.references, lf))
+	}
+	return strings.Join(groups, lf+lf)
+}
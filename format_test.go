@@ -0,0 +1,287 @@
+package markdown
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		source string
+		want   string
+	}{
+		"nothing": {
+			source: "",
+			want:   "",
+		},
+		"blank lines only": {
+			source: "\n\n\n",
+			want:   "",
+		},
+		"setext and closed headings": {
+			source: "Title\n=====\n\nSub\n---\n## Closed ##\n",
+			want:   "# Title\n\n## Sub\n\n## Closed\n",
+		},
+		"bullet markers": {
+			source: "* a\n* b\n\n+ c\n",
+			want:   "- a\n- b\n- c\n",
+		},
+		"ordered numbering": {
+			source: "3) x\n7) y\n",
+			want:   "3. x\n4. y\n",
+		},
+		"nested lists": {
+			source: "1. a\n   * b\n   * c\n2. d\n",
+			want:   "1. a\n   - b\n   - c\n2. d\n",
+		},
+		"loose list": {
+			source: "- a\n\n- b\n    * c\n",
+			want:   "- a\n\n- b\n\n  - c\n",
+		},
+		"lists parted by a definition": {
+			source: "- a\n- b\n\n[x]: https://example.com\n\n* c\n\n  d\n",
+			want:   "- a\n\n- b\n\n- c\n\n  d\n\n[x]: https://example.com\n",
+		},
+		"task list": {
+			source: "- [X] done\n- [ ]   todo\n",
+			want:   "- [x] done\n- [ ] todo\n",
+		},
+		"table": {
+			source: "|a|b|\n|:-|-:|\n|long cell|2|\n",
+			want:   "|     a     | b |\n|:----------|--:|\n| long cell | 2 |\n",
+		},
+		"code blocks": {
+			source: "    indented\n\n~~~go\nfmt.Println()\n~~~\n```\nplain\n```\n",
+			want:   "```text\nindented\n```\n\n```go\nfmt.Println()\n```\n\n```text\nplain\n```\n",
+		},
+		"thematic breaks": {
+			source: "***\n___\n",
+			want:   "---\n\n---\n",
+		},
+		"quote": {
+			source: ">quote\n>\n>more\n",
+			want:   "> quote\n>\n> more\n",
+		},
+		"definitions": {
+			source: "see [a][x] and[^1]\n\n[x]: https://example.com  \"T\"\n[^1]: note\n\ntext\n",
+			want:   "see [a][x] and[^1]\n\ntext\n\n[^1]: note\n\n[x]: https://example.com \"T\"\n",
+		},
+		"trailing spaces and blank lines": {
+			source: "# a\ntext   \nhard  \nend\n\n\n\n",
+			want:   "# a\n\ntext  \nhard  \nend\n",
+		},
+		"front matter": {
+			source: "---\ntitle: x\n---\n# a\n",
+			want:   "---\ntitle: x\n---\n\n# a\n",
+		},
+		"indented line in a paragraph": {
+			source: "a\n    # b\n",
+			want:   "a\n    # b\n",
+		},
+		"quote marker space is not indentation": {
+			source: ">0\n00",
+			want:   "> 0\n> 00\n",
+		},
+		"html block": {
+			source: "<div>\n  <b>x</b>\n</div>\n\n\n# a\n",
+			want:   "<div>\n  <b>x</b>\n</div>\n\n# a\n",
+		},
+		"crlf": {
+			source: "# a\r\ntext\r\n",
+			want:   "# a\n\ntext\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Format(tt.source)
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+			if again := Format(got); again != got {
+				t.Errorf("Format() is not idempotent (-first +second):\n%s", cmp.Diff(got, again))
+			}
+		})
+	}
+}
+
+// TestFormatKeepsTheRepositoryDocuments formats the repository's own documents
+// and checks that they render to the same HTML, up to whitespace and the
+// language the formatter names for code blocks that had none.
+func TestFormatKeepsTheRepositoryDocuments(t *testing.T) {
+	t.Parallel()
+
+	files := []string{"README.md", "CHANGELOG.md"}
+	for _, pattern := range []string{"testdata/golden/*.md", "doc/*/generated.md"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+
+	whitespace := regexp.MustCompile(`\s+`)
+	render := func(t *testing.T, source string) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if err := newHTMLConverter(false).Convert([]byte(source), &buf); err != nil {
+			t.Fatal(err)
+		}
+		out := strings.ReplaceAll(buf.String(), ` class="language-text"`, "")
+		return strings.TrimSpace(whitespace.ReplaceAllString(out, " "))
+	}
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := os.ReadFile(file) //nolint:gosec // paths come from the globs above
+			if err != nil {
+				t.Fatal(err)
+			}
+			source := strings.ReplaceAll(string(src), "\r\n", "\n")
+			got := Format(source)
+			if diff := cmp.Diff(render(t, source), render(t, got)); diff != "" {
+				t.Errorf("the formatted document renders differently (-want +got):\n%s", diff)
+			}
+			if again := Format(got); again != got {
+				t.Errorf("Format() is not idempotent (-first +second):\n%s", cmp.Diff(got, again))
+			}
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("formatted", func(t *testing.T) {
+		t.Parallel()
+
+		if got := CheckFormat("doc.md", normalizeLineFeeds("# a\n\n- b\n")); got != "" {
+			t.Errorf("CheckFormat() = %q, want \"\"", got)
+		}
+	})
+
+	t.Run("not formatted", func(t *testing.T) {
+		t.Parallel()
+
+		source := normalizeLineFeeds("# a\n* b\n")
+		want := "--- a/docs/doc.md\n+++ b/docs/doc.md\n@@ -1,2 +1,3 @@\n # a\n-* b\n+\n+- b\n"
+		if diff := cmp.Diff(normalizeLineFeeds(want), CheckFormat("docs/doc.md", source)); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestFormatInDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"README.md":        "# a\n\n- b\n",
+		"docs/guide.md":    "Guide\n=====\n* step\n",
+		"docs/notes.txt":   "* not markdown\n",
+		"docs/deep/ref.md": "```\nx\n```\n",
+	})
+
+	diff, err := CheckFormatInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a/docs/deep/ref.md", "a/docs/guide.md"} {
+		if !strings.Contains(diff, "--- "+name+"\n") {
+			t.Errorf("CheckFormatInDir() does not name %s:\n%s", name, diff)
+		}
+	}
+	if strings.Contains(diff, "README.md") || strings.Contains(diff, "notes.txt") {
+		t.Errorf("CheckFormatInDir() names a file it should not:\n%s", diff)
+	}
+
+	changed, err := FormatInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"docs/deep/ref.md", "docs/guide.md"}, changed); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "docs", "guide.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(normalizeLineFeeds("# Guide\n\n- step\n"), string(got)); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+	notes, err := os.ReadFile(filepath.Join(dir, "docs", "notes.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(notes) != "* not markdown\n" {
+		t.Errorf("FormatInDir() changed a file that is not markdown: %q", notes)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("a temporary file was left behind: %v", entries)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "docs", "guide.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+
+	if diff, err := CheckFormatInDir(dir); err != nil || diff != "" {
+		t.Errorf("CheckFormatInDir() after FormatInDir() = %q, %v, want \"\", nil", diff, err)
+	}
+}
+
+func TestFormatInDirMissingDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "missing")
+	if _, err := FormatInDir(dir); err == nil {
+		t.Error("FormatInDir() = nil error, want one")
+	}
+	if _, err := CheckFormatInDir(dir); err == nil {
+		t.Error("CheckFormatInDir() = nil error, want one")
+	}
+}
+
+// FuzzFormat checks that formatting a formatted document changes nothing.
+func FuzzFormat(f *testing.F) {
+	f.Add("# a\n\n- b\n  - c\n\n1. x\n")
+	f.Add("> q\n> - a\n\n| a | b |\n|---|:-:|\n| 1 | 2 |\n")
+	f.Add("```go\nx\n```\n[a]: b\n[^1]: c\n")
+	f.Add("Title\n---\ntext  \nmore\n***\n    code\n")
+	f.Add("- [ ] a\n- [X] b\n<div>\nx\n</div>\n<!-- c -->\n")
+	f.Fuzz(func(t *testing.T, source string) {
+		// Control characters and a carriage return on its own, which no
+		// editor writes, are read differently depending on where they end up.
+		if strings.ContainsAny(source, "\v\f\x00") || strings.Contains(strings.ReplaceAll(source, "\r\n", ""), "\r") {
+			t.Skip()
+		}
+		formatted := Format(source)
+		if again := Format(formatted); again != formatted {
+			t.Errorf("Format(%q) is not idempotent (-first +second):\n%s", source, cmp.Diff(formatted, again))
+		}
+	})
+}
@@ -0,0 +1,352 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithFrontMatter writes v as YAML front matter at the top of the document,
+// between two "---" lines, which is what Hugo, Jekyll, Docusaurus and mkdocs
+// read a page's metadata from.
+//
+// v is a map with string keys or a struct, or a pointer to either. A struct is
+// encoded the way yaml.v3 encodes it, so `yaml:"name,omitempty"` tags apply.
+// The encoder quotes every string that YAML would otherwise read as something
+// else, so a title such as "Checkout: API" or "yes" survives the round trip.
+// Anything else is recorded as ErrInvalidFrontMatter.
+//
+// The front matter is not a block. Blocks, Walk and the table of contents do
+// not see it, and HTML leaves it out.
+func WithFrontMatter(v any) Option {
+	return func(m *Markdown) {
+		m.setFrontMatter(v, encodeYAMLFrontMatter)
+	}
+}
+
+// WithTOMLFrontMatter writes v as TOML front matter, between two "+++" lines,
+// which is the form Hugo uses by default. v is what WithFrontMatter takes, and
+// the yaml struct tags name the keys here too.
+//
+// TOML has no null, so a nil value leaves its key out.
+func WithTOMLFrontMatter(v any) Option {
+	return func(m *Markdown) {
+		m.setFrontMatter(v, func(node *yaml.Node) (string, error) {
+			tagFloats(node, reflect.ValueOf(v))
+			return encodeTOMLFrontMatter(node)
+		})
+	}
+}
+
+// setFrontMatter encodes v and keeps it for String, recording the error when
+// v cannot be encoded.
+func (m *Markdown) setFrontMatter(v any, encode func(*yaml.Node) (string, error)) {
+	node, err := frontMatterNode(v)
+	if err == nil {
+		var text string
+		if text, err = encode(node); err == nil {
+			m.frontMatter = normalizeLineFeeds(text)
+			return
+		}
+	}
+	m.addError(fmt.Errorf("failed to encode front matter: %w", err))
+}
+
+// frontMatterNode returns v as a YAML mapping, keeping the order of the fields
+// of a struct.
+func frontMatterNode(v any) (*yaml.Node, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch {
+	case rv.Kind() == reflect.Struct:
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+	default:
+		return nil, fmt.Errorf("%w: got %T", ErrInvalidFrontMatter, v)
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+	return node, nil
+}
+
+// tagFloats tags as floats the scalars of node that rv holds as floats.
+//
+// yaml.v3 writes float64(3) as 3, which reads back as an integer, and TOML
+// tells the two apart: the tag is all that lets tomlValue write 3.0. A value
+// that marshals itself is left as it is, since node need not mirror its
+// fields.
+func tagFloats(node *yaml.Node, rv reflect.Value) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	if rv.CanInterface() {
+		if _, ok := rv.Interface().(yaml.Marshaler); ok {
+			return
+		}
+	}
+
+	switch {
+	case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!int" {
+			node.Tag = "!!float"
+		}
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && node.Kind == yaml.SequenceNode:
+		for i := 0; i < min(rv.Len(), len(node.Content)); i++ {
+			tagFloats(node.Content[i], rv.Index(i))
+		}
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := reflect.ValueOf(node.Content[i].Value).Convert(rv.Type().Key())
+			if value := rv.MapIndex(key); value.IsValid() {
+				tagFloats(node.Content[i+1], value)
+			}
+		}
+	case rv.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := map[string]reflect.Value{}
+		structFields(rv, fields, node)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if field, ok := fields[node.Content[i].Value]; ok {
+				tagFloats(node.Content[i+1], field)
+			}
+		}
+	}
+}
+
+// structFields adds the fields of the struct rv to fields under the keys
+// yaml.v3 gives them, and tags the floats of the fields it inlines into node.
+func structFields(rv reflect.Value, fields map[string]reflect.Value, node *yaml.Node) {
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+flags+",", ",inline,") {
+			value := rv.Field(i)
+			if value.Kind() == reflect.Struct {
+				structFields(value, fields, node)
+			} else {
+				tagFloats(node, value)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = rv.Field(i)
+	}
+}
+
+// encodeYAMLFrontMatter writes the mapping between "---" lines.
+func encodeYAMLFrontMatter(node *yaml.Node) (string, error) {
+	if len(node.Content) == 0 {
+		return "---\n---", nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2) //nolint:mnd // the indentation every static site generator's examples use
+	if err := enc.Encode(node); err != nil {
+		return "", fmt.Errorf("yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("yaml: %w", err)
+	}
+	return "---\n" + buf.String() + "---", nil
+}
+
+// encodeTOMLFrontMatter writes the mapping between "+++" lines.
+func encodeTOMLFrontMatter(node *yaml.Node) (string, error) {
+	var b strings.Builder
+	b.WriteString("+++\n")
+	if err := writeTOMLTable(&b, nil, node); err != nil {
+		return "", err
+	}
+	b.WriteString("+++")
+	return b.String(), nil
+}
+
+// writeTOMLTable writes the keys of a mapping under the table named by path.
+//
+// TOML puts every plain key of a table before its first subtable, because a
+// key written after a [header] belongs to that header. Keys holding a mapping,
+// or a list of mappings, are written last for that reason.
+func writeTOMLTable(b *strings.Builder, path []string, node *yaml.Node) error {
+	type entry struct {
+		key   string
+		value *yaml.Node
+	}
+	var plain, tables, arrays []entry
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		e := entry{key: node.Content[i].Value, value: node.Content[i+1]}
+		switch {
+		case e.value.Tag == "!!null":
+			// TOML has no null.
+		case e.value.Kind == yaml.MappingNode:
+			tables = append(tables, e)
+		case e.value.Kind == yaml.SequenceNode && len(e.value.Content) > 0 && isMappingSequence(e.value):
+			arrays = append(arrays, e)
+		default:
+			plain = append(plain, e)
+		}
+	}
+
+	for _, e := range plain {
+		value, err := tomlValue(e.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.key, err)
+		}
+		fmt.Fprintf(b, "%s = %s\n", tomlKey(e.key), value)
+	}
+	for _, e := range tables {
+		sub := append(append([]string{}, path...), e.key)
+		fmt.Fprintf(b, "\n[%s]\n", tomlPath(sub))
+		if err := writeTOMLTable(b, sub, e.value); err != nil {
+			return err
+		}
+	}
+	for _, e := range arrays {
+		sub := append(append([]string{}, path...), e.key)
+		for _, item := range e.value.Content {
+			fmt.Fprintf(b, "\n[[%s]]\n", tomlPath(sub))
+			if err := writeTOMLTable(b, sub, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isMappingSequence reports whether every item of a sequence is a mapping,
+// which TOML writes as an array of tables.
+func isMappingSequence(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlValue returns a scalar or an array of scalars as a TOML value.
+func tomlValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind == yaml.MappingNode {
+				return "", fmt.Errorf("%w: a list mixing tables and values", ErrInvalidFrontMatter)
+			}
+			value, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, value)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!bool", "!!int", "!!timestamp":
+			return node.Value, nil
+		case "!!float":
+			return tomlFloat(node.Value), nil
+		case "!!null":
+			return "", fmt.Errorf("%w: null inside a list", ErrInvalidFrontMatter)
+		}
+		return tomlString(node.Value), nil
+	case yaml.DocumentNode, yaml.MappingNode, yaml.AliasNode:
+	}
+	return "", fmt.Errorf("%w: unsupported value", ErrInvalidFrontMatter)
+}
+
+// tomlFloat rewrites the YAML spellings of the special floats as TOML's, and
+// gives a whole number a fraction, which TOML would read as an integer without
+// one: float64(3) is encoded as 3 and written as 3.0.
+func tomlFloat(value string) string {
+	switch strings.ToLower(value) {
+	case ".inf", "+.inf":
+		return "inf"
+	case "-.inf":
+		return "-inf"
+	case ".nan":
+		return "nan"
+	}
+	if !strings.ContainsAny(value, ".eE") {
+		return value + ".0"
+	}
+	return value
+}
+
+// bareKey matches the keys TOML accepts without quotes.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`) //nolint:gochecknoglobals // compiled once
+
+// tomlKey returns key bare when TOML allows it, quoted otherwise.
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlPath returns the dotted name of a table.
+func tomlPath(path []string) string {
+	keys := make([]string, 0, len(path))
+	for _, key := range path {
+		keys = append(keys, tomlKey(key))
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlString returns s as a TOML basic string.
+//
+// strconv.Quote is not a TOML encoder: it writes \a, \v and \xNN, none of
+// which TOML has, so the escapes are spelled out here.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
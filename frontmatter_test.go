@@ -0,0 +1,323 @@
+package markdown
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWithFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	type page struct {
+		Title string   `yaml:"title"`
+		Draft bool     `yaml:"draft"`
+		Tags  []string `yaml:"tags,omitempty"`
+		Slug  string   `yaml:"slug,omitempty"`
+	}
+
+	tests := map[string]struct {
+		v    any
+		want string
+	}{
+		"a struct keeps the order of its fields": {
+			v:    page{Title: "Release notes", Draft: true, Tags: []string{"go", "docs"}},
+			want: "---\ntitle: Release notes\ndraft: true\ntags:\n  - go\n  - docs\n---",
+		},
+		"a pointer to a struct": {
+			v:    &page{Title: "Guide"},
+			want: "---\ntitle: Guide\ndraft: false\n---",
+		},
+		"strings YAML would misread are quoted": {
+			v:    map[string]any{"title": "Checkout: API", "answer": "yes", "version": "1.10"},
+			want: "---\nanswer: \"yes\"\ntitle: 'Checkout: API'\nversion: \"1.10\"\n---",
+		},
+		"a nested map": {
+			v:    map[string]any{"params": map[string]int{"weight": 10}},
+			want: "---\nparams:\n  weight: 10\n---",
+		},
+		"an empty map": {
+			v:    map[string]string{},
+			want: "---\n---",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, WithFrontMatter(tt.v)).H1("Title")
+			if err := m.Error(); err != nil {
+				t.Fatal(err)
+			}
+			want := strings.ReplaceAll(tt.want, "\n", lf()) + lf() + "# Title"
+			if got := m.String(); got != want {
+				t.Errorf("String() = %q, want %q", got, want)
+			}
+
+			// What was written reads back as what was given.
+			var back, given map[string]any
+			text := strings.Trim(tt.want, "-\n")
+			if err := yaml.Unmarshal([]byte(text), &back); err != nil {
+				t.Fatalf("the front matter is not YAML: %v", err)
+			}
+			raw, err := yaml.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal(raw, &given); err != nil {
+				t.Fatal(err)
+			}
+			if len(back) != len(given) {
+				t.Errorf("read back %v, want %v", back, given)
+			}
+			for key, value := range given {
+				if got, ok := back[key]; !ok || !equalYAMLValues(got, value) {
+					t.Errorf("%s read back as %v, want %v", key, got, value)
+				}
+			}
+		})
+	}
+}
+
+// equalYAMLValues compares two values decoded from YAML.
+func equalYAMLValues(a, b any) bool {
+	ya, errA := yaml.Marshal(a)
+	yb, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && string(ya) == string(yb)
+}
+
+func TestWithTOMLFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	type author struct {
+		Name string `yaml:"name"`
+	}
+	type page struct {
+		Title   string            `yaml:"title"`
+		Weight  float64           `yaml:"weight"`
+		Tags    []string          `yaml:"tags"`
+		Params  map[string]string `yaml:"params"`
+		Authors []author          `yaml:"authors"`
+		Summary *string           `yaml:"summary"`
+		Draft   bool              `yaml:"draft"`
+	}
+
+	v := page{
+		Title:   "Say \"hi\"\tC:\\dir",
+		Weight:  1.5,
+		Tags:    []string{"a", "b"},
+		Params:  map[string]string{"the key": "v"},
+		Authors: []author{{Name: "x"}, {Name: "y"}},
+	}
+	want := strings.Join([]string{
+		"+++",
+		`title = "Say \"hi\"\tC:\\dir"`,
+		"weight = 1.5",
+		`tags = ["a", "b"]`,
+		"draft = false",
+		"",
+		"[params]",
+		`"the key" = "v"`,
+		"",
+		"[[authors]]",
+		`name = "x"`,
+		"",
+		"[[authors]]",
+		`name = "y"`,
+		"+++",
+	}, lf())
+
+	m := NewMarkdown(nil, WithTOMLFrontMatter(v))
+	if err := m.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestTOMLFrontMatterFloats(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil, WithTOMLFrontMatter(map[string]any{
+		"whole": float64(3),
+		"big":   1e21,
+		"small": 1e-7,
+		"inf":   math.Inf(-1),
+		"list":  []float64{2, 0.5},
+		"count": 3,
+	}))
+	if err := m.Error(); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"+++",
+		"big = 1e+21",
+		"count = 3",
+		"inf = -inf",
+		"list = [2.0, 0.5]",
+		"small = 1e-07",
+		"whole = 3.0",
+		"+++",
+	}, lf())
+	if got := m.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	type params struct {
+		Ratio float32 `yaml:"ratio"`
+	}
+	type page struct {
+		Weight float64 `yaml:"weight"`
+		Pages  int
+		Score  *float64
+		params `yaml:",inline"`
+	}
+	score := 10.0
+	m = NewMarkdown(nil, WithTOMLFrontMatter(page{Weight: 2, Pages: 2, Score: &score, params: params{Ratio: 1}}))
+	want = strings.Join([]string{"+++", "weight = 2.0", "pages = 2", "score = 10.0", "ratio = 1.0", "+++"}, lf())
+	if got := m.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFrontMatterOfTheWrongType(t *testing.T) {
+	t.Parallel()
+
+	for name, v := range map[string]any{
+		"a string":         "title: x",
+		"a slice":          []string{"a"},
+		"a map of int key": map[int]string{1: "a"},
+		"nil":              nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, opt := range []Option{WithFrontMatter(v), WithTOMLFrontMatter(v)} {
+				m := NewMarkdown(nil, opt).H1("Title")
+				if !errors.Is(m.Error(), ErrInvalidFrontMatter) {
+					t.Errorf("Error() = %v, want %v", m.Error(), ErrInvalidFrontMatter)
+				}
+				if got := m.String(); got != "# Title" {
+					t.Errorf("String() = %q, want the body alone", got)
+				}
+			}
+		})
+	}
+}
+
+func TestFrontMatterIsNotPartOfTheBody(t *testing.T) {
+	t.Parallel()
+
+	fm := map[string]string{"title": "Guide"}
+
+	t.Run("the table of contents and the blocks ignore it", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithFrontMatter(fm)).
+			TableOfContents(TableOfContentsDepthH2).
+			H2("Install")
+
+		want := strings.Join([]string{
+			"---",
+			"title: Guide",
+			"---",
+			TableOfContentsMarkerBegin,
+			"- [Install](#install)",
+			TableOfContentsMarkerEnd,
+			"",
+			"## Install",
+		}, lf())
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+		if got := m.BlockText(0); got != TableOfContentsMarkerBegin {
+			t.Errorf("BlockText(0) = %q, want the table of contents", got)
+		}
+	})
+
+	t.Run("block spacing puts a blank line after it", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithBlockSpacing(), WithFrontMatter(fm)).H1("A").PlainText("b")
+		want := strings.Join([]string{"---", "title: Guide", "---", "", "# A", "", "b"}, lf())
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("a document with front matter only", func(t *testing.T) {
+		t.Parallel()
+
+		want := strings.Join([]string{"---", "title: Guide", "---"}, lf())
+		if got := NewMarkdown(nil, WithFrontMatter(fm)).String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("HTML leaves it out", func(t *testing.T) {
+		t.Parallel()
+
+		got := renderHTML(t, NewMarkdown(nil, WithFrontMatter(fm)).H1("A"))
+		if want := "<h1 id=\"a\">A</h1>\n"; got != want {
+			t.Errorf("HTML() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestParseKeepsFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"yaml":                       "---\ntitle: Guide\n---\n\n# Guide\n",
+		"yaml closed with dots":      "---\ntitle: Guide\n...\n# Guide\n",
+		"toml":                       "+++\ntitle = \"Guide\"\n+++\n# Guide\n",
+		"front matter alone":         "---\ntitle: Guide\n---\n",
+		"a rule is not front matter": "text\n\n---\n\nmore\n",
+		"an unclosed fence":          "---\ntitle: Guide\n",
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := Parse(strings.NewReader(src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := m.String(), strings.ReplaceAll(strings.TrimSuffix(src, "\n"), "\n", lf()); got != want {
+				t.Errorf("String() = %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("the headings under it are found", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := Parse(strings.NewReader("---\ntitle: Guide\n---\n## Install\n"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, ok := m.SectionBounds("Install"); !ok {
+			t.Error("the heading is not found")
+		}
+	})
+
+	t.Run("WithFrontMatter replaces it", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := Parse(strings.NewReader("---\ntitle: Old\n---\n# Guide\n"), nil,
+			WithFrontMatter(map[string]string{"title": "New"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Join([]string{"---", "title: New", "---", "# Guide"}, lf())
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+}
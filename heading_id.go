@@ -0,0 +1,149 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// HeadingIDStyle is the way a heading's explicit ID is written.
+type HeadingIDStyle int
+
+const (
+	// HeadingIDAnchor writes the ID as an empty HTML anchor at the start of the
+	// heading: ## <a id="install"></a>Install. GitHub, GitLab and Azure DevOps
+	// keep the anchor, so it is the default.
+	HeadingIDAnchor HeadingIDStyle = iota
+	// HeadingIDAttribute writes the ID as an attribute after the heading text:
+	// ## Install {#install}. That is what MkDocs, Hugo, Docusaurus, Jekyll and
+	// pandoc read; GitHub shows it as text.
+	HeadingIDAttribute
+)
+
+// headingID matches an ID that every style can write without escaping.
+var headingID = regexp.MustCompile(`^[\p{L}\p{N}_][\p{L}\p{N}_.:-]*$`) //nolint:gochecknoglobals // compiled once
+
+// WithHeadingIDStyle sets how the IDs of headings added with H1WithID to
+// H6WithID are written. An unknown style is recorded as an error.
+func WithHeadingIDStyle(style HeadingIDStyle) Option {
+	return func(m *Markdown) {
+		if style != HeadingIDAnchor && style != HeadingIDAttribute {
+			m.addError(fmt.Errorf("unknown heading id style: %d", int(style)))
+			return
+		}
+		m.headingIDStyle = style
+	}
+}
+
+// H1WithID is H1 with an explicit ID, which stays the same when the text is
+// reworded. CrossReference links to it, and so does the table of contents.
+//
+// The ID is written the way WithHeadingIDStyle says. It has to start with a
+// letter, a digit or '_' and hold only those, '-', '.' and ':'; an ID that does
+// not is recorded as an error and the heading is not added. Bitbucket, which
+// drops both forms, records ErrUnsupportedByDialect.
+func (m *Markdown) H1WithID(text, id string) *Markdown {
+	return m.headingWithID(1, text, id)
+}
+
+// H2WithID is H2 with an explicit ID. See H1WithID.
+func (m *Markdown) H2WithID(text, id string) *Markdown {
+	return m.headingWithID(2, text, id) //nolint:mnd // the heading level
+}
+
+// H3WithID is H3 with an explicit ID. See H1WithID.
+func (m *Markdown) H3WithID(text, id string) *Markdown {
+	return m.headingWithID(3, text, id) //nolint:mnd // the heading level
+}
+
+// H4WithID is H4 with an explicit ID. See H1WithID.
+func (m *Markdown) H4WithID(text, id string) *Markdown {
+	return m.headingWithID(4, text, id) //nolint:mnd // the heading level
+}
+
+// H5WithID is H5 with an explicit ID. See H1WithID.
+func (m *Markdown) H5WithID(text, id string) *Markdown {
+	return m.headingWithID(5, text, id) //nolint:mnd // the heading level
+}
+
+// H6WithID is H6 with an explicit ID. See H1WithID.
+func (m *Markdown) H6WithID(text, id string) *Markdown {
+	return m.headingWithID(6, text, id) //nolint:mnd // the heading level
+}
+
+// headingWithID adds a heading with an explicit ID, once the ID is known to be
+// writable.
+func (m *Markdown) headingWithID(level int, text, id string) *Markdown {
+	if err := validateHeadingID(id); err != nil {
+		m.addError(err)
+		return m
+	}
+	return m.add(&Heading{Level: level, Text: m.literal(text, EscapeHeading), ID: id})
+}
+
+// validateHeadingID reports an ID that cannot be written.
+func validateHeadingID(id string) error {
+	if !headingID.MatchString(id) {
+		return fmt.Errorf("invalid heading id %q: it must start with a letter, a digit or '_' and hold only those, '-', '.' and ':'", id)
+	}
+	return nil
+}
+
+// CrossReference returns a link to the heading with the explicit ID id:
+// [text](#id). The heading can come before or after the link.
+//
+// The ID is checked when the document is built: Build and BuildHTML record an
+// error wrapping ErrUndefinedCrossReference for an ID no heading of the
+// document defines, and one wrapping ErrDuplicateHeadingID for an ID two
+// headings define, since the link would only ever reach the first.
+func (m *Markdown) CrossReference(text, id string) string {
+	m.referTo(id, false)
+	return Link(text, "#"+id)
+}
+
+// referTo registers a cross reference to id, unless one is registered already.
+// reported tells whether its error has been recorded.
+func (m *Markdown) referTo(id string, reported bool) {
+	if m.crossReferences == nil {
+		m.crossReferences = map[string]bool{}
+	}
+	if _, ok := m.crossReferences[id]; !ok {
+		m.crossReferences[id] = reported
+		m.crossReferenceOrder = append(m.crossReferenceOrder, id)
+	}
+}
+
+// resolveCrossReferences records an error for every cross reference to an ID
+// the document does not define once, and for every ID it defines twice.
+func (m *Markdown) resolveCrossReferences() {
+	counts := m.headingIDCounts()
+	for _, id := range m.crossReferenceOrder {
+		if m.crossReferences[id] {
+			continue
+		}
+		switch {
+		case counts[id] == 0:
+			m.addError(fmt.Errorf("cross reference to #%s: %w", id, ErrUndefinedCrossReference))
+		case counts[id] > 1:
+			m.addError(fmt.Errorf("cross reference to #%s, which %d headings define: %w", id, counts[id], ErrDuplicateHeadingID))
+		default:
+			continue
+		}
+		m.crossReferences[id] = true
+	}
+}
+
+// headingIDCounts returns how many headings define each explicit ID. A
+// streaming document counts the headings as they are written.
+func (m *Markdown) headingIDCounts() map[string]int {
+	if m.stream != nil {
+		return m.stream.headingIDs
+	}
+	counts := map[string]int{}
+	walkBlocks(m.body, func(b Block) bool {
+		if h, ok := b.(*Heading); ok && h.ID != "" {
+			counts[h.ID]++
+		}
+		return true
+	})
+	return counts
+}
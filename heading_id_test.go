@@ -0,0 +1,244 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHeadingWithID(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		m    *Markdown
+		want string
+	}{
+		"an anchor by default": {
+			m:    NewMarkdown(nil).H2WithID("Install", "install"),
+			want: `## <a id="install"></a>Install`,
+		},
+		"an attribute": {
+			m:    NewMarkdown(nil, WithHeadingIDStyle(HeadingIDAttribute)).H3WithID("Install", "setup"),
+			want: `### Install {#setup}`,
+		},
+		"every level": {
+			m: NewMarkdown(nil, WithHeadingIDStyle(HeadingIDAttribute)).
+				H1WithID("a", "a").H2WithID("b", "b").H3WithID("c", "c").
+				H4WithID("d", "d").H5WithID("e", "e").H6WithID("f", "f"),
+			want: "# a {#a}" + lf() + "## b {#b}" + lf() + "### c {#c}" + lf() +
+				"#### d {#d}" + lf() + "##### e {#e}" + lf() + "###### f {#f}",
+		},
+		"an ID of letters, digits and punctuation": {
+			m:    NewMarkdown(nil).H1WithID("x", "_v1.2:api-ref"),
+			want: `# <a id="_v1.2:api-ref"></a>x`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.m.Error(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.m.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadingWithIDErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, id := range []string{"", "has space", "-leading", `q"uote`, "a#b"} {
+		t.Run(id, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil).H2WithID("x", id)
+			if m.Error() == nil || m.BlockCount() != 0 {
+				t.Errorf("H2WithID(%q) = %v, %d blocks", id, m.Error(), m.BlockCount())
+			}
+			added := NewMarkdown(nil).AddBlocks(&Heading{Level: 2, Text: "x", ID: id})
+			if id != "" && (added.Error() == nil || added.BlockCount() != 0) {
+				t.Errorf("AddBlocks took the heading: %v", added.Error())
+			}
+		})
+	}
+
+	t.Run("an unknown style", func(t *testing.T) {
+		t.Parallel()
+
+		if NewMarkdown(nil, WithHeadingIDStyle(HeadingIDStyle(7))).Error() == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("Bitbucket", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithDialect(DialectBitbucket)).H2WithID("x", "x")
+		if !errors.Is(m.Error(), ErrUnsupportedByDialect) {
+			t.Errorf("got %v, want ErrUnsupportedByDialect", m.Error())
+		}
+	})
+}
+
+func TestHeadingIDInTableOfContents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("an anchor keeps the derived anchors counting", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).
+			TableOfContents(TableOfContentsDepthH2).
+			H2WithID("Usage", "usage-v2").
+			H2("Usage")
+		out := m.String()
+		for _, want := range []string{"- [Usage](#usage-v2)", "- [Usage](#usage-1)"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("the table of contents has no %q:\n%s", want, out)
+			}
+		}
+
+		html, err := m.HTML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{`<h2 id="usage"><a id="usage-v2"></a>Usage</h2>`, `<h2 id="usage-1">Usage</h2>`} {
+			if !strings.Contains(html, want) {
+				t.Errorf("the html has no %q:\n%s", want, html)
+			}
+		}
+	})
+
+	t.Run("an attribute replaces the derived anchor", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithHeadingIDStyle(HeadingIDAttribute)).
+			TableOfContents(TableOfContentsDepthH2).
+			H2WithID("Usage", "usage-v2").
+			H2("Usage")
+		out := m.String()
+		for _, want := range []string{"- [Usage](#usage-v2)", "- [Usage](#usage)"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("the table of contents has no %q:\n%s", want, out)
+			}
+		}
+
+		html, err := m.HTML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{`<h2 id="usage-v2">Usage</h2>`, `<h2 id="usage">Usage</h2>`} {
+			if !strings.Contains(html, want) {
+				t.Errorf("the html has no %q:\n%s", want, html)
+			}
+		}
+	})
+}
+
+func TestCrossReference(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a reference before and after the heading", func(t *testing.T) {
+		t.Parallel()
+
+		var buf strings.Builder
+		m := NewMarkdown(&buf)
+		m.PlainText("See "+m.CrossReference("installing", "install")+".").
+			H2WithID("Install", "install").
+			PlainText(m.CrossReference("Back up", "install"))
+		if err := m.Build(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "See [installing](#install).") {
+			t.Errorf("the link is missing:\n%s", buf.String())
+		}
+	})
+
+	t.Run("an undefined ID", func(t *testing.T) {
+		t.Parallel()
+
+		var buf strings.Builder
+		m := NewMarkdown(&buf)
+		m.H2("Install").PlainText(m.CrossReference("installing", "install"))
+		if m.Error() != nil {
+			t.Fatalf("the error was recorded before Build: %v", m.Error())
+		}
+
+		err := m.Build()
+		if !errors.Is(err, ErrUndefinedCrossReference) || !strings.Contains(err.Error(), "#install") {
+			t.Errorf("got %v, want ErrUndefinedCrossReference naming #install", err)
+		}
+		if !errors.Is(m.Error(), ErrUndefinedCrossReference) {
+			t.Errorf("Error() = %v, want the recorded error", m.Error())
+		}
+
+		// A second Build reports the same error once.
+		again := m.Build()
+		if strings.Count(again.Error(), "#install") != 1 {
+			t.Errorf("the error was recorded twice: %v", again)
+		}
+	})
+
+	t.Run("an ID two headings define", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(&strings.Builder{})
+		m.H2WithID("A", "x").H2WithID("B", "x").PlainText(m.CrossReference("x", "x"))
+		if err := m.Build(); !errors.Is(err, ErrDuplicateHeadingID) {
+			t.Errorf("got %v, want ErrDuplicateHeadingID", err)
+		}
+	})
+
+	t.Run("a heading removed after the reference", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(&strings.Builder{})
+		m.H2WithID("A", "a").PlainText(m.CrossReference("a", "a"))
+		m.ReplaceBlocks(0, 1, NewMarkdown(nil))
+		if err := m.Build(); !errors.Is(err, ErrUndefinedCrossReference) {
+			t.Errorf("got %v, want ErrUndefinedCrossReference", err)
+		}
+	})
+
+	t.Run("BuildHTML", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(&strings.Builder{})
+		m.PlainText(m.CrossReference("a", "a"))
+		if err := m.BuildHTML(); !errors.Is(err, ErrUndefinedCrossReference) {
+			t.Errorf("got %v, want ErrUndefinedCrossReference", err)
+		}
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		t.Parallel()
+
+		var buf strings.Builder
+		m := NewMarkdown(&buf, WithStreaming())
+		m.PlainText(m.CrossReference("later", "later")).
+			PlainText(m.CrossReference("never", "never")).
+			H2WithID("Later", "later")
+		err := m.Build()
+		if !errors.Is(err, ErrUndefinedCrossReference) || !strings.Contains(err.Error(), "#never") || strings.Contains(err.Error(), "#later") {
+			t.Errorf("got %v, want ErrUndefinedCrossReference naming #never only", err)
+		}
+	})
+}
+
+func TestParsedHeadingWithIDIsRewritten(t *testing.T) {
+	t.Parallel()
+
+	m, err := Parse(strings.NewReader("Install\n=======\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heading, _ := m.Blocks()[0].(*Heading)
+	heading.ID = "install"
+
+	if got, want := m.String(), `# <a id="install"></a>Install`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
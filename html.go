@@ -0,0 +1,521 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// HTMLOption configures the HTML a document is rendered as.
+type HTMLOption func(*htmlConfig)
+
+// htmlConfig is what the HTMLOptions set.
+type htmlConfig struct {
+	// page wraps the fragment in a complete HTML document.
+	page bool
+	// title is the title of the page.
+	title string
+	// stylesheet is the CSS inlined into the page.
+	stylesheet string
+	// stylesheetURL is a stylesheet the page links to instead.
+	stylesheetURL string
+	// head is extra markup for the head of the page.
+	head string
+}
+
+// WithHTMLPage renders a complete, standalone HTML page instead of a fragment:
+// a doctype, a head holding the title and a stylesheet, and the document inside
+// <article class="markdown-body">.
+//
+// An empty title uses the text of the first heading of the document.
+func WithHTMLPage(title string) HTMLOption {
+	return func(c *htmlConfig) {
+		c.page = true
+		c.title = title
+	}
+}
+
+// WithHTMLStylesheet replaces the stylesheet inlined into the page. It implies
+// WithHTMLPage with an empty title unless that option is given too.
+func WithHTMLStylesheet(css string) HTMLOption {
+	return func(c *htmlConfig) {
+		c.page = true
+		c.stylesheet = css
+	}
+}
+
+// WithHTMLStylesheetURL links the page to a stylesheet instead of inlining one.
+// It implies WithHTMLPage with an empty title unless that option is given too.
+func WithHTMLStylesheetURL(url string) HTMLOption {
+	return func(c *htmlConfig) {
+		c.page = true
+		c.stylesheetURL = url
+	}
+}
+
+// WithHTMLHead adds markup to the head of the page, written as it is. That is
+// where the script that draws mermaid diagrams or typesets math goes; the page
+// loads nothing by itself. It implies WithHTMLPage with an empty title unless
+// that option is given too.
+func WithHTMLHead(markup string) HTMLOption {
+	return func(c *htmlConfig) {
+		c.page = true
+		c.head += markup
+	}
+}
+
+// HTML renders the document as HTML, the way GitHub would show it.
+//
+// Tables, task lists, strikethrough, autolinks and footnotes follow GitHub
+// Flavored Markdown. Alerts become <div class="markdown-alert
+// markdown-alert-note"> with a title paragraph, as on GitHub. A mermaid code
+// block becomes <pre class="mermaid">, which is what mermaid.js looks for. Math
+// written with InlineMath, BlockMath or a math code block is wrapped in
+// <span class="math math-inline">\(…\)</span> and
+// <div class="math math-display">\[…\]</div>, which KaTeX and MathJax both
+// pick up. Headings get the id the table of contents links to.
+//
+// HTML in the document, such as Details, is written through unchanged: the
+// page is as trustworthy as the markdown it was built from.
+//
+// Like String, it renders the document whether or not an error was recorded.
+// The error is a rendering failure only; Error and BuildHTML report the
+// recorded one.
+func (m *Markdown) HTML(opts ...HTMLOption) (string, error) {
+	c := &htmlConfig{stylesheet: defaultStylesheet}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	src := []byte(strings.ReplaceAll(m.bodyString(), "\r\n", "\n"))
+	var buf bytes.Buffer
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{dialect: m.dialect, counts: map[string]int{}}))
+	if err := newHTMLConverter(m.headingIDStyle == HeadingIDAttribute).Convert(src, &buf, parser.WithContext(ctx)); err != nil {
+		return "", fmt.Errorf("failed to render html: %w", err)
+	}
+
+	out := buf.String()
+	if c.page {
+		out = c.wrapPage(out, m.headings())
+	}
+	return normalizeLineFeeds(out), nil
+}
+
+// BuildHTML writes the document to the output destination as HTML.
+//
+// It reports errors the way Build does: the recorded error, a nil destination,
+// and a destination that refuses the page, each carrying the earlier error too
+// when there is one.
+func (m *Markdown) BuildHTML(opts ...HTMLOption) error {
+	m.resolveCrossReferences()
+	if m.dest == nil {
+		if m.err != nil {
+			return fmt.Errorf("failed to write html text: destination writer is nil: %s", m.err.Error()) //nolint:wrapcheck
+		}
+		return errors.New("failed to write html text: destination writer is nil")
+	}
+
+	out, err := m.HTML(opts...)
+	if err != nil {
+		return errors.Join(err, m.err)
+	}
+	if _, err := fmt.Fprint(m.dest, out); err != nil {
+		if m.err != nil {
+			return fmt.Errorf("failed to write html text: %w: %s", err, m.err.Error()) //nolint:wrapcheck
+		}
+		return fmt.Errorf("failed to write html text: %w", err)
+	}
+	return m.err
+}
+
+// wrapPage wraps the rendered fragment in a complete HTML document.
+func (c *htmlConfig) wrapPage(body string, headings []headerInfo) string {
+	title := c.title
+	if title == "" && len(headings) > 0 {
+		title = headings[0].text
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	b.WriteString("<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	if c.stylesheetURL != "" {
+		fmt.Fprintf(&b, "<link rel=\"stylesheet\" href=\"%s\">\n", html.EscapeString(c.stylesheetURL))
+	} else if c.stylesheet != "" {
+		fmt.Fprintf(&b, "<style>\n%s\n</style>\n", strings.TrimSpace(c.stylesheet))
+	}
+	if c.head != "" {
+		b.WriteString(strings.TrimRight(c.head, "\n") + "\n")
+	}
+	b.WriteString("</head>\n<body>\n<article class=\"markdown-body\">\n")
+	b.WriteString(body)
+	b.WriteString("</article>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// newHTMLConverter returns the goldmark converter HTML uses. With attributes
+// set, it reads the {#id} after a heading that WithHeadingIDStyle writes.
+func newHTMLConverter(attributes bool) goldmark.Markdown {
+	// The priorities put these ahead of goldmark's own parsers and renderers,
+	// which sit between 100 and 1000, so the fenced code renderer here replaces
+	// the default one.
+	const priority = 50
+	parserOptions := []parser.Option{
+		parser.WithAutoHeadingID(),
+		parser.WithBlockParsers(util.Prioritized(mathBlockParser{}, priority)),
+		parser.WithInlineParsers(util.Prioritized(mathInlineParser{}, priority)),
+		parser.WithASTTransformers(util.Prioritized(alertTransformer{}, priority)),
+	}
+	if attributes {
+		parserOptions = append(parserOptions, parser.WithHeadingAttribute())
+	}
+	return goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(
+			gmhtml.WithUnsafe(),
+			renderer.WithNodeRenderers(util.Prioritized(htmlBlockRenderer{}, priority)),
+		),
+	)
+}
+
+// htmlTag matches an HTML tag inside the text of a heading.
+var htmlTag = regexp.MustCompile(`<[^<>]*>`) //nolint:gochecknoglobals // compiled once
+
+// headingIDs gives headings the anchors the table of contents links to.
+type headingIDs struct {
+	dialect Dialect
+	counts  map[string]int
+}
+
+// Generate returns the anchor of a heading whose text is value. A second
+// heading with the same text gets a suffix, the way generateTableOfContents
+// counts. HTML tags in the text, such as the anchor H1WithID writes, are not
+// part of it, as on GitHub.
+func (ids *headingIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	base := ids.dialect.anchor(htmlTag.ReplaceAllString(string(value), ""))
+	count := ids.counts[base]
+	ids.counts[base] = count + 1
+	return []byte(ids.dialect.uniqueAnchor(base, count))
+}
+
+// Put records an id a heading set explicitly.
+func (ids *headingIDs) Put(value []byte) {
+	ids.counts[string(value)]++
+}
+
+//nolint:gochecknoglobals // node kinds are registered once, like goldmark's own
+var (
+	kindMath      = ast.NewNodeKind("Math")
+	kindMathBlock = ast.NewNodeKind("MathBlock")
+	kindAlert     = ast.NewNodeKind("Alert")
+)
+
+// mathNode is inline math, $…$ or $`…`$.
+type mathNode struct {
+	ast.BaseInline
+	expression []byte
+}
+
+func (n *mathNode) Kind() ast.NodeKind { return kindMath }
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Expression": string(n.expression)}, nil)
+}
+
+// mathBlockNode is display math between two $$ lines.
+type mathBlockNode struct {
+	ast.BaseBlock
+	// closed is set once the closing $$ has been read.
+	closed bool
+}
+
+func (n *mathBlockNode) Kind() ast.NodeKind { return kindMathBlock }
+
+func (n *mathBlockNode) IsRaw() bool { return true }
+
+func (n *mathBlockNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// alertNode is a blockquote that opens with [!NOTE] or one of its siblings.
+type alertNode struct {
+	ast.BaseBlock
+	alert AlertKind
+}
+
+func (n *alertNode) Kind() ast.NodeKind { return kindAlert }
+
+func (n *alertNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Alert": string(n.alert)}, nil)
+}
+
+// mathInlineParser reads $…$ and $`…`$.
+//
+// A dollar sign is also money, so the rules GitHub uses apply: the opening $
+// is not followed by a space, and the closing one is not preceded by a space
+// nor followed by a digit. "$5 and $10" stays text.
+type mathInlineParser struct{}
+
+func (mathInlineParser) Trigger() []byte { return []byte{'$'} }
+
+func (mathInlineParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if len(line) < 3 || line[1] == '$' || line[1] == ' ' || line[1] == '\t' {
+		return nil
+	}
+
+	if line[1] == '`' {
+		end := bytes.Index(line[2:], []byte("`$"))
+		if end < 1 {
+			return nil
+		}
+		block.Advance(end + 4)
+		return &mathNode{expression: line[2 : end+2]}
+	}
+
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '$':
+			if line[i-1] == ' ' || line[i-1] == '\t' || (i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9') {
+				return nil
+			}
+			block.Advance(i + 1)
+			return &mathNode{expression: line[1:i]}
+		}
+	}
+	return nil
+}
+
+// mathBlockParser reads display math: a line holding $$, the expression, and
+// another line holding $$, or the whole of it on one line as $$…$$.
+type mathBlockParser struct{}
+
+func (mathBlockParser) Trigger() []byte { return []byte{'$'} }
+
+func (mathBlockParser) Open(_ ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+
+	node := &mathBlockNode{}
+	rest := bytes.TrimSpace(line[pos+2:])
+	if len(rest) == 0 {
+		return node, parser.NoChildren
+	}
+	if len(rest) < 3 || !bytes.HasSuffix(rest, []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+	start := segment.Start + pos + 2 + bytes.Index(line[pos+2:], rest)
+	node.Lines().Append(text.NewSegment(start, start+len(rest)-2))
+	node.closed = true
+	reader.AdvanceToEOL()
+	return node, parser.NoChildren
+}
+
+func (mathBlockParser) Continue(node ast.Node, reader text.Reader, _ parser.Context) parser.State {
+	n, _ := node.(*mathBlockNode)
+	if n.closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if trimmed := bytes.TrimSpace(line); bytes.HasSuffix(trimmed, []byte("$$")) {
+		if expression := bytes.TrimSuffix(trimmed, []byte("$$")); len(expression) > 0 {
+			start := segment.Start + bytes.Index(line, expression)
+			n.Lines().Append(text.NewSegment(start, start+len(expression)))
+		}
+		n.closed = true
+		reader.AdvanceToEOL()
+		return parser.Close
+	}
+	n.Lines().Append(segment)
+	reader.AdvanceToEOL()
+	return parser.Continue | parser.NoChildren
+}
+
+func (mathBlockParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+func (mathBlockParser) CanInterruptParagraph() bool { return true }
+
+func (mathBlockParser) CanAcceptIndentedLine() bool { return false }
+
+// alertMarker matches the first line of an alert, in either case: GitHub reads
+// both, and the GitLab dialect writes the lower-case one.
+var alertMarker = regexp.MustCompile(`^\[!(?i:(NOTE|TIP|IMPORTANT|WARNING|CAUTION))\]\s*$`) //nolint:gochecknoglobals // compiled once
+
+// alertTransformer turns the blockquotes that open with an alert marker into
+// alerts, dropping the marker line.
+type alertTransformer struct{}
+
+func (alertTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+	quotes := []*ast.Blockquote{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if q, ok := n.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, q)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, q := range quotes {
+		p, ok := q.FirstChild().(*ast.Paragraph)
+		if !ok || p.Lines().Len() == 0 {
+			continue
+		}
+		first := p.Lines().At(0)
+		match := alertMarker.FindSubmatch(first.Value(source))
+		if match == nil {
+			continue
+		}
+
+		// Drop the inline nodes of the marker line, up to the line break.
+		for c := p.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			t, isText := c.(*ast.Text)
+			if isText && t.Segment.Start >= first.Stop {
+				break
+			}
+			p.RemoveChild(p, c)
+			if isText && (t.SoftLineBreak() || t.HardLineBreak()) {
+				break
+			}
+			c = next
+		}
+		if p.ChildCount() == 0 {
+			q.RemoveChild(q, p)
+		}
+
+		alert := &alertNode{alert: AlertKind(strings.ToUpper(string(match[1])))}
+		for c := q.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			alert.AppendChild(alert, c)
+			c = next
+		}
+		q.Parent().ReplaceChild(q.Parent(), q, alert)
+	}
+}
+
+// htmlBlockRenderer writes the nodes goldmark does not know, and fenced code
+// blocks, which it knows but writes one way only.
+type htmlBlockRenderer struct{}
+
+func (r htmlBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, r.renderMath)
+	reg.Register(kindMathBlock, r.renderMathBlock)
+	reg.Register(kindAlert, r.renderAlert)
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (htmlBlockRenderer) renderMath(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n, _ := node.(*mathNode)
+		_, _ = fmt.Fprintf(w, `<span class="math math-inline">\(%s\)</span>`, html.EscapeString(string(n.expression)))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (htmlBlockRenderer) renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeDisplayMath(w, linesOf(node, source))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (htmlBlockRenderer) renderAlert(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n, _ := node.(*alertNode)
+	if entering {
+		kind := strings.ToLower(string(n.alert))
+		title := strings.ToUpper(kind[:1]) + kind[1:]
+		_, _ = fmt.Fprintf(w, "<div class=\"markdown-alert markdown-alert-%s\">\n<p class=\"markdown-alert-title\">%s</p>\n", kind, title)
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString("</div>\n")
+	return ast.WalkContinue, nil
+}
+
+func (htmlBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n, _ := node.(*ast.FencedCodeBlock)
+	code := linesOf(n, source)
+	language := string(n.Language(source))
+
+	switch SyntaxHighlight(language) {
+	case SyntaxHighlightMermaid:
+		_, _ = fmt.Fprintf(w, "<pre class=\"mermaid\">%s</pre>\n", html.EscapeString(code))
+	case "math":
+		writeDisplayMath(w, code)
+	default:
+		_, _ = w.WriteString("<pre><code")
+		if language != "" {
+			_, _ = fmt.Fprintf(w, " class=\"language-%s\"", html.EscapeString(language))
+		}
+		_, _ = fmt.Fprintf(w, ">%s</code></pre>\n", html.EscapeString(code))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// writeDisplayMath writes display math the way KaTeX and MathJax find it.
+func writeDisplayMath(w util.BufWriter, expression string) {
+	_, _ = fmt.Fprintf(w, "<div class=\"math math-display\">\\[%s\\]</div>\n",
+		html.EscapeString(strings.TrimSuffix(expression, "\n")))
+}
+
+// linesOf returns the source lines a block holds, joined.
+func linesOf(node ast.Node, source []byte) string {
+	var b strings.Builder
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		b.Write(segment.Value(source))
+	}
+	return b.String()
+}
+
+// defaultStylesheet is the stylesheet a page gets unless WithHTMLStylesheet or
+// WithHTMLStylesheetURL says otherwise. It styles what HTML writes and nothing
+// else.
+const defaultStylesheet = `
+.markdown-body { box-sizing: border-box; max-width: 980px; margin: 0 auto; padding: 32px; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 16px; line-height: 1.5; color: #1f2328; }
+.markdown-body h1, .markdown-body h2 { padding-bottom: .3em; border-bottom: 1px solid #d1d9e0; }
+.markdown-body a { color: #0969da; }
+.markdown-body code, .markdown-body pre { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 85%; background: #f6f8fa; border-radius: 6px; }
+.markdown-body code { padding: .2em .4em; }
+.markdown-body pre { padding: 16px; overflow: auto; }
+.markdown-body pre code { padding: 0; background: transparent; font-size: 100%; }
+.markdown-body blockquote { margin: 0; padding: 0 1em; color: #59636e; border-left: .25em solid #d1d9e0; }
+.markdown-body table { border-collapse: collapse; }
+.markdown-body th, .markdown-body td { padding: 6px 13px; border: 1px solid #d1d9e0; }
+.markdown-body tr:nth-child(2n) { background: #f6f8fa; }
+.markdown-body hr { height: .25em; border: 0; background: #d1d9e0; }
+.markdown-body img { max-width: 100%; }
+.markdown-body li:has(> input[type="checkbox"]) { list-style: none; }
+.markdown-body details { margin-bottom: 16px; }
+.markdown-body summary { cursor: pointer; }
+.markdown-alert { margin-bottom: 16px; padding: .5em 1em; border-left: .25em solid; }
+.markdown-alert-title { font-weight: 600; }
+.markdown-alert-note { border-color: #0969da; }
+.markdown-alert-tip { border-color: #1a7f37; }
+.markdown-alert-important { border-color: #8250df; }
+.markdown-alert-warning { border-color: #9a6700; }
+.markdown-alert-caution { border-color: #cf222e; }
+pre.mermaid { background: transparent; }
+`
@@ -0,0 +1,219 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nao1215/markdown/internal/golden"
+)
+
+// renderHTML renders the document as HTML with "\n" line endings, failing the
+// test on a rendering error.
+func renderHTML(t *testing.T, m *Markdown, opts ...HTMLOption) string {
+	t.Helper()
+
+	out, err := m.HTML(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.ReplaceAll(out, "\r\n", "\n")
+}
+
+func TestHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		build func(*Markdown) *Markdown
+		want  string
+	}{
+		"a heading gets the anchor the table of contents uses": {
+			build: func(m *Markdown) *Markdown { return m.H2("Getting Started").H2("Getting Started") },
+			want:  "<h2 id=\"getting-started\">Getting Started</h2>\n<h2 id=\"getting-started-1\">Getting Started</h2>\n",
+		},
+		"a table": {
+			build: func(m *Markdown) *Markdown {
+				return m.Table(TableSet{Header: []string{"a"}, Rows: [][]string{{"1"}}, Alignment: []TableAlignment{AlignRight}})
+			},
+			want: "<table>\n<thead>\n<tr>\n<th style=\"text-align:right\">a</th>\n</tr>\n</thead>\n" +
+				"<tbody>\n<tr>\n<td style=\"text-align:right\">1</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		"a task list": {
+			build: func(m *Markdown) *Markdown {
+				return m.CheckBox([]CheckBoxSet{{Checked: true, Text: "done"}, {Text: "todo"}})
+			},
+			want: "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n" +
+				"<li><input disabled=\"\" type=\"checkbox\"> todo</li>\n</ul>\n",
+		},
+		"an alert": {
+			build: func(m *Markdown) *Markdown { return m.Warning("Mind **this**.\nAnd this.") },
+			want: "<div class=\"markdown-alert markdown-alert-warning\">\n" +
+				"<p class=\"markdown-alert-title\">Warning</p>\n" +
+				"<p>Mind <strong>this</strong>.\nAnd this.</p>\n</div>\n",
+		},
+		"a quote that is not an alert": {
+			build: func(m *Markdown) *Markdown { return m.Blockquote("[!NOTE] is how alerts start") },
+			want:  "<blockquote>\n<p>[!NOTE] is how alerts start</p>\n</blockquote>\n",
+		},
+		"details render the markdown inside": {
+			build: func(m *Markdown) *Markdown { return m.Details("More", "**bold**") },
+			want:  "<details>\n<summary>More</summary>\n<p><strong>bold</strong></p>\n</details>\n",
+		},
+		"inline math": {
+			build: func(m *Markdown) *Markdown { return m.PlainText("Euler: " + InlineMath("e^{i\\pi}+1=0")) },
+			want:  "<p>Euler: <span class=\"math math-inline\">\\(e^{i\\pi}+1=0\\)</span></p>\n",
+		},
+		"inline math in the backtick form": {
+			build: func(m *Markdown) *Markdown { return m.PlainText("$`a<b`$") },
+			want:  "<p><span class=\"math math-inline\">\\(a&lt;b\\)</span></p>\n",
+		},
+		"money is not math": {
+			build: func(m *Markdown) *Markdown { return m.PlainText("It costs $5 and $10.") },
+			want:  "<p>It costs $5 and $10.</p>\n",
+		},
+		"block math": {
+			build: func(m *Markdown) *Markdown { return m.PlainText(BlockMath("x^2 + y^2")) },
+			want:  "<div class=\"math math-display\">\\[x^2 + y^2\\]</div>\n",
+		},
+		"block math on one line": {
+			build: func(m *Markdown) *Markdown { return m.PlainText("$$x$$") },
+			want:  "<div class=\"math math-display\">\\[x\\]</div>\n",
+		},
+		"a math code block": {
+			build: func(m *Markdown) *Markdown { return m.CodeBlocks("math", "x < y") },
+			want:  "<div class=\"math math-display\">\\[x &lt; y\\]</div>\n",
+		},
+		"a mermaid code block": {
+			build: func(m *Markdown) *Markdown { return m.CodeBlocks(SyntaxHighlightMermaid, "graph TD\nA-->B") },
+			want:  "<pre class=\"mermaid\">graph TD\nA--&gt;B\n</pre>\n",
+		},
+		"any other code block": {
+			build: func(m *Markdown) *Markdown { return m.CodeBlocks(SyntaxHighlightGo, "a := <-ch") },
+			want:  "<pre><code class=\"language-go\">a := &lt;-ch\n</code></pre>\n",
+		},
+		"a nested list": {
+			build: func(m *Markdown) *Markdown { return m.BulletListTree(Item("a", Item("b"))) },
+			want:  "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n</ul>\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := renderHTML(t, tt.build(NewMarkdown(nil))); got != tt.want {
+				t.Errorf("HTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLTableOfContentsLinksResolve(t *testing.T) {
+	t.Parallel()
+
+	got := renderHTML(t, NewMarkdown(nil).
+		H1("Guide").
+		TableOfContents(TableOfContentsDepthH3).
+		H2("Install & Run").
+		H3("Install & Run"))
+
+	for _, anchor := range []string{"install--run", "install--run-1"} {
+		if !strings.Contains(got, `href="#`+anchor+`"`) || !strings.Contains(got, `id="`+anchor+`"`) {
+			t.Errorf("anchor %q is not both linked and defined:\n%s", anchor, got)
+		}
+	}
+}
+
+func TestHTMLPage(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).
+		H1("Report").
+		PlainText("All checks passed.").
+		CodeBlocks(SyntaxHighlightMermaid, "pie\n\"ok\" : 1")
+
+	t.Run("golden page", func(t *testing.T) {
+		t.Parallel()
+
+		if err := golden.Assert("page.html", renderHTML(t, m, WithHTMLPage("Nightly <report>"))); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("the title defaults to the first heading", func(t *testing.T) {
+		t.Parallel()
+
+		if got := renderHTML(t, m, WithHTMLPage("")); !strings.Contains(got, "<title>Report</title>") {
+			t.Errorf("no default title in:\n%s", got)
+		}
+	})
+
+	t.Run("a linked stylesheet replaces the inline one", func(t *testing.T) {
+		t.Parallel()
+
+		got := renderHTML(t, m, WithHTMLStylesheetURL("style.css?v=1&x=2"), WithHTMLHead(`<script src="mermaid.js"></script>`))
+		if !strings.Contains(got, `<link rel="stylesheet" href="style.css?v=1&amp;x=2">`) {
+			t.Errorf("no stylesheet link in:\n%s", got)
+		}
+		if strings.Contains(got, "<style>") {
+			t.Errorf("the default stylesheet is still inlined:\n%s", got)
+		}
+		if !strings.Contains(got, `<script src="mermaid.js"></script>`+"\n</head>") {
+			t.Errorf("the head markup is missing:\n%s", got)
+		}
+	})
+
+	t.Run("a custom stylesheet is inlined", func(t *testing.T) {
+		t.Parallel()
+
+		got := renderHTML(t, m, WithHTMLStylesheet("body { color: red; }"))
+		if !strings.Contains(got, "<style>\nbody { color: red; }\n</style>") {
+			t.Errorf("the custom stylesheet is missing:\n%s", got)
+		}
+	})
+}
+
+func TestBuildHTML(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes the fragment", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		if err := NewMarkdown(&buf).H1("Title").BuildHTML(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := strings.ReplaceAll(buf.String(), "\r\n", "\n"), "<h1 id=\"title\">Title</h1>\n"; got != want {
+			t.Errorf("BuildHTML() wrote %q, want %q", got, want)
+		}
+	})
+
+	t.Run("returns the recorded error", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		m := NewMarkdown(&buf).Table(TableSet{Header: []string{"a"}, Rows: [][]string{{"1", "2"}}})
+		if err := m.BuildHTML(); !errors.Is(err, ErrMismatchColumn) {
+			t.Errorf("BuildHTML() = %v, want %v", err, ErrMismatchColumn)
+		}
+	})
+
+	t.Run("a nil writer", func(t *testing.T) {
+		t.Parallel()
+
+		err := NewMarkdown(nil).H1("Title").BuildHTML()
+		if err == nil || !strings.Contains(err.Error(), "destination writer is nil") {
+			t.Errorf("BuildHTML() = %v, want the nil writer error", err)
+		}
+	})
+
+	t.Run("a failing writer", func(t *testing.T) {
+		t.Parallel()
+
+		err := NewMarkdown(&failingWriter{}).H1("Title").BuildHTML()
+		if err == nil || !strings.Contains(err.Error(), "failed to write html text") {
+			t.Errorf("BuildHTML() = %v, want the write error", err)
+		}
+	})
+}
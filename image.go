@@ -0,0 +1,117 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// ImageOptions are the ways CustomImage can show an image beyond what Image
+// writes. Markdown has no syntax for a size, an alignment or an image per
+// color scheme, so setting any of those writes the image as HTML.
+type ImageOptions struct {
+	// Width is the width the image is shown at, in pixels such as "120" or as
+	// a share of the page such as "50%", or "" for the width of the image.
+	Width string
+	// Height is the height the image is shown at, in the same form as Width.
+	Height string
+	// Align floats the image to the left or the right of the text around it
+	// with AlignLeft or AlignRight, or centers it on a line of its own with
+	// AlignCenter. AlignDefault leaves it where it is.
+	Align TableAlignment
+	// DarkURL is the image shown to a reader whose color scheme is dark, the
+	// URL CustomImage is given being the one for a light scheme, or "" for the
+	// same image in both.
+	DarkURL string
+	// Title is the text a browser shows on hover, or "" for none.
+	Title string
+	// Link is the URL the image links to, or "" for an image that is only an
+	// image.
+	Link string
+}
+
+// needsHTML reports whether the options can only be written as HTML.
+func (o ImageOptions) needsHTML() bool {
+	return o.Width != "" || o.Height != "" || o.Align != AlignDefault || o.DarkURL != ""
+}
+
+// CustomImage returns the image at url with the alternative text text, shown
+// the way options say.
+//
+// Options that markdown can say, a title and a link, keep the image markdown:
+// ![text](url "title"), inside a link when there is one. A URL holding a
+// space, a parenthesis or an angle bracket is written as <url>, so that it
+// does not end the destination early. A size, an alignment
+// or a dark variant make it an <img> element, inside a <picture> element that
+// picks the dark variant by prefers-color-scheme, and inside <a> when there is
+// a link; every attribute is HTML-escaped. A centered image is a <p> of its
+// own, which has to start a line.
+func CustomImage(text, url string, options ImageOptions) string {
+	if !options.needsHTML() {
+		image := "![" + text + "](" + internal.LinkDestination(url)
+		if options.Title != "" {
+			image += ` "` + strings.ReplaceAll(oneLine(options.Title), `"`, `\"`) + `"`
+		}
+		image += ")"
+		if options.Link != "" {
+			return "[" + image + "](" + internal.LinkDestination(options.Link) + ")"
+		}
+		return image
+	}
+
+	lf := internal.LineFeed()
+	image := "<img" + htmlAttribute("src", url) + htmlAttribute("alt", oneLine(text))
+	if options.Title != "" {
+		image += htmlAttribute("title", oneLine(options.Title))
+	}
+	if options.Width != "" {
+		image += htmlAttribute("width", options.Width)
+	}
+	if options.Height != "" {
+		image += htmlAttribute("height", options.Height)
+	}
+	switch options.Align {
+	case AlignLeft:
+		image += htmlAttribute("align", "left")
+	case AlignRight:
+		image += htmlAttribute("align", "right")
+	case AlignDefault, AlignCenter:
+	}
+	image += ">"
+
+	if options.DarkURL != "" {
+		image = "<picture>" + lf +
+			`  <source media="(prefers-color-scheme: dark)"` + htmlAttribute("srcset", options.DarkURL) + ">" + lf +
+			"  " + image + lf +
+			"</picture>"
+	}
+	if options.Link != "" {
+		image = "<a" + htmlAttribute("href", options.Link) + ">" + image + "</a>"
+	}
+	if options.Align == AlignCenter {
+		image = `<p align="center">` + lf + image + lf + "</p>"
+	}
+	return image
+}
+
+// htmlAttribute returns ` name="value"` with the value escaped.
+func htmlAttribute(name, value string) string {
+	return fmt.Sprintf(` %s="%s"`, name, html.EscapeString(value))
+}
+
+// CustomImage writes an image shown the way options say, as a block of its
+// own. See the package-level CustomImage.
+//
+// An image written as HTML is followed by a blank line, which ends the HTML
+// block: without it the block after the image would be read as HTML too.
+// WithEscaping escapes the alternative text of an image written as markdown;
+// in HTML it is always escaped. Bitbucket strips HTML, so an image that needs
+// it is recorded as ErrUnsupportedByDialect there.
+func (m *Markdown) CustomImage(text, url string, options ImageOptions) *Markdown {
+	if !options.needsHTML() {
+		text = m.literal(text, EscapeLinkText)
+	}
+	return m.add(&Picture{Text: text, URL: url, Options: options})
+}
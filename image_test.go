@@ -0,0 +1,147 @@
+package markdown
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCustomImage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		text    string
+		url     string
+		options ImageOptions
+		want    string
+	}{
+		"no options": {
+			text: "logo",
+			url:  "logo.png",
+			want: "![logo](logo.png)",
+		},
+		"title": {
+			text:    "logo",
+			url:     "logo.png",
+			options: ImageOptions{Title: `The "new" logo`},
+			want:    `![logo](logo.png "The \"new\" logo")`,
+		},
+		"link": {
+			text:    "logo",
+			url:     "logo.png",
+			options: ImageOptions{Link: "https://example.com"},
+			want:    "[![logo](logo.png)](https://example.com)",
+		},
+		"a URL with spaces and parentheses": {
+			text:    "logo",
+			url:     "my logo (dark).png",
+			options: ImageOptions{Link: "https://example.com/a (b)"},
+			want:    "[![logo](<my logo (dark).png>)](<https://example.com/a (b)>)",
+		},
+		"a URL with an unbalanced parenthesis": {
+			text: "logo",
+			url:  "logo).png",
+			want: "![logo](<logo).png>)",
+		},
+		"size": {
+			text:    "logo",
+			url:     "logo.png",
+			options: ImageOptions{Width: "120", Height: "50%"},
+			want:    `<img src="logo.png" alt="logo" width="120" height="50%">`,
+		},
+		"floated": {
+			text:    "logo",
+			url:     "logo.png",
+			options: ImageOptions{Align: AlignRight, Title: "Logo"},
+			want:    `<img src="logo.png" alt="logo" title="Logo" align="right">`,
+		},
+		"centered and linked": {
+			text:    "logo",
+			url:     "logo.png",
+			options: ImageOptions{Align: AlignCenter, Link: "https://example.com"},
+			want:    "<p align=\"center\">\n<a href=\"https://example.com\"><img src=\"logo.png\" alt=\"logo\"></a>\n</p>",
+		},
+		"dark variant": {
+			text:    "logo",
+			url:     "light.png",
+			options: ImageOptions{DarkURL: "dark.png", Width: "200"},
+			want: "<picture>\n  <source media=\"(prefers-color-scheme: dark)\" srcset=\"dark.png\">\n" +
+				"  <img src=\"light.png\" alt=\"logo\" width=\"200\">\n</picture>",
+		},
+		"attributes are escaped": {
+			text:    `a "quoted" <b>logo</b> & more`,
+			url:     `logo.png?a=1&b="2"`,
+			options: ImageOptions{Width: `1" onload="x`},
+			want: `<img src="logo.png?a=1&amp;b=&#34;2&#34;" alt="a &#34;quoted&#34; &lt;b&gt;logo&lt;/b&gt; &amp; more"` +
+				` width="1&#34; onload=&#34;x">`,
+		},
+		"line breaks in the text": {
+			text:    "two\nlines",
+			url:     "logo.png",
+			options: ImageOptions{Width: "10"},
+			want:    `<img src="logo.png" alt="two lines" width="10">`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), CustomImage(tt.text, tt.url, tt.options)); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMarkdownCustomImage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("an HTML image ends its HTML block", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).
+			H1("Title").
+			CustomImage("logo", "logo.png", ImageOptions{Width: "100"}).
+			PlainText("*text*")
+		want := "# Title\n<img src=\"logo.png\" alt=\"logo\" width=\"100\">\n\n*text*"
+		if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("a markdown image", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithEscaping()).CustomImage("[logo]", "logo.png", ImageOptions{}).PlainText("text")
+		want := "![\\[logo\\]](logo.png)\ntext"
+		if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("escaping leaves HTML alone", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil, WithEscaping()).CustomImage("[logo]", "logo.png", ImageOptions{Align: AlignLeft})
+		want := "<img src=\"logo.png\" alt=\"[logo]\" align=\"left\">\n"
+		if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Bitbucket", func(t *testing.T) {
+		t.Parallel()
+
+		if err := NewMarkdown(nil, WithDialect(DialectBitbucket)).
+			CustomImage("logo", "logo.png", ImageOptions{Link: "https://example.com"}).Error(); err != nil {
+			t.Errorf("Error() = %v for a markdown image, want nil", err)
+		}
+		err := NewMarkdown(nil, WithDialect(DialectBitbucket)).
+			CustomImage("logo", "logo.png", ImageOptions{DarkURL: "dark.png"}).Error()
+		if !errors.Is(err, ErrUnsupportedByDialect) {
+			t.Errorf("Error() = %v, want ErrUnsupportedByDialect", err)
+		}
+	})
+}
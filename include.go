@@ -0,0 +1,257 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Include appends the blocks of other, a document built on its own, with the
+// level of every heading it holds moved by shift: a shift of 1 writes its H1 as
+// an H2, which turns a chapter built as a document into a section of this one.
+// A level moved past H1 or H6 stays there.
+//
+// Everything other recorded comes along, which writing other.String() as
+// PlainText would lose. Its error is recorded here. Its headings join the table
+// of contents, at their new levels. Its footnotes and reference links are
+// registered here, and renumbered in its text when their numbers are taken. A
+// table of contents it asked for is this document's, as with ReplaceBlocks. Its
+// cross references are checked against the headings of this document when it
+// is built.
+//
+// The blocks are copied, so other is left as it was, and they are written the
+// way this document writes blocks, its options included. Front matter is not
+// a block and stays behind, and a document built WithStreaming has already
+// written its blocks and has none left to give. A nil other adds nothing.
+func (m *Markdown) Include(other *Markdown, shift int) *Markdown {
+	if other == nil {
+		return m
+	}
+	m.adopt(other)
+
+	renumber, _ := m.renumbering(other)
+	inc := inclusion{shift: shift, renumber: renumber}
+	for _, b := range other.body {
+		m.add(inc.block(b))
+	}
+	return m
+}
+
+// blockBody returns the builder holding the blocks build adds, for the body of a
+// block m writes, or nil when build is nil. The error it records and the table
+// of contents it asks for are m's, its cross references are checked against
+// the headings of m, and its footnotes and reference links are registered with
+// m and renumbered in its blocks.
+func (m *Markdown) blockBody(build func(m *Markdown)) *Markdown {
+	if build == nil {
+		return nil
+	}
+	f := m.fragment()
+	f.nested = true
+	build(f)
+	body, _ := m.adoptBody(f)
+	return body
+}
+
+// adoptBody adopts body, the builder holding the blocks of a block m writes,
+// as blockBody does, and reports whether its blocks had to be renumbered. body
+// is returned as it is unless they did, and then a copy is.
+func (m *Markdown) adoptBody(body *Markdown) (*Markdown, bool) {
+	if body == nil {
+		return nil, false
+	}
+	m.adopt(body)
+	renumber, ok := m.renumbering(body)
+	if !ok {
+		return body, false
+	}
+	return inclusion{renumber: renumber}.body(body), true
+}
+
+// renumbering registers the footnotes and reference links of other and
+// returns the replacer that gives their markers the numbers they have here,
+// and whether any marker changes.
+func (m *Markdown) renumbering(other *Markdown) (*strings.Replacer, bool) {
+	pairs := []string{}
+	for i, note := range other.footnotes {
+		if id := m.footnoteID(note); id != i+1 {
+			pairs = append(pairs, FootnoteReference(strconv.Itoa(i+1)), FootnoteReference(strconv.Itoa(id)))
+		}
+	}
+	for i, r := range other.references {
+		if id := m.referenceID(r); id != i+1 {
+			pairs = append(pairs, "]["+strconv.Itoa(i+1)+"]", "]["+strconv.Itoa(id)+"]")
+		}
+	}
+	return strings.NewReplacer(pairs...), len(pairs) > 0
+}
+
+// inclusion copies the blocks of an included document.
+type inclusion struct {
+	// shift is added to the level of every heading.
+	shift int
+	// renumber rewrites the footnote and reference link markers of the text.
+	renumber *strings.Replacer
+}
+
+// blocks returns copies of blocks.
+func (inc inclusion) blocks(blocks []Block) []Block {
+	out := make([]Block, 0, len(blocks))
+	for _, b := range blocks {
+		out = append(out, inc.block(b))
+	}
+	return out
+}
+
+// block returns a copy of b with its headings shifted and its markers
+// renumbered. The code of a code block is left alone.
+func (inc inclusion) block(b Block) Block {
+	switch b := b.(type) {
+	case *Heading:
+		h := *b
+		h.Level = clampHeadingLevel(b.Level + inc.shift)
+		h.Text = inc.renumber.Replace(b.Text)
+		return &h
+	case *Paragraph:
+		p := *b
+		p.Text = inc.renumber.Replace(b.Text)
+		return &p
+	case *List:
+		l := *b
+		l.Items = inc.items(b.Items)
+		return &l
+	case *Table:
+		t := *b
+		t.Set.Header = inc.cells(b.Set.Header)
+		t.Set.Rows = make([][]string, len(b.Set.Rows))
+		for i, row := range b.Set.Rows {
+			t.Set.Rows[i] = inc.cells(row)
+		}
+		return &t
+	case *CodeBlock:
+		c := *b
+		return &c
+	case *Picture:
+		p := *b
+		p.Text = inc.renumber.Replace(b.Text)
+		return &p
+	case *Blockquote:
+		return &Blockquote{Text: inc.renumber.Replace(b.Text)}
+	case *Alert:
+		a := *b
+		a.Title = inc.renumber.Replace(b.Title)
+		a.Text = inc.renumber.Replace(b.Text)
+		a.Body = inc.body(b.Body)
+		return &a
+	case *Details:
+		d := *b
+		d.Summary = inc.renumber.Replace(b.Summary)
+		d.Text = inc.renumber.Replace(b.Text)
+		d.Body = inc.body(b.Body)
+		return &d
+	case *Raw:
+		return inc.raw(b)
+	}
+	return b
+}
+
+// items returns copies of list items, their bodies and nested items included.
+func (inc inclusion) items(items []ListItem) []ListItem {
+	if items == nil {
+		return nil
+	}
+	out := make([]ListItem, len(items))
+	for i, item := range items {
+		out[i] = item
+		out[i].Text = inc.renumber.Replace(item.Text)
+		out[i].Body = inc.body(item.Body)
+		out[i].Items = inc.items(item.Items)
+	}
+	return out
+}
+
+// body returns a copy of the builder holding the blocks of a block, or nil.
+func (inc inclusion) body(body *Markdown) *Markdown {
+	if body == nil {
+		return nil
+	}
+	copied := *body
+	copied.body = inc.blocks(body.body)
+	return &copied
+}
+
+// cells returns a copy of a table row.
+func (inc inclusion) cells(row []string) []string {
+	if row == nil {
+		return nil
+	}
+	out := make([]string, len(row))
+	for i, cell := range row {
+		out[i] = inc.renumber.Replace(cell)
+	}
+	return out
+}
+
+// raw returns a copy of raw text. Text Parse read with headings in it keeps
+// them, shifted, as long as it was not changed since.
+func (inc inclusion) raw(r *Raw) Block {
+	copied := &Raw{Text: inc.renumber.Replace(r.Text)}
+	if _, ok := r.verbatim(r.Text); !ok || len(r.headings) == 0 {
+		return copied
+	}
+
+	headings := make([]headerInfo, len(r.headings))
+	for i, h := range r.headings {
+		h.level = TableOfContentsDepth(clampHeadingLevel(int(h.level) + inc.shift))
+		headings[i] = h
+	}
+	if inc.shift != 0 {
+		copied.Text = shiftHeadings(copied.Text, inc.shift)
+	}
+	copied.source = source{text: copied.Text, fields: []any{copied.Text}, headings: headings}
+	return copied
+}
+
+// atxHeading matches the start of an ATX heading line.
+var atxHeading = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]|$)`) //nolint:gochecknoglobals // compiled once
+
+// shiftHeadings moves the level of every top-level heading of text by shift,
+// writing each as an ATX heading, which is the only kind with more than two
+// levels.
+func shiftHeadings(src string, shift int) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	data := []byte(src)
+	root := goldmark.New(goldmark.WithExtensions(extension.GFM, extension.Footnote)).
+		Parser().Parse(text.NewReader(data))
+
+	lines := strings.Split(src, "\n")
+	lineOf := lineIndex(src)
+	out := make([]string, 0, len(lines))
+	next := 0
+	for n := root.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok || h.Pos() < 0 {
+			continue
+		}
+		first := lineOf(h.Pos())
+		last := first
+		if !atxHeading.MatchString(lines[first]) && h.Lines().Len() > 0 {
+			// A setext heading ends with the line under its text.
+			last = lineOf(h.Lines().At(h.Lines().Len()-1).Start) + 1
+		}
+		heading := strings.Repeat("#", clampHeadingLevel(h.Level+shift))
+		if content := headingText(h, data); content != "" {
+			heading += " " + escapeClosingHashes(content)
+		}
+		out = append(out, lines[next:first]...)
+		out = append(out, heading)
+		next = last + 1
+	}
+	out = append(out, lines[next:]...)
+	return normalizeLineFeeds(strings.Join(out, "\n"))
+}
@@ -0,0 +1,165 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInclude(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		build func() *Markdown
+		want  string
+	}{
+		"headings are shifted and join the table of contents": {
+			build: func() *Markdown {
+				chapter := NewMarkdown(nil).H1("Install").PlainText("Run it.").H2("Linux")
+				return NewMarkdown(nil).
+					H1("Guide").
+					TableOfContents(TableOfContentsDepthH3).
+					Include(chapter, 1)
+			},
+			want: "# Guide\n<!-- BEGIN_TOC -->\n- [Guide](#guide)\n  - [Install](#install)\n    - [Linux](#linux)\n<!-- END_TOC -->\n\n" +
+				"## Install\nRun it.\n### Linux",
+		},
+		"levels stop at H1 and H6": {
+			build: func() *Markdown {
+				return NewMarkdown(nil).
+					Include(NewMarkdown(nil).H2("Up"), -5).
+					Include(NewMarkdown(nil).H5("Down"), 5)
+			},
+			want: "# Up\n###### Down",
+		},
+		"no shift": {
+			build: func() *Markdown {
+				return NewMarkdown(nil).H1("A").Include(NewMarkdown(nil).H2("B").BulletList("c"), 0)
+			},
+			want: "# A\n## B\n- c",
+		},
+		"footnotes and reference links are renumbered": {
+			build: func() *Markdown {
+				m := NewMarkdown(nil)
+				m.PlainText("Parent" + m.Footnote("shared") + " " + m.ReferenceLink("Go", "https://go.dev"))
+
+				other := NewMarkdown(nil)
+				other.PlainText("Own"+other.Footnote("own")+", shared"+other.Footnote("shared")+".").
+					BulletList(other.ReferenceLink("pkg", "https://pkg.go.dev"), other.ReferenceLink("Go", "https://go.dev"))
+				return m.Include(other, 0)
+			},
+			want: "Parent[^1] [Go][1]\nOwn[^2], shared[^1].\n- [pkg][2]\n- [Go][1]\n\n" +
+				"[^1]: shared\n[^2]: own\n\n[1]: https://go.dev\n[2]: https://pkg.go.dev",
+		},
+		"the table of contents of the included document": {
+			build: func() *Markdown {
+				chapter := NewMarkdown(nil).TableOfContents(TableOfContentsDepthH2).H1("A").H2("B")
+				return NewMarkdown(nil).H1("Top").Include(chapter, 1)
+			},
+			want: "# Top\n<!-- BEGIN_TOC -->\n- [Top](#top)\n  - [A](#a)\n<!-- END_TOC -->\n\n## A\n### B",
+		},
+		"nil": {
+			build: func() *Markdown { return NewMarkdown(nil).H1("A").Include(nil, 1) },
+			want:  "# A",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := tt.build()
+			if err := m.Error(); err != nil {
+				t.Fatalf("Error() = %v", err)
+			}
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), m.String()); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIncludeParsedDocument(t *testing.T) {
+	t.Parallel()
+
+	source := "Setext\n======\n\n- item\n## Tight\n\n```sh\n# not a heading\n```\n"
+	chapter, err := Parse(strings.NewReader(source), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMarkdown(nil).H1("Book").TableOfContents(TableOfContentsDepthH6).Include(chapter, 1)
+	want := "# Book\n<!-- BEGIN_TOC -->\n- [Book](#book)\n  - [Setext](#setext)\n    - [Tight](#tight)\n<!-- END_TOC -->\n\n" +
+		"## Setext\n\n- item\n### Tight\n\n```sh\n# not a heading\n```"
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	// The source is written back as it was read: the copy was shifted, not it.
+	if diff := cmp.Diff(normalizeLineFeeds(strings.TrimSuffix(source, "\n")), chapter.String()); diff != "" {
+		t.Errorf("the included document changed (-want +got):\n%s", diff)
+	}
+}
+
+func TestIncludeLeavesTheOtherDocument(t *testing.T) {
+	t.Parallel()
+
+	other := NewMarkdown(nil).H1("A").BulletListTree(Item("b", Item("c")))
+	other.PlainText("d" + other.Footnote("e"))
+	want := other.String()
+
+	m := NewMarkdown(nil)
+	m.PlainText("x" + m.Footnote("y"))
+	m.Include(other, 2)
+	if diff := cmp.Diff(want, other.String()); diff != "" {
+		t.Errorf("the included document changed (-want +got):\n%s", diff)
+	}
+}
+
+func TestIncludeRecordsTheError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	other := NewMarkdown(nil).H1("A")
+	other.addError(boom)
+
+	m := NewMarkdown(nil).TableOfContents(TableOfContentsDepthH2)
+	m.Include(other.TableOfContents(TableOfContentsDepthH2), 0)
+	err := m.Error()
+	if !errors.Is(err, boom) {
+		t.Errorf("Error() = %v, want boom", err)
+	}
+	if !errors.Is(err, errTableOfContentsGenerated) {
+		t.Errorf("Error() = %v, want the second table of contents", err)
+	}
+}
+
+func TestIncludeChecksCrossReferences(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a broken cross reference", func(t *testing.T) {
+		t.Parallel()
+
+		other := NewMarkdown(nil)
+		other.PlainText(other.CrossReference("x", "missing-id"))
+
+		err := NewMarkdown(&strings.Builder{}).H1("A").Include(other, 1).Build()
+		if !errors.Is(err, ErrUndefinedCrossReference) || !strings.Contains(err.Error(), "#missing-id") {
+			t.Errorf("Build() = %v, want ErrUndefinedCrossReference naming #missing-id", err)
+		}
+	})
+
+	t.Run("a heading of the parent", func(t *testing.T) {
+		t.Parallel()
+
+		other := NewMarkdown(nil)
+		other.PlainText(other.CrossReference("install", "install"))
+
+		err := NewMarkdown(&strings.Builder{}).H2WithID("Install", "install").Include(other, 1).Build()
+		if err != nil {
+			t.Errorf("Build() = %v, want nil", err)
+		}
+	})
+}
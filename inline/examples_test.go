@@ -0,0 +1,106 @@
+//go:build linux || darwin
+
+package inline_test
+
+import (
+	"fmt"
+
+	"github.com/nao1215/markdown/inline"
+)
+
+// ExampleNode skips this test on Windows.
+// The newline codes in the comment section where
+// the expected values are written are represented as '\n',
+// causing failures when testing on Windows.
+func ExampleNode() {
+	var title inline.Node = inline.Strong(inline.Text("v1.2 [beta]"))
+	fmt.Println(title.String())
+
+	// Output:
+	// **v1.2 \[beta\]**
+}
+
+func ExampleRender() {
+	fmt.Println(inline.Render(
+		inline.Text("Run "),
+		inline.Code("go test ./..."),
+		inline.Text(" before "),
+		inline.Link("https://example.com/a (b)", inline.Strong(inline.Text("pushing *anything*"))),
+	))
+
+	// Output:
+	// Run `go test ./...` before [**pushing \*anything\***](<https://example.com/a (b)>)
+}
+
+// ExampleText escapes what markdown would read as syntax.
+func ExampleText() {
+	fmt.Println(inline.Render(inline.Text("# 2 * 3 = 6 <not html> [draft]")))
+
+	// Output:
+	// \# 2 \* 3 = 6 \<not html> \[draft\]
+}
+
+// ExampleEmph writes the spaces at either end outside the delimiters.
+func ExampleEmph() {
+	fmt.Println(inline.Render(inline.Text("a"), inline.Emph(inline.Text(" very ")), inline.Text("good idea")))
+
+	// Output:
+	// a *very* good idea
+}
+
+func ExampleStrong() {
+	fmt.Println(inline.Render(inline.Strong(inline.Text("Note:"), inline.Emph(inline.Text(" read this")))))
+
+	// Output:
+	// **Note: *read this***
+}
+
+func ExampleStrike() {
+	fmt.Println(inline.Render(inline.Strike(inline.Text("$10")), inline.Text(" $8")))
+
+	// Output:
+	// ~~\$10~~ \$8
+}
+
+// ExampleCode fences the span with more backticks than it holds.
+func ExampleCode() {
+	fmt.Println(inline.Render(inline.Code("a `b` c")))
+	fmt.Println(inline.Render(inline.Code("`")))
+
+	// Output:
+	// ``a `b` c``
+	// `` ` ``
+}
+
+func ExampleMath() {
+	fmt.Println(inline.Render(inline.Text("Euler: "), inline.Math(`e^{i\pi} + 1 = 0`)))
+
+	// Output:
+	// Euler: $e^{i\pi} + 1 = 0$
+}
+
+// ExampleLink writes a URL with a space or parentheses between angle brackets.
+func ExampleLink() {
+	fmt.Println(inline.Render(inline.Link("https://go.dev", inline.Text("Go"))))
+	fmt.Println(inline.Render(inline.Link("docs/getting started.md", inline.Code("make"), inline.Text(" guide"))))
+
+	// Output:
+	// [Go](https://go.dev)
+	// [`make` guide](<docs/getting started.md>)
+}
+
+func ExampleImage() {
+	fmt.Println(inline.Render(inline.Link("https://go.dev", inline.Image("gopher (blue).png", "the [Go] gopher"))))
+
+	// Output:
+	// [![the \[Go\] gopher](<gopher (blue).png>)](https://go.dev)
+}
+
+// ExampleLineBreak ends a line without ending the paragraph.
+func ExampleLineBreak() {
+	fmt.Println(inline.Render(inline.Text("Roses are red,"), inline.LineBreak(), inline.Text("- and so on")))
+
+	// Output:
+	// Roses are red,\
+	// \- and so on
+}
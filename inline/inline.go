@@ -0,0 +1,164 @@
+// Package inline builds the text of a paragraph, a heading or a table cell from
+// nodes that compose: emphasis around a link around code, and so on.
+//
+// The string helpers of the markdown package, such as markdown.Bold and
+// markdown.Link, paste their argument between the delimiters, so Bold("a*b")
+// or Link("x]y", url) write broken markdown, and Code cannot hold a backtick.
+// The nodes here escape what they hold for where it is written instead: text
+// has its markdown punctuation escaped, a code span is fenced with a run of
+// backticks longer than any inside it, and a URL holding a space or a
+// parenthesis is written between angle brackets.
+//
+//	inline.Render(
+//		inline.Text("Run "),
+//		inline.Code("go test ./..."),
+//		inline.Text(" before "),
+//		inline.Link("https://example.com/a (b)", inline.Strong(inline.Text("pushing *anything*"))),
+//	)
+//
+// gives
+//
+//	Run `go test ./...` before [**pushing \*anything\***](<https://example.com/a (b)>)
+package inline
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// Node is a piece of inline markdown. The functions of this package return
+// them; String writes one as markdown.
+type Node interface {
+	// String returns the node as markdown.
+	String() string
+	// render writes the node to w.
+	render(w *writer)
+}
+
+// Render writes nodes one after another and returns the markdown.
+func Render(nodes ...Node) string {
+	w := &writer{lineStart: true}
+	w.nodes(nodes)
+	return w.String()
+}
+
+// writer collects the markdown of nodes along with what the next node needs to
+// know about where it is written.
+type writer struct {
+	strings.Builder
+	// lineStart is set when the next character starts a line, where '#', '-'
+	// and the like start a block.
+	lineStart bool
+	// inLink is set inside the text of a link, which cannot hold another link.
+	inLink bool
+	// inStrike is set inside a strikethrough, which cannot hold another.
+	inStrike bool
+	// before and after are what is written around the nodes of w by the node
+	// holding them, such as the delimiters of emphasis. They are empty at the
+	// edges of the content.
+	before, after string
+	// rest are the nodes written after the one being written.
+	rest []Node
+	// peeking is set on a writer that only looks at how nodes start, which
+	// does not look further ahead.
+	peeking bool
+}
+
+// nodes writes each of nodes.
+func (w *writer) nodes(nodes []Node) {
+	outer := w.rest
+	for i, n := range nodes {
+		if n != nil {
+			w.rest = append(nodes[i+1:len(nodes):len(nodes)], outer...)
+			n.render(w)
+		}
+	}
+	w.rest = outer
+}
+
+// previous returns the character written just before the next node, or 0 at
+// the start of the content.
+func (w *writer) previous() rune {
+	s := w.String()
+	if s == "" {
+		s = w.before
+	}
+	r, _ := utf8.DecodeLastRuneInString(s)
+	if r == utf8.RuneError {
+		return 0
+	}
+	return r
+}
+
+// following returns what the nodes after the one being written write, or ""
+// at the end of the content. lineStart tells whether they start a line.
+func (w *writer) following(lineStart bool) string {
+	for _, n := range w.rest {
+		if n == nil {
+			continue
+		}
+		peek := &writer{lineStart: lineStart, inLink: w.inLink, inStrike: w.inStrike, after: w.after, peeking: true}
+		n.render(peek)
+		if peek.Len() > 0 {
+			return peek.String()
+		}
+		lineStart = peek.lineStart
+	}
+	return w.after
+}
+
+// write writes markdown that does not start a line.
+func (w *writer) write(s string) {
+	if s == "" {
+		return
+	}
+	w.WriteString(s)
+	w.lineStart = strings.HasSuffix(s, "\n")
+}
+
+// child returns a writer for the nodes between before and after, which are
+// not at the start of a line.
+func (w *writer) child(before, after string) *writer {
+	return &writer{inLink: w.inLink, inStrike: w.inStrike, before: before, after: after, peeking: w.peeking}
+}
+
+// text is literal text.
+type text string
+
+// Text is literal text. Every character that markdown could read as syntax is
+// escaped, so the text reads exactly as given: "2 * 3 = 6" stays a product
+// and "[draft]" stays in brackets. A line break in it is a soft break, as in
+// the source of a paragraph; LineBreak is a hard one. Spaces and tabs that
+// would start a line, where they could start a code block, are dropped, and so
+// are the spaces ending a line, where two of them break it.
+func Text(s string) Node { return text(s) }
+
+func (t text) String() string { return Render(t) }
+
+func (t text) render(w *writer) {
+	lines := strings.Split(strings.ReplaceAll(string(t), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			w.write(internal.LineFeed())
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " \t")
+		}
+		if w.lineStart {
+			line = strings.TrimLeft(line, " \t")
+		}
+		w.write(escapeText(line, w.lineStart))
+	}
+}
+
+// escapeText escapes the markdown punctuation of one line of text. At the
+// start of a line it also escapes what would start a block there.
+func escapeText(s string, lineStart bool) string {
+	s = internal.EscapeInline(s)
+	if lineStart {
+		s = internal.EscapeLineStart(s)
+	}
+	return s
+}
@@ -0,0 +1,184 @@
+package inline
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// lf is the line ending the nodes write.
+func lf() string { return internal.LineFeed() }
+
+// toHTML renders markdown the way GitHub reads it.
+func toHTML(t *testing.T, markdown string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := goldmark.New(goldmark.WithExtensions(extension.GFM)).Convert([]byte(markdown), &buf); err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// tag matches an HTML tag.
+var tag = regexp.MustCompile(`<[^>]*>`)
+
+// textOf returns the text of rendered HTML, tags dropped.
+func textOf(rendered string) string {
+	return html.UnescapeString(tag.ReplaceAllString(rendered, ""))
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		nodes []Node
+		want  string
+	}{
+		"plain text":             {nodes: []Node{Text("hello")}, want: "hello"},
+		"markdown punctuation":   {nodes: []Node{Text(`a*b_c [d] <e> ~f~ $g$ h|i \j` + "`k`")}, want: `a\*b\_c \[d\] \<e> \~f\~ \$g\$ h\|i \\j` + "\\`k\\`"},
+		"an entity":              {nodes: []Node{Text("&amp; R&D & co")}, want: `\&amp; R\&D & co`},
+		"a heading mark":         {nodes: []Node{Text("# not a heading")}, want: `\# not a heading`},
+		"a list mark":            {nodes: []Node{Text("- not a list")}, want: `\- not a list`},
+		"an ordered list mark":   {nodes: []Node{Text("2024. was a year")}, want: `2024\. was a year`},
+		"a quote mark":           {nodes: []Node{Text("> not a quote")}, want: `\> not a quote`},
+		"marks mid-line":         {nodes: []Node{Text("a # b - c > d 1. e")}, want: "a # b - c > d 1. e"},
+		"marks on a later line":  {nodes: []Node{Text("a\n# b\n===")}, want: "a" + lf() + `\# b` + lf() + `\===`},
+		"emphasis":               {nodes: []Node{Emph(Text("a*b"))}, want: `*a\*b*`},
+		"strong":                 {nodes: []Node{Strong(Text("x"))}, want: "**x**"},
+		"strikethrough":          {nodes: []Node{Strike(Text("old"))}, want: "~~old~~"},
+		"spaces outside":         {nodes: []Node{Text("a"), Strong(Text(" b ")), Text("c")}, want: "a **b** c"},
+		"only spaces":            {nodes: []Node{Emph(Text("  "))}, want: "  "},
+		"nothing":                {nodes: []Node{Emph(), Code(""), Math("")}, want: ""},
+		"emphasis in emphasis":   {nodes: []Node{Emph(Emph(Text("x")))}, want: "_*x*_"},
+		"strong in emphasis":     {nodes: []Node{Emph(Strong(Text("x")))}, want: "***x***"},
+		"strike in strike":       {nodes: []Node{Strike(Text("a "), Strike(Text("b")))}, want: "~~a b~~"},
+		"code":                   {nodes: []Node{Code("x := 1")}, want: "`x := 1`"},
+		"code with a backtick":   {nodes: []Node{Code("a`b")}, want: "``a`b``"},
+		"code with two":          {nodes: []Node{Code("a``b`c")}, want: "```a``b`c```"},
+		"code edged by one":      {nodes: []Node{Code("`x`")}, want: "`` `x` ``"},
+		"code edged by spaces":   {nodes: []Node{Code(" x ")}, want: "`  x  `"},
+		"code of spaces":         {nodes: []Node{Code("  ")}, want: "`  `"},
+		"code over lines":        {nodes: []Node{Code("a\nb")}, want: "`a b`"},
+		"math":                   {nodes: []Node{Math("a$b")}, want: `$a\$b$`},
+		"a link":                 {nodes: []Node{Link("https://go.dev", Text("x]y"))}, want: `[x\]y](https://go.dev)`},
+		"a URL with a space":     {nodes: []Node{Link("docs/my file.md", Text("f"))}, want: "[f](<docs/my file.md>)"},
+		"a URL with parentheses": {nodes: []Node{Link("https://w.org/Go_(lang)", Text("Go"))}, want: "[Go](<https://w.org/Go_(lang)>)"},
+		"a URL with brackets":    {nodes: []Node{Link("a <b>", Text("x"))}, want: `[x](<a \<b\>>)`},
+		"a URL with a line feed": {nodes: []Node{Link("a\nb", Text("x"))}, want: "[x](a%0Ab)"},
+		"a link in a link":       {nodes: []Node{Link("https://a", Text("a "), Link("https://b", Text("b")))}, want: "[a b](https://a)"},
+		"an image":               {nodes: []Node{Image("logo (dark).png", "the [logo]")}, want: `![the \[logo\]](<logo (dark).png>)`},
+		"an image in a link":     {nodes: []Node{Link("https://go.dev", Image("gopher.png", "Go"))}, want: "[![Go](gopher.png)](https://go.dev)"},
+		"a line break":           {nodes: []Node{Text("a"), LineBreak(), Text("- b")}, want: `a\` + lf() + `\- b`},
+		"composed": {
+			nodes: []Node{Text("Run "), Code("go test"), Text(" before "), Link("https://example.com/a (b)", Strong(Text("pushing *anything*")))},
+			want:  "Run `go test` before [**pushing \\*anything\\***](<https://example.com/a (b)>)",
+		},
+		"a nil node":                 {nodes: []Node{nil, Text("x")}, want: "x"},
+		"indented text":              {nodes: []Node{Text("    indented")}, want: "indented"},
+		"a tab at a line start":      {nodes: []Node{Text("a\n\tb")}, want: "a" + lf() + "b"},
+		"spaces before a line feed":  {nodes: []Node{Text("a  \nb")}, want: "a" + lf() + "b"},
+		"indented emphasis":          {nodes: []Node{Emph(Text("    x"))}, want: "*x*"},
+		"text after a line break":    {nodes: []Node{Text("a"), LineBreak(), Text("    b")}, want: `a\` + lf() + "b"},
+		"a trailing line break":      {nodes: []Node{Text("a"), LineBreak()}, want: "a"},
+		"two trailing line breaks":   {nodes: []Node{Text("a"), LineBreak(), LineBreak(), Text("")}, want: "a"},
+		"emphasis in a word":         {nodes: []Node{Text("a"), Emph(Text("(b)")), Text("c")}, want: "a<em>(b)</em>c"},
+		"strong after a word":        {nodes: []Node{Text("foo"), Strong(Text(".bar"))}, want: "foo<strong>.bar</strong>"},
+		"strike before a word":       {nodes: []Node{Strike(Text("a.")), Text("b")}, want: "<del>a.</del>b"},
+		"punctuation between spaces": {nodes: []Node{Text("a "), Emph(Text("(b)")), Text(" c")}, want: "a *(b)* c"},
+		"underscores in a word":      {nodes: []Node{Text("a"), Emph(Emph(Text("x")))}, want: "a<em>*x*</em>"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Render(tt.nodes...); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []Node{Text("# a"), Emph(Text("a")), Code("a"), Math("a"), Link("u", Text("a")), Image("u", "a"), LineBreak()} {
+		if got, want := n.String(), Render(n); got != want {
+			t.Errorf("String() = %q, Render = %q", got, want)
+		}
+	}
+}
+
+func TestRenderReadsBack(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		nodes []Node
+		want  string
+	}{
+		"escaped text":         {nodes: []Node{Text(`2 * 3 = 6, a_b_c, [x](y), <b>, ~~no~~, \n, &amp;`)}, want: `<p>2 * 3 = 6, a_b_c, [x](y), &lt;b&gt;, ~~no~~, \n, &amp;amp;</p>`},
+		"a heading mark":       {nodes: []Node{Text("# a")}, want: "<p># a</p>"},
+		"a setext underline":   {nodes: []Node{Text("a\n---")}, want: "<p>a\n---</p>"},
+		"emphasis":             {nodes: []Node{Text("a"), Emph(Text(" b* ")), Text("c")}, want: "<p>a <em>b*</em> c</p>"},
+		"emphasis in emphasis": {nodes: []Node{Emph(Text("a "), Emph(Text("b")))}, want: "<p><em>a <em>b</em></em></p>"},
+		"code":                 {nodes: []Node{Code("``x`` ` y")}, want: "<p><code>``x`` ` y</code></p>"},
+		"code edged by ticks":  {nodes: []Node{Code("`x`")}, want: "<p><code>`x`</code></p>"},
+		"a link":               {nodes: []Node{Link("a (b).md", Text("[x]"))}, want: `<p><a href="a%20(b).md">[x]</a></p>`},
+		"a line break":         {nodes: []Node{Text("a"), LineBreak(), Text("1. b")}, want: "<p>a<br>\n1. b</p>"},
+		"a strikethrough":      {nodes: []Node{Strike(Text("x"), Strike(Text("y")))}, want: "<p><del>xy</del></p>"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := toHTML(t, Render(tt.nodes...)); got != tt.want {
+				t.Errorf("html = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzText checks that text reads back as itself, whatever it holds.
+func FuzzText(f *testing.F) {
+	for _, seed := range []string{"a*b", "# x", "[a](b)", "<div>", "&copy;", "1) x", "a\\", "`x`", "~~y~~", "$x$", "| a |", "**", "_a_"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		// A paragraph drops the spaces around its text, and a tab or four spaces
+		// at the start of a line begin a code block: the line's business, not
+		// the text's.
+		s = strings.TrimSpace(s)
+		if s == "" || !utf8.ValidString(s) || strings.ContainsAny(s, "\t\r\n\x00") {
+			t.Skip()
+		}
+		if got := textOf(toHTML(t, Render(Text(s)))); got != s {
+			t.Errorf("Text(%q) reads back as %q from %q", s, got, Render(Text(s)))
+		}
+	})
+}
+
+// FuzzCode checks that a code span holds any text.
+func FuzzCode(f *testing.F) {
+	for _, seed := range []string{"a`b", "`", "``", " ` ", "x  ", "<b>"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if strings.Trim(s, " ") == "" || !utf8.ValidString(s) || strings.ContainsAny(s, "\t\r\n\x00") {
+			t.Skip()
+		}
+		got := toHTML(t, Render(Code(s)))
+		if !strings.HasPrefix(got, "<p><code>") || strings.Count(got, "<code>") != 1 || textOf(got) != s {
+			t.Errorf("Code(%q) = %q", s, got)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package inline
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// delimited is emphasis, strong emphasis or a strikethrough around nodes.
+type delimited struct {
+	delimiter string
+	children  []Node
+}
+
+// Emph is emphasis, written *like this*.
+//
+// Spaces at either end of the children are written outside the delimiters,
+// where they do not stop the emphasis from being read. Emphasis with nothing
+// but spaces inside is written as the spaces. Where the delimiters would not be
+// read, as around "(b)" in the middle of a word, it is written as HTML,
+// a<em>(b)</em>c, which GitHub reads.
+func Emph(children ...Node) Node { return &delimited{delimiter: "*", children: children} }
+
+// Strong is strong emphasis, written **like this**. See Emph.
+func Strong(children ...Node) Node { return &delimited{delimiter: "**", children: children} }
+
+// Strike is a strikethrough, written ~~like this~~. See Emph. A strikethrough
+// inside another is written as its children, since GitHub reads no double
+// strikethrough.
+func Strike(children ...Node) Node { return &delimited{delimiter: "~~", children: children} }
+
+func (d *delimited) String() string { return Render(d) }
+
+func (d *delimited) render(w *writer) {
+	strike := d.delimiter == "~~"
+	if strike && w.inStrike {
+		w.nodes(d.children)
+		return
+	}
+
+	inner := w.child(d.delimiter, d.delimiter)
+	inner.inStrike = w.inStrike || strike
+	inner.nodes(d.children)
+	content := inner.String()
+
+	body := strings.Trim(content, " ")
+	if body == "" {
+		w.write(content)
+		return
+	}
+	delimiter := d.delimiter
+	if delimiter == "*" && (startsWithSingle(body, '*') || endsWithSingle(body, '*')) {
+		// *emphasis* right inside *emphasis* would read as **strong**.
+		delimiter = "_"
+	}
+	lead := len(content) - len(strings.TrimLeft(content, " "))
+	if !w.lineStart {
+		w.write(content[:lead])
+	}
+	trail := content[lead+len(body):]
+	if w.peeking || flanks(delimiter, body, w.previous(), trail+w.following(false)) {
+		w.write(delimiter + body + delimiter)
+	} else {
+		w.write("<" + tags[d.delimiter] + ">" + body + "</" + tags[d.delimiter] + ">")
+	}
+	w.write(trail)
+}
+
+// tags are the HTML elements written for the delimiters where the delimiters
+// would not be read.
+var tags = map[string]string{"*": "em", "**": "strong", "~~": "del"}
+
+// flanks reports whether delimiter around body is read as emphasis between the
+// character before, 0 at the start of the content, and the text after. Next
+// to a letter, a delimiter is read only on the side of body that does not
+// start or end with punctuation, so a*(b)*c is not emphasis; '_' is not read
+// next to a letter at all.
+func flanks(delimiter, body string, before rune, after string) bool {
+	first, _ := utf8.DecodeRuneInString(body)
+	last, _ := utf8.DecodeLastRuneInString(body)
+	next, _ := utf8.DecodeRuneInString(after)
+	if after == "" {
+		next = 0
+	}
+	open := !isPunct(first) || isSpace(before) || isPunct(before)
+	closing := !isPunct(last) || isSpace(next) || isPunct(next)
+	if delimiter == "_" {
+		open = isSpace(before) || isPunct(before)
+		closing = isSpace(next) || isPunct(next)
+	}
+	return open && closing
+}
+
+// isSpace reports whether r is white space, or 0 for the edge of the content.
+func isSpace(r rune) bool { return r == 0 || unicode.IsSpace(r) }
+
+// isPunct reports whether r is punctuation to markdown, which counts symbols.
+func isPunct(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) }
+
+// startsWithSingle reports whether s starts with exactly one c.
+func startsWithSingle(s string, c byte) bool {
+	return len(s) > 0 && s[0] == c && (len(s) == 1 || s[1] != c)
+}
+
+// endsWithSingle reports whether s ends with exactly one c that is not
+// escaped.
+func endsWithSingle(s string, c byte) bool {
+	n := len(s)
+	return n > 0 && s[n-1] == c && (n == 1 || (s[n-2] != c && s[n-2] != '\\'))
+}
+
+// code is a code span.
+type code string
+
+// Code is a code span. It holds any text, backticks included: the span is
+// fenced with a run of backticks longer than any inside it, and padded with a
+// space where the text starts or ends with a backtick or a space that would
+// otherwise be taken away. A line break is written as a space, which is how a
+// code span reads one anyway. Empty text writes nothing.
+func Code(s string) Node { return code(s) }
+
+func (c code) String() string { return Render(c) }
+
+func (c code) render(w *writer) {
+	s := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(string(c))
+	if s == "" {
+		return
+	}
+	fence := strings.Repeat("`", internal.LongestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") ||
+		(strings.HasPrefix(s, " ") && strings.HasSuffix(s, " ") && strings.Trim(s, " ") != "") {
+		s = " " + s + " "
+	}
+	w.write(fence + s + fence)
+}
+
+// math is inline math.
+type math string
+
+// Math is inline math, written $like this$, which GitHub typesets. A '$' in
+// the expression is escaped, as markdown.InlineMath does it.
+func Math(expression string) Node { return math(expression) }
+
+func (m math) String() string { return Render(m) }
+
+func (m math) render(w *writer) {
+	if m == "" {
+		return
+	}
+	w.write("$" + strings.ReplaceAll(string(m), "$", `\$`) + "$")
+}
+
+// link is a link around nodes.
+type link struct {
+	url      string
+	children []Node
+}
+
+// Link is a link to url whose text is children: [text](url).
+//
+// A URL holding a space, a parenthesis or an angle bracket is written between
+// angle brackets, <like this>, which is the form markdown reads it in. A link
+// inside the text of another is written as its text, since markdown has no
+// links in links.
+func Link(url string, children ...Node) Node { return &link{url: url, children: children} }
+
+func (l *link) String() string { return Render(l) }
+
+func (l *link) render(w *writer) {
+	if w.inLink {
+		w.nodes(l.children)
+		return
+	}
+	inner := w.child("[", "]")
+	inner.inLink = true
+	inner.nodes(l.children)
+	w.write("[" + inner.String() + "](" + internal.LinkDestination(l.url) + ")")
+}
+
+// image is an image.
+type image struct {
+	url string
+	alt string
+}
+
+// Image is an image at url with the alternative text alt: ![alt](url). The
+// text is escaped as Text escapes it, and the URL is written as Link writes
+// it.
+func Image(url, alt string) Node { return &image{url: url, alt: alt} }
+
+func (i *image) String() string { return Render(i) }
+
+func (i *image) render(w *writer) {
+	alt := w.child("[", "]")
+	alt.nodes([]Node{Text(strings.ReplaceAll(i.alt, "\n", " "))})
+	w.write("![" + alt.String() + "](" + internal.LinkDestination(i.url) + ")")
+}
+
+// lineBreak is a hard line break.
+type lineBreak struct{}
+
+// LineBreak is a hard line break: the text after it starts a new line of the
+// same paragraph. It is written as a backslash at the end of the line, which,
+// unlike two trailing spaces, an editor does not strip. A line break that ends
+// the content has no line to start and is dropped.
+func LineBreak() Node { return lineBreak{} }
+
+func (lineBreak) String() string { return Render(lineBreak{}) }
+
+func (lineBreak) render(w *writer) {
+	if w.peeking {
+		// Whether the break is written depends on what follows it.
+		w.lineStart = true
+		return
+	}
+	if w.following(true) == "" {
+		return
+	}
+	w.write(`\` + internal.LineFeed())
+}
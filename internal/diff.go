@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines a hunk shows around a change,
+// which is what diff -u and git show.
+const diffContext = 3
+
+// noNewline is the marker diff -u writes after a last line that has no line
+// ending.
+const noNewline = "\\ No newline at end of file"
+
+// Diff returns a unified diff that turns a into b, headed by their names, or
+// "" when they are the same. Lines end in "\n" on either side; a "\r" before
+// it is part of the line.
+//
+// The output is what diff -u and git diff print, so patch and git apply take
+// it, and CI logs show it the way reviewers are used to reading it.
+func Diff(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	as, bs := splitLines(a), splitLines(b)
+	edits := diffLines(as, bs)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", aName, bName)
+	for _, h := range hunks(edits) {
+		h.write(&buf)
+	}
+	return buf.String()
+}
+
+// splitLines splits text into lines that keep their "\n", so that a last line
+// without one differs from the same line with one.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edit is one line of a diff: ' ' for a line both sides have, '-' for a line
+// only a has and '+' for a line only b has.
+type edit struct {
+	op   byte
+	line string
+	// aLine and bLine are the indexes of the line in a and b, or of the line
+	// it comes before on the side that does not have it.
+	aLine, bLine int
+}
+
+// diffLines returns the shortest edit script from a to b, found with Myers'
+// algorithm. The lines a and b start and end with are matched up front, which
+// keeps the search to the part that changed.
+func diffLines(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{op: ' ', line: a[i], aLine: i, bLine: i})
+	}
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		e.aLine += prefix
+		e.bLine += prefix
+		edits = append(edits, e)
+	}
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, edit{op: ' ', line: a[len(a)-i], aLine: len(a) - i, bLine: len(b) - i})
+	}
+	return edits
+}
+
+// myers returns the shortest edit script from a to b.
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	// trace holds v as it was before each round, which is what walking the
+	// path back needs.
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			x := v[offset+k-1] + 1
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the path myers found from the end back to the start.
+func backtrack(a, b []string, trace [][]int, offset int) []edit {
+	var edits []edit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			edits = append(edits, edit{op: ' ', line: a[x], aLine: x, bLine: y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{op: '+', line: b[y], aLine: x, bLine: y})
+		} else {
+			x--
+			edits = append(edits, edit{op: '-', line: a[x], aLine: x, bLine: y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		edits = append(edits, edit{op: ' ', line: a[x], aLine: x, bLine: y})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// hunk is a run of edits shown together.
+type hunk []edit
+
+// hunks groups the changes with the lines of context around them. Two changes
+// whose context would overlap or touch share a hunk.
+func hunks(edits []edit) []hunk {
+	var out []hunk
+	start, end := -1, -1
+	for i, e := range edits {
+		if e.op == ' ' {
+			continue
+		}
+		from, to := max(0, i-diffContext), min(len(edits), i+diffContext+1)
+		if start >= 0 && from <= end {
+			end = to
+			continue
+		}
+		if start >= 0 {
+			out = append(out, hunk(edits[start:end]))
+		}
+		start, end = from, to
+	}
+	if start >= 0 {
+		out = append(out, hunk(edits[start:end]))
+	}
+	return out
+}
+
+// write writes the hunk with its "@@ -a,n +b,n @@" header.
+func (h hunk) write(buf *strings.Builder) {
+	aCount, bCount := 0, 0
+	for _, e := range h {
+		if e.op != '+' {
+			aCount++
+		}
+		if e.op != '-' {
+			bCount++
+		}
+	}
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(h[0].aLine, aCount), hunkRange(h[0].bLine, bCount))
+	for _, e := range h {
+		buf.WriteByte(e.op)
+		buf.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			buf.WriteString("\n" + noNewline + "\n")
+		}
+	}
+}
+
+// hunkRange returns the range of a hunk's header for a side whose first line
+// has index start. A side with no lines names the line before the hunk, as
+// diff -u does.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
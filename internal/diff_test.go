@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		a, b string
+		want string
+	}{
+		"the same text": {
+			a: "a\nb\n", b: "a\nb\n",
+			want: "",
+		},
+		"a changed line": {
+			a: "1\n2\n3\n4\n5\n6\n7\n8\n", b: "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		"changes far apart": {
+			a: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", b: "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		"changes close together": {
+			a: "1\n2\n3\n4\n5\n6\n7\n", b: "1\nx\n3\n4\n5\n6\ny\n",
+			want: "--- a\n+++ b\n@@ -1,7 +1,7 @@\n 1\n-2\n+x\n 3\n 4\n 5\n 6\n-7\n+y\n",
+		},
+		"an inserted line": {
+			a: "a\nc\n", b: "a\nb\nc\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n a\n+b\n c\n",
+		},
+		"from nothing": {
+			a: "", b: "a\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+a\n",
+		},
+		"no line ending at the end": {
+			a: "a\nb", b: "a\nb\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, Diff("a", "b", tt.a, tt.b)); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// FuzzDiff applies the diff to a and checks that it gives b back, which is what
+// patch would do with it.
+func FuzzDiff(f *testing.F) {
+	f.Add("a\nb\nc\n", "a\nc\nd\n")
+	f.Add("x\n\n\ny", "\ny\n\nx\n")
+	f.Add("", "1\n2\n")
+	f.Fuzz(func(t *testing.T, a, b string) {
+		got, err := applyDiff(a, Diff("a", "b", a, b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != b {
+			t.Errorf("the diff turns %q into %q, want %q", a, got, b)
+		}
+	})
+}
+
+// applyDiff applies a diff Diff wrote to a.
+func applyDiff(a, diff string) (string, error) {
+	if diff == "" {
+		return a, nil
+	}
+	lines := splitLines(a)
+	var out []string
+	next := 0
+	// last is the op of the row before, which a "\ No newline" row is about.
+	var last byte
+	rows := strings.SplitAfter(diff, "\n")
+	for i := 2; i < len(rows); i++ {
+		row := rows[i]
+		switch {
+		case row == "":
+		case strings.HasPrefix(row, "@@ -"):
+			start := strings.TrimPrefix(strings.Fields(row)[1], "-")
+			from, count, _ := strings.Cut(start, ",")
+			n, err := strconv.Atoi(from)
+			if err != nil {
+				return "", err
+			}
+			if count != "0" {
+				n--
+			}
+			out = append(out, lines[next:n]...)
+			next = n
+		case strings.HasPrefix(row, noNewline):
+			if last != '-' {
+				out[len(out)-1] = strings.TrimSuffix(out[len(out)-1], "\n")
+			}
+			continue
+		case row[0] == ' ':
+			out = append(out, row[1:])
+			next++
+		case row[0] == '+':
+			out = append(out, row[1:])
+		case row[0] == '-':
+			next++
+		}
+		if row != "" {
+			last = row[0]
+		}
+	}
+	out = append(out, lines[next:]...)
+	return strings.Join(out, ""), nil
+}
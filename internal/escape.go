@@ -0,0 +1,90 @@
+package internal
+
+import "strings"
+
+// Markdown reads syntax in two places: within a line, where punctuation opens
+// emphasis, code, links and the like, and at the start of a line, where it
+// opens a block. The two helpers here escape one line for each, so that it
+// reads as the text it holds. Which of them a context needs, and what else it
+// needs, is the caller's business.
+
+// EscapeInline escapes the characters of one line that markdown could read as
+// inline syntax: the backslash itself, '`', '*', '_', '[', ']', '<', '~', '$'
+// and '|', and '&' where it would start an entity such as "&amp;".
+//
+// The line is expected to hold no line break.
+func EscapeInline(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\', '`', '*', '_', '[', ']', '<', '~', '$', '|':
+			b.WriteByte('\\')
+		case '&':
+			if i+1 < len(s) && (isLetter(s[i+1]) || s[i+1] == '#') {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// EscapeLineStart escapes the character that would make a line a heading, a
+// quote, a list item or a setext underline. Leading spaces are kept.
+//
+// It is meant for a line EscapeInline has escaped already, which takes care of
+// the markers, such as '*' and '`', that are syntax anywhere.
+func EscapeLineStart(s string) string {
+	rest := strings.TrimLeft(s, " ")
+	indent := s[:len(s)-len(rest)]
+	if rest == "" {
+		return s
+	}
+	switch rest[0] {
+	case '#', '>', '-', '+', '=':
+		return indent + `\` + rest
+	}
+	// An ordered list item: up to nine digits, then '.' or ')'.
+	digits := 0
+	for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits <= 9 && digits < len(rest) && (rest[digits] == '.' || rest[digits] == ')') {
+		return indent + rest[:digits] + `\` + rest[digits:]
+	}
+	return s
+}
+
+// isLetter reports whether c is an ASCII letter.
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// LinkDestination returns url written as the destination of a link or an
+// image. A line break is percent-encoded, and a URL holding a space, a
+// parenthesis or an angle bracket is written in the <...> form, where none of
+// them ends it.
+func LinkDestination(url string) string {
+	url = strings.NewReplacer("\r", "%0D", "\n", "%0A").Replace(url)
+	if !strings.ContainsAny(url, " ()<>\t") {
+		return strings.ReplaceAll(url, `\`, `\\`)
+	}
+	return "<" + strings.NewReplacer(`\`, `\\`, "<", `\<`, ">", `\>`).Replace(url) + ">"
+}
+
+// LongestRun returns the length of the longest run of c in s, which a fence
+// around s must be longer than.
+func LongestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
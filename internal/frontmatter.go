@@ -3,7 +3,9 @@ package internal
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 // FrontMatterTitle returns the `title:` line of a mermaid front matter block.
@@ -42,3 +44,42 @@ func FrontMatterTitle(title string) string {
 func quoteYAML(value string) string {
 	return strconv.Quote(value)
 }
+
+// frontMatterEnd matches the line that closes the front matter a document
+// opens with, by the line it opens with: "---" for YAML and "+++" for TOML.
+var frontMatterEnd = map[string]*regexp.Regexp{ //nolint:gochecknoglobals // compiled once
+	"---": regexp.MustCompile(`(?m)^(---|\.\.\.)[ \t]*$`),
+	"+++": regexp.MustCompile(`(?m)^\+\+\+[ \t]*$`),
+}
+
+// SplitFrontMatter returns the front matter src opens with, if any, and the
+// rest of the document. src ends its lines with "\n".
+func SplitFrontMatter(src string) (frontMatter, rest string) {
+	for open, end := range frontMatterEnd {
+		if !strings.HasPrefix(src, open+"\n") {
+			continue
+		}
+		loc := end.FindStringIndex(src[len(open)+1:])
+		if loc == nil {
+			return "", src
+		}
+		stop := len(open) + 1 + loc[1]
+		return src[:stop], strings.TrimPrefix(src[stop:], "\n")
+	}
+	return "", src
+}
+
+// BlankFrontMatter returns src with the lines of its front matter emptied, so
+// that it is not read as markdown but the lines after it keep their numbers,
+// and the front matter itself. Lines may end with "\r\n".
+func BlankFrontMatter(src string) (blanked, frontMatter string) {
+	frontMatter, _ = SplitFrontMatter(strings.ReplaceAll(src, "\r\n", "\n"))
+	if frontMatter == "" {
+		return src, ""
+	}
+	lines := strings.SplitAfter(src, "\n")
+	for i := 0; i <= strings.Count(frontMatter, "\n"); i++ {
+		lines[i] = lines[i][len(strings.TrimRight(lines[i], "\r\n")):]
+	}
+	return strings.Join(lines, ""), frontMatter
+}
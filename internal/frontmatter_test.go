@@ -128,3 +128,54 @@ func TestFrontMatterTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestBlankFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		want        string
+		frontMatter string
+	}{
+		{
+			name:        "YAML",
+			src:         "---\ntitle: A\n---\n# A\n",
+			want:        "\n\n\n# A\n",
+			frontMatter: "---\ntitle: A\n---",
+		},
+		{
+			name:        "YAML closed by dots",
+			src:         "---\ntitle: A\n...\ntext",
+			want:        "\n\n\ntext",
+			frontMatter: "---\ntitle: A\n...",
+		},
+		{
+			name:        "TOML with CRLF line endings",
+			src:         "+++\r\ntitle = \"A\"\r\n+++\r\ntext\r\n",
+			want:        "\r\n\r\n\r\ntext\r\n",
+			frontMatter: "+++\ntitle = \"A\"\n+++",
+		},
+		{
+			name: "never closed",
+			src:  "---\ntitle: A\n",
+			want: "---\ntitle: A\n",
+		},
+		{
+			name: "a rule further down",
+			src:  "text\n---\nmore\n---\n",
+			want: "text\n---\nmore\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			blanked, frontMatter := BlankFrontMatter(tt.src)
+			if blanked != tt.want || frontMatter != tt.frontMatter {
+				t.Errorf("BlankFrontMatter(%q) = %q, %q, want %q, %q", tt.src, blanked, frontMatter, tt.want, tt.frontMatter)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/karrick/godirwalk"
+	"github.com/nao1215/markdown/internal"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// BrokenLink is a link or an image whose target does not exist.
+type BrokenLink struct {
+	// File is the file the link is in, relative to the directory
+	// CheckLinksInDir checked and with forward slashes. It is "" for a link in
+	// a document CheckLinks checked.
+	File string
+	// Line is the number of the line the link is on, counting from 1.
+	Line int
+	// Target is the destination as the link wrote it.
+	Target string
+	// Reason says what is wrong with it.
+	Reason string
+}
+
+// String returns the link as "file:line: target: reason", the way compilers
+// report a position. The file is left out when there is none.
+func (b BrokenLink) String() string {
+	if b.File == "" {
+		return fmt.Sprintf("%d: %s: %s", b.Line, b.Target, b.Reason)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", b.File, b.Line, b.Target, b.Reason)
+}
+
+// CheckLinks checks the links and images of the document without going
+// online, and returns those whose target does not exist, in the order they
+// appear.
+//
+// A link to #anchor has to match a heading of the document, with the anchor
+// the dialect gives it, which is the one the table of contents links to, or an
+// explicit ID. A link to a file or a directory is resolved from dir, which is
+// where the document is going to be written, and a link to #anchor in a
+// markdown file has to match a heading there. An external URL is only checked
+// for syntax: a scheme, and a host for http and https.
+//
+// The error is a failure to read a file a link points to, other than its not
+// existing. Line numbers count the front matter. A streaming document keeps no
+// blocks, so it has no links to check.
+func (m *Markdown) CheckLinks(dir string) ([]BrokenLink, error) {
+	c := &linkChecker{root: dir, dialect: m.dialect, anchors: map[string]map[string]bool{}}
+	source := []byte(strings.ReplaceAll(m.String(), "\r\n", "\n"))
+	return c.check(source, filepath.Join(dir, "document.md"), "", m.headingIDStyle == HeadingIDAttribute)
+}
+
+// CheckLinksInDir checks the links and images of every markdown file under
+// dir, the way CheckLinks checks a document, and returns the broken ones
+// ordered by file and line. A link starting with "/" is resolved from dir,
+// which GitHub does from the root of the repository. Anchors follow GitHub's
+// rules.
+func CheckLinksInDir(dir string) ([]BrokenLink, error) {
+	files, err := markdownFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &linkChecker{root: dir, dialect: DialectGFM, anchors: map[string]map[string]bool{}}
+	var broken []BrokenLink
+	for _, file := range files {
+		source, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			rel = file
+		}
+		found, err := c.check([]byte(strings.ReplaceAll(string(source), "\r\n", "\n")), file, filepath.ToSlash(rel), false)
+		if err != nil {
+			return nil, err
+		}
+		broken = append(broken, found...)
+	}
+	return broken, nil
+}
+
+// markdownFiles returns the markdown files under dir, sorted.
+func markdownFiles(dir string) ([]string, error) {
+	var files []string
+	err := godirwalk.Walk(dir, &godirwalk.Options{
+		Callback: func(path string, dirent *godirwalk.Dirent) error {
+			if !dirent.IsDir() && isMarkdownFile(path) {
+				files = append(files, path)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// linkChecker resolves links against the file system.
+type linkChecker struct {
+	// root is where a link starting with "/" is resolved from.
+	root string
+	// dialect decides the anchors of headings.
+	dialect Dialect
+	// anchors caches the anchors of each markdown file a link pointed into.
+	anchors map[string]map[string]bool
+}
+
+// check returns the broken links of source, the markdown of the file at path,
+// which is reported as name.
+func (c *linkChecker) check(source []byte, path, name string, attributes bool) ([]BrokenLink, error) {
+	blanked, _ := internal.BlankFrontMatter(string(source))
+	source = []byte(blanked)
+	root := c.parse(source, attributes)
+	own := c.collectAnchors(root, source)
+
+	var broken []BrokenLink
+	var failure error
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var destination string
+		switch n := n.(type) {
+		case *ast.Link:
+			destination = string(n.Destination)
+		case *ast.Image:
+			destination = string(n.Destination)
+		case *ast.AutoLink:
+			if n.AutoLinkType == ast.AutoLinkURL {
+				destination = string(n.URL(source))
+			}
+		default:
+			return ast.WalkContinue, nil
+		}
+
+		reason, err := c.resolve(destination, filepath.Dir(path), own)
+		if err != nil {
+			failure = err
+			return ast.WalkStop, nil
+		}
+		if reason != "" {
+			broken = append(broken, BrokenLink{File: name, Line: lineOf(source, n.Pos()), Target: destination, Reason: reason})
+		}
+		return ast.WalkContinue, nil
+	})
+	if failure != nil {
+		return nil, failure
+	}
+	return broken, nil
+}
+
+// resolve returns why destination, written in a file in dir whose anchors are
+// own, points nowhere, or "" when it resolves.
+func (c *linkChecker) resolve(destination, dir string, own map[string]bool) (string, error) {
+	if destination == "" {
+		return "empty destination", nil
+	}
+	if strings.HasPrefix(destination, "#") {
+		return missingAnchor(own, destination[1:]), nil
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "malformed URL: " + err.Error(), nil
+	}
+	if u.Scheme != "" || strings.HasPrefix(destination, "//") {
+		switch {
+		case (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "") && u.Host == "":
+			return "URL has no host", nil
+		case u.Scheme == "mailto" && u.Opaque == "":
+			return "mailto URL has no address", nil
+		}
+		return "", nil
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(u.Path))
+	if strings.HasPrefix(u.Path, "/") {
+		target = filepath.Join(c.root, filepath.FromSlash(u.Path))
+	}
+	if u.Path == "" {
+		return missingAnchor(own, u.Fragment), nil
+	}
+	info, err := os.Stat(target)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "no such file or directory", nil
+	case err != nil:
+		return "", fmt.Errorf("failed to check link %s: %w", destination, err)
+	}
+	if u.Fragment == "" || info.IsDir() || !isMarkdownFile(target) {
+		return "", nil
+	}
+
+	anchors, ok := c.anchors[target]
+	if !ok {
+		source, err := os.ReadFile(filepath.Clean(target))
+		if err != nil {
+			return "", fmt.Errorf("failed to check link %s: %w", destination, err)
+		}
+		blanked, _ := internal.BlankFrontMatter(strings.ReplaceAll(string(source), "\r\n", "\n"))
+		source = []byte(blanked)
+		anchors = c.collectAnchors(c.parse(source, false), source)
+		c.anchors[target] = anchors
+	}
+	return missingAnchor(anchors, u.Fragment), nil
+}
+
+// missingAnchor returns why anchor is not one of anchors, or "" when it is.
+func missingAnchor(anchors map[string]bool, anchor string) string {
+	if anchor == "" || anchors[anchor] {
+		return ""
+	}
+	if unescaped, err := url.PathUnescape(anchor); err == nil && anchors[unescaped] {
+		return ""
+	}
+	return "no heading or anchor with the id " + anchor
+}
+
+// parse parses source the way HTML does, giving each heading the id HTML
+// gives it.
+func (c *linkChecker) parse(source []byte, attributes bool) ast.Node {
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{dialect: c.dialect, counts: map[string]int{}}))
+	return newHTMLConverter(attributes).Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+}
+
+// htmlAnchor matches the id or name of an HTML element, which a link can point
+// to as it can to a heading.
+var htmlAnchor = regexp.MustCompile(`(?i)<[a-z][^>]*\s(?:id|name)\s*=\s*["']([^"']*)["']`) //nolint:gochecknoglobals // compiled once
+
+// collectAnchors returns the anchors of the document: the ids of its headings
+// and of the HTML elements in it.
+func (c *linkChecker) collectAnchors(root ast.Node, source []byte) map[string]bool {
+	anchors := map[string]bool{}
+	addHTML := func(raw []byte) {
+		for _, match := range htmlAnchor.FindAllSubmatch(raw, -1) {
+			anchors[string(match[1])] = true
+		}
+	}
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			if id, ok := n.AttributeString("id"); ok {
+				if b, ok := id.([]byte); ok {
+					anchors[string(b)] = true
+				}
+			}
+		case *ast.RawHTML:
+			for i := 0; i < n.Segments.Len(); i++ {
+				segment := n.Segments.At(i)
+				addHTML(segment.Value(source))
+			}
+		case *ast.HTMLBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				segment := n.Lines().At(i)
+				addHTML(segment.Value(source))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return anchors
+}
+
+// lineOf returns the number of the line that holds the byte at offset.
+func lineOf(source []byte, offset int) int {
+	if offset < 0 {
+		return 1
+	}
+	return strings.Count(string(source[:min(offset, len(source))]), "\n") + 1
+}
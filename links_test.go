@@ -0,0 +1,216 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeFiles writes each file under dir, creating the directories it needs.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"guide.md":         "# Guide\n\n## Install\n\n## Install\n\n<a id=\"custom\"></a>\n",
+		"docs/my file.md":  "# Spaced\n",
+		"images/logo.png":  "png",
+		"docs/sub/deep.md": "# Deep\n",
+	})
+
+	tests := map[string]struct {
+		build func(m *Markdown)
+		opts  []Option
+		want  []BrokenLink
+	}{
+		"anchors in the document": {
+			build: func(m *Markdown) {
+				m.H1("Title").H2("Usage").H2("Usage").H2WithID("Set up", "setup").
+					PlainText(Link("a", "#usage") + Link("b", "#usage-1") + Link("c", "#setup") + Link("d", "#set-up") + Link("e", "#usage-2"))
+			},
+			want: []BrokenLink{
+				{Line: 5, Target: "#usage-2", Reason: "no heading or anchor with the id usage-2"},
+			},
+		},
+		"the table of contents": {
+			build: func(m *Markdown) {
+				m.H1("Title").TableOfContents(TableOfContentsDepthH3).H2("Café & Co.").H2WithID("Install", "install-it").H3("Install")
+			},
+		},
+		"files and directories": {
+			build: func(m *Markdown) {
+				m.PlainText(Link("a", "guide.md")).
+					PlainText(Link("b", "docs/my%20file.md")).
+					PlainText(Link("c", "<docs/my file.md>")).
+					PlainText(Link("d", "docs/sub")).
+					PlainText(Image("e", "images/logo.png")).
+					PlainText(Link("f", "/docs/sub/deep.md")).
+					PlainText(Link("g", "missing.md")).
+					PlainText(Image("h", "images/missing.png"))
+			},
+			want: []BrokenLink{
+				{Line: 7, Target: "missing.md", Reason: "no such file or directory"},
+				{Line: 8, Target: "images/missing.png", Reason: "no such file or directory"},
+			},
+		},
+		"anchors in other files": {
+			build: func(m *Markdown) {
+				m.PlainText(Link("a", "guide.md#install-1") + Link("b", "guide.md#custom") + Link("c", "guide.md#usage") + Link("d", "images/logo.png#x"))
+			},
+			want: []BrokenLink{
+				{Line: 1, Target: "guide.md#usage", Reason: "no heading or anchor with the id usage"},
+			},
+		},
+		"external URLs": {
+			build: func(m *Markdown) {
+				m.PlainText(Link("a", "https://go.dev/doc?x=1#y")).
+					PlainText(Link("b", "https:///nohost")).
+					PlainText(Link("c", "mailto:gopher@example.com")).
+					PlainText(Link("d", "mailto:")).
+					PlainText(Link("e", "http://[::1")).
+					PlainText("<https://example.com> and www.example.com")
+			},
+			want: []BrokenLink{
+				{Line: 2, Target: "https:///nohost", Reason: "URL has no host"},
+				{Line: 4, Target: "mailto:", Reason: "mailto URL has no address"},
+				{Line: 5, Target: "http://[::1", Reason: "malformed URL: missing ']' in host"},
+			},
+		},
+		"an empty destination": {
+			build: func(m *Markdown) { m.PlainText("[a]()") },
+			want:  []BrokenLink{{Line: 1, Target: "", Reason: "empty destination"}},
+		},
+		"front matter counts": {
+			build: func(m *Markdown) { m.PlainText(Link("a", "#nope")) },
+			opts:  []Option{WithFrontMatter(map[string]string{"title": "x"})},
+			want:  []BrokenLink{{Line: 4, Target: "#nope", Reason: "no heading or anchor with the id nope"}},
+		},
+		"TOML front matter is not markdown": {
+			build: func(m *Markdown) { m.PlainText(Link("a", "#nope")) },
+			opts:  []Option{WithTOMLFrontMatter(map[string]string{"see": "[x](missing.md)"})},
+			want:  []BrokenLink{{Line: 4, Target: "#nope", Reason: "no heading or anchor with the id nope"}},
+		},
+		"attribute-style ids": {
+			build: func(m *Markdown) {
+				m.H2WithID("Install", "setup").PlainText(Link("a", "#setup"))
+			},
+			opts: []Option{WithHeadingIDStyle(HeadingIDAttribute)},
+		},
+		"the dialect's anchors": {
+			build: func(m *Markdown) {
+				m.H2("Install").H2("Install").PlainText(Link("a", "#markdown-header-install_1") + Link("b", "#install"))
+			},
+			opts: []Option{WithDialect(DialectBitbucket)},
+			want: []BrokenLink{{Line: 3, Target: "#install", Reason: "no heading or anchor with the id install"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, tt.opts...)
+			tt.build(m)
+			got, err := m.CheckLinks(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s\n%s", diff, m.String())
+			}
+		})
+	}
+}
+
+func TestCheckLinksInDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"README.md":        "# Project\r\n\r\nSee [the guide](docs/guide.md#usage) and [gone](docs/gone.md).\r\n",
+		"docs/guide.md":    "---\ntitle: Guide\n---\n\n# Guide\n\n## Usage\n\n[up](../README.md#project) [bad](../README.md#nope) [root](/README.md)\n\n```go\n[not a link](nowhere.md)\n```\n",
+		"docs/notes.txt":   "[ignored](nowhere.md)",
+		"docs/sub/deep.MD": "[self](#deep) [parent](../guide.md)\n\n# Deep\n",
+	})
+
+	got, err := CheckLinksInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BrokenLink{
+		{File: "README.md", Line: 3, Target: "docs/gone.md", Reason: "no such file or directory"},
+		{File: "docs/guide.md", Line: 9, Target: "../README.md#nope", Reason: "no heading or anchor with the id nope"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckLinksInDirMissingDir(t *testing.T) {
+	t.Parallel()
+
+	if _, err := CheckLinksInDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("want an error for a missing directory")
+	}
+}
+
+// TestGeneratedIndexLinksResolve checks GenerateIndex against the checker: the
+// index links to every file it lists.
+func TestGeneratedIndexLinksResolve(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.md":            "# A\n",
+		"sub/b.md":        "## B\n",
+		"sub/deeper/c.md": "text\n",
+	})
+	if err := GenerateIndex(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := CheckLinksInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("the index has broken links: %v", got)
+	}
+}
+
+func TestBrokenLinkString(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		link BrokenLink
+		want string
+	}{
+		"with a file":    {link: BrokenLink{File: "docs/a.md", Line: 3, Target: "b.md", Reason: "no such file or directory"}, want: "docs/a.md:3: b.md: no such file or directory"},
+		"without a file": {link: BrokenLink{Line: 3, Target: "#x", Reason: "no heading or anchor with the id x"}, want: "3: #x: no heading or anchor with the id x"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.link.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package markdown
+
+import "github.com/nao1215/markdown/lint"
+
+// Lint checks the document String returns against rules modelled on
+// markdownlint's and returns what it finds, with line numbers. A document that
+// passes gives nil, so a generator's test can fail on any finding without
+// installing node. See the lint package for the rules and the options.
+//
+// WithBlockSpacing, a language on every code block and a single H1 keep a
+// generated document clean of the findings that come up most.
+func (m *Markdown) Lint(opts ...lint.Option) []lint.Finding {
+	return lint.Check(m.String(), opts...)
+}
@@ -0,0 +1,83 @@
+//go:build linux || darwin
+
+package lint_test
+
+import (
+	"fmt"
+
+	"github.com/nao1215/markdown/lint"
+)
+
+// ExampleCheck skips this test on Windows.
+// The newline codes in the comment section where
+// the expected values are written are represented as '\n',
+// causing failures when testing on Windows.
+func ExampleCheck() {
+	document := "# Guide\n\n### Install\n\nSee https://go.dev.\n\n```\ngo install\n```\n"
+	for _, f := range lint.Check(document) {
+		fmt.Println(f)
+	}
+
+	// Output:
+	// 3: MD001/heading-increment heading level jumps from h1 to h3
+	// 5: MD034/no-bare-urls bare URL https://go.dev; write it as <https://go.dev> or as a link
+	// 7: MD040/fenced-code-language fenced code block has no language
+}
+
+func ExampleFinding() {
+	for _, f := range lint.Check("# One\n\n# Two\n") {
+		fmt.Printf("line %d breaks %s (%s)\n", f.Line, f.Rule, f.Rule.Name())
+	}
+
+	// Output:
+	// line 3 breaks MD025 (single-h1)
+}
+
+func ExampleFinding_String() {
+	f := lint.Finding{Line: 4, Rule: lint.RuleTrailingSpaces, Message: "line ends in spaces (1); only the 2 of a hard break are allowed"}
+	fmt.Println(f.String())
+
+	// Output:
+	// 4: MD009/no-trailing-spaces line ends in spaces (1); only the 2 of a hard break are allowed
+}
+
+func ExampleRule() {
+	fmt.Println(lint.RuleListIndent)
+
+	// Output:
+	// MD007
+}
+
+func ExampleRule_Name() {
+	fmt.Println(lint.RuleListIndent.Name())
+
+	// Output:
+	// ul-indent
+}
+
+func ExampleOption() {
+	opts := []lint.Option{lint.WithLineLength(120), lint.WithoutRules(lint.RuleDuplicateHeading)}
+	fmt.Println(len(lint.Check("## Usage\n\n## Usage\n", opts...)))
+
+	// Output:
+	// 0
+}
+
+func ExampleWithLineLength() {
+	for _, f := range lint.Check("a line of some length\n", lint.WithLineLength(10)) {
+		fmt.Println(f)
+	}
+
+	// Output:
+	// 1: MD013/line-length line is 21 characters long, want at most 10
+}
+
+func ExampleWithoutRules() {
+	document := "```\nls\n```\n"
+	fmt.Println(len(lint.Check(document)))
+	fmt.Println(len(lint.Check(document, lint.WithoutRules(lint.RuleFenceLanguage))))
+
+	// Output:
+	// 1
+	// 0
+}
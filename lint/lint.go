@@ -0,0 +1,270 @@
+// Package lint checks a markdown document against rules modelled on
+// markdownlint's, so a generator's tests can fail on a document markdownlint
+// would complain about without installing node.
+//
+// Each rule has markdownlint's ID and behaves as markdownlint's does with its
+// default configuration, as far as a generated document can tell the
+// difference:
+//
+//	MD001 heading-increment      a heading is at most one level deeper than the one before
+//	MD007 ul-indent              a nested bullet list is indented two spaces per level
+//	MD009 no-trailing-spaces     no line ends with spaces, except the two of a hard break
+//	MD013 line-length            no line is longer than 80 characters, unless only a long word crosses the limit
+//	MD024 no-duplicate-heading   no two headings have the same text
+//	MD025 single-h1              a document with a title has no second one
+//	MD034 no-bare-urls           a URL is written as a link, not as bare text
+//	MD040 fenced-code-language   a fenced code block names its language
+//
+// Front matter is not checked, as markdownlint does not check it, but a title
+// in it counts as the document's title for MD025.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Rule is a rule, named by its markdownlint ID such as "MD001".
+type Rule string
+
+const (
+	// RuleHeadingIncrement is MD001: a heading is at most one level deeper
+	// than the heading before it.
+	RuleHeadingIncrement Rule = "MD001"
+	// RuleListIndent is MD007: a bullet list nested in a bullet list is
+	// indented two spaces per level, and a top-level one not at all.
+	RuleListIndent Rule = "MD007"
+	// RuleTrailingSpaces is MD009: no line ends with spaces, except the two
+	// spaces of a hard line break. Code blocks are not checked.
+	RuleTrailingSpaces Rule = "MD009"
+	// RuleLineLength is MD013: no line is longer than the limit, 80 characters
+	// unless WithLineLength says otherwise. A line with no space or tab past
+	// the limit, such as one ending in a long URL, is allowed.
+	RuleLineLength Rule = "MD013"
+	// RuleDuplicateHeading is MD024: no two headings have the same text.
+	RuleDuplicateHeading Rule = "MD024"
+	// RuleSingleH1 is MD025: a document whose first block is an H1, or whose
+	// front matter has a title, has no other H1.
+	RuleSingleH1 Rule = "MD025"
+	// RuleBareURL is MD034: a URL or an email address is not written as bare
+	// text. GitHub links it anyway, but other renderers do not.
+	RuleBareURL Rule = "MD034"
+	// RuleFenceLanguage is MD040: a fenced code block names its language.
+	RuleFenceLanguage Rule = "MD040"
+)
+
+// ruleNames holds markdownlint's alias of each rule.
+var ruleNames = map[Rule]string{ //nolint:gochecknoglobals // a lookup table
+	RuleHeadingIncrement: "heading-increment",
+	RuleListIndent:       "ul-indent",
+	RuleTrailingSpaces:   "no-trailing-spaces",
+	RuleLineLength:       "line-length",
+	RuleDuplicateHeading: "no-duplicate-heading",
+	RuleSingleH1:         "single-h1",
+	RuleBareURL:          "no-bare-urls",
+	RuleFenceLanguage:    "fenced-code-language",
+}
+
+// Name returns markdownlint's alias of the rule, such as "heading-increment",
+// or "" for a rule this package does not know.
+func (r Rule) Name() string {
+	return ruleNames[r]
+}
+
+// Finding is one place where a document breaks a rule.
+type Finding struct {
+	// Line is the number of the line, counting from 1 at the top of the
+	// document, front matter included.
+	Line int
+	// Rule is the rule the line breaks.
+	Rule Rule
+	// Message says what is wrong.
+	Message string
+}
+
+// String returns the finding the way markdownlint prints one:
+// "3: MD001/heading-increment heading level jumps from h1 to h3".
+func (f Finding) String() string {
+	return fmt.Sprintf("%d: %s/%s %s", f.Line, f.Rule, f.Rule.Name(), f.Message)
+}
+
+// Option configures Check.
+type Option func(*config)
+
+// config is what the options set.
+type config struct {
+	lineLength int
+	disabled   map[Rule]bool
+}
+
+// defaultLineLength is markdownlint's default limit for MD013.
+const defaultLineLength = 80
+
+// WithLineLength sets the longest line MD013 allows. Zero or less turns the
+// rule off.
+func WithLineLength(n int) Option {
+	return func(c *config) {
+		c.lineLength = n
+	}
+}
+
+// WithoutRules turns rules off, as a markdownlint configuration setting them
+// to false does.
+func WithoutRules(rules ...Rule) Option {
+	return func(c *config) {
+		for _, r := range rules {
+			c.disabled[r] = true
+		}
+	}
+}
+
+// Check checks the markdown source against the rules and returns what it
+// finds, ordered by line and then by rule. A document that passes every rule
+// gives nil.
+func Check(source string, opts ...Option) []Finding {
+	c := &config{lineLength: defaultLineLength, disabled: map[Rule]bool{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	d := newDocument(source)
+	checks := []struct {
+		rule  Rule
+		check func(d *document, c *config) []Finding
+	}{
+		{RuleHeadingIncrement, checkHeadingIncrement},
+		{RuleListIndent, checkListIndent},
+		{RuleTrailingSpaces, checkTrailingSpaces},
+		{RuleLineLength, checkLineLength},
+		{RuleDuplicateHeading, checkDuplicateHeading},
+		{RuleSingleH1, checkSingleH1},
+		{RuleBareURL, checkBareURL},
+		{RuleFenceLanguage, checkFenceLanguage},
+	}
+
+	var findings []Finding
+	for _, ch := range checks {
+		if !c.disabled[ch.rule] {
+			findings = append(findings, ch.check(d, c)...)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+	return findings
+}
+
+// document is a document parsed once for every rule.
+type document struct {
+	// source is the document with its front matter blanked out, so that line
+	// numbers still count it but nothing in it is read.
+	source []byte
+	// lines is source split into lines, without their line endings.
+	lines []string
+	// root is the parsed document.
+	root ast.Node
+	// title is set when the front matter has a title.
+	title bool
+	// lineStarts holds the offset in source at which each line starts.
+	lineStarts []int
+	// codeLines holds the numbers of the lines inside code blocks.
+	codeLines map[int]bool
+}
+
+// newDocument parses the markdown source.
+func newDocument(source string) *document {
+	source, frontMatter := internal.BlankFrontMatter(source)
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	d := &document{codeLines: map[int]bool{}, title: hasTitle(frontMatter)}
+	d.lines = lines
+	d.source = []byte(strings.Join(lines, "\n"))
+	offset := 0
+	for _, line := range lines {
+		d.lineStarts = append(d.lineStarts, offset)
+		offset += len(line) + 1
+	}
+	d.root = goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser().Parse(text.NewReader(d.source))
+
+	_ = ast.Walk(d.root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindFencedCodeBlock, ast.KindCodeBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				d.codeLines[d.line(n.Lines().At(i).Start)] = true
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return d
+}
+
+// hasTitle reports whether YAML or TOML front matter sets a title.
+func hasTitle(frontMatter string) bool {
+	toml := strings.HasPrefix(frontMatter, "+++")
+	for _, line := range strings.Split(frontMatter, "\n") {
+		line = strings.TrimLeft(line, " \t")
+		if !toml && strings.HasPrefix(line, "title:") {
+			return true
+		}
+		if key, _, ok := strings.Cut(line, "="); toml && ok && strings.TrimRight(key, " \t") == "title" {
+			return true
+		}
+	}
+	return false
+}
+
+// line returns the number of the line that holds the byte at offset.
+func (d *document) line(offset int) int {
+	if offset < 0 {
+		return 1
+	}
+	return sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset })
+}
+
+// headings returns the headings of the document in order.
+func (d *document) headings() []*ast.Heading {
+	var headings []*ast.Heading
+	_ = ast.Walk(d.root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering {
+			headings = append(headings, h)
+		}
+		return ast.WalkContinue, nil
+	})
+	return headings
+}
+
+// text returns the text of n and its children, with the markup left out.
+func (d *document) text(n ast.Node) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(d.source))
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.AutoLink:
+			b.Write(c.Label(d.source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		lines []string
+		opts  []Option
+		want  []Finding
+	}{
+		"a clean document": {
+			lines: []string{"# Title", "", "Text with <https://go.dev>.", "", "- a", "  - b", "", "```go", "x := 1   ", "```"},
+			want:  nil,
+		},
+		"a heading two levels down": {
+			lines: []string{"# A", "", "### B", "", "## C", "", "#### D"},
+			want: []Finding{
+				{Line: 3, Rule: RuleHeadingIncrement, Message: "heading level jumps from h1 to h3"},
+				{Line: 7, Rule: RuleHeadingIncrement, Message: "heading level jumps from h2 to h4"},
+			},
+		},
+		"a setext heading": {
+			lines: []string{"# A", "", "B", "---", "", "C", "==="},
+			want: []Finding{
+				{Line: 6, Rule: RuleSingleH1, Message: "second top-level heading; the document has a title already"},
+			},
+		},
+		"a list indented wrongly": {
+			lines: []string{" - a", "   - b", "     - c", "", "1. x", "   - y"},
+			want: []Finding{
+				{Line: 1, Rule: RuleListIndent, Message: "list item is indented 1 spaces, want 0"},
+				{Line: 2, Rule: RuleListIndent, Message: "list item is indented 3 spaces, want 2"},
+				{Line: 3, Rule: RuleListIndent, Message: "list item is indented 5 spaces, want 4"},
+			},
+		},
+		"a list in a quote": {
+			lines: []string{"> - a", ">   - b", ">    - c"},
+			want: []Finding{
+				{Line: 3, Rule: RuleListIndent, Message: "list item is indented 3 spaces, want 2"},
+			},
+		},
+		"trailing spaces": {
+			lines: []string{"one ", "two  ", "three", "   ", "> [!NOTE]  ", "> four   "},
+			want: []Finding{
+				{Line: 1, Rule: RuleTrailingSpaces, Message: "line ends in spaces (1); only the 2 of a hard break are allowed"},
+				{Line: 4, Rule: RuleTrailingSpaces, Message: "line ends in spaces (3); only the 2 of a hard break are allowed"},
+				{Line: 6, Rule: RuleTrailingSpaces, Message: "line ends in spaces (3); only the 2 of a hard break are allowed"},
+			},
+		},
+		"long lines": {
+			lines: []string{"a b c d e f", "abcdefghij <https://example.com/a/long/path>", "a b c <https://example.com/a/long/path>", "äöüäöüäöü"},
+			opts:  []Option{WithLineLength(9)},
+			want: []Finding{
+				{Line: 1, Rule: RuleLineLength, Message: "line is 11 characters long, want at most 9"},
+				{Line: 2, Rule: RuleLineLength, Message: "line is 44 characters long, want at most 9"},
+			},
+		},
+		"line length off": {
+			lines: []string{strings.Repeat("word ", 40)},
+			opts:  []Option{WithLineLength(0), WithoutRules(RuleTrailingSpaces)},
+			want:  nil,
+		},
+		"duplicate headings": {
+			lines: []string{"## Usage", "", "## *Usage*", "", "## Install", "", "### Usage"},
+			want: []Finding{
+				{Line: 3, Rule: RuleDuplicateHeading, Message: `heading "Usage" repeats the one on line 1`},
+				{Line: 7, Rule: RuleDuplicateHeading, Message: `heading "Usage" repeats the one on line 1`},
+			},
+		},
+		"two titles": {
+			lines: []string{"# One", "", "text", "", "# Two"},
+			want: []Finding{
+				{Line: 5, Rule: RuleSingleH1, Message: "second top-level heading; the document has a title already"},
+			},
+		},
+		"no title": {
+			lines: []string{"text", "", "# One", "", "# Two"},
+			want:  nil,
+		},
+		"a title in the front matter": {
+			lines: []string{"---", "title: Guide", "---", "", "# One"},
+			want: []Finding{
+				{Line: 5, Rule: RuleSingleH1, Message: "second top-level heading; the document has a title already"},
+			},
+		},
+		"front matter is not checked": {
+			lines: []string{"---", "description: https://example.com  ", "---", "", "# One"},
+			want:  nil,
+		},
+		"a title in TOML front matter": {
+			lines: []string{"+++", `title = "Guide"`, "+++", "", "# One"},
+			want: []Finding{
+				{Line: 5, Rule: RuleSingleH1, Message: "second top-level heading; the document has a title already"},
+			},
+		},
+		"TOML front matter is not checked": {
+			lines: []string{"+++", `description = "` + strings.Repeat("long ", 30) + `"  `, "+++", "", "# One"},
+			want:  nil,
+		},
+		"bare URLs": {
+			lines: []string{"See https://go.dev, www.example.com and gopher@example.com.", "", "[go](https://go.dev) `https://x.test` <https://y.test>"},
+			want: []Finding{
+				{Line: 1, Rule: RuleBareURL, Message: "bare URL https://go.dev; write it as <https://go.dev> or as a link"},
+				{Line: 1, Rule: RuleBareURL, Message: "bare URL www.example.com; write it as <www.example.com> or as a link"},
+				{Line: 1, Rule: RuleBareURL, Message: "bare URL gopher@example.com; write it as <gopher@example.com> or as a link"},
+			},
+		},
+		"a fence without a language": {
+			lines: []string{"text", "", "```", "code", "```", "", "~~~ sh", "ls", "~~~", "", "    indented"},
+			want: []Finding{
+				{Line: 3, Rule: RuleFenceLanguage, Message: "fenced code block has no language"},
+			},
+		},
+		"a rule turned off": {
+			lines: []string{"```", "x", "```"},
+			opts:  []Option{WithoutRules(RuleFenceLanguage)},
+			want:  nil,
+		},
+		"CRLF line endings": {
+			lines: []string{"# A\r", "\r", "### B \r"},
+			want: []Finding{
+				{Line: 3, Rule: RuleHeadingIncrement, Message: "heading level jumps from h1 to h3"},
+				{Line: 3, Rule: RuleTrailingSpaces, Message: "line ends in spaces (1); only the 2 of a hard break are allowed"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Check(strings.Join(tt.lines, "\n"), tt.opts...)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	t.Parallel()
+
+	f := Finding{Line: 3, Rule: RuleHeadingIncrement, Message: "heading level jumps from h1 to h3"}
+	if got, want := f.String(), "3: MD001/heading-increment heading level jumps from h1 to h3"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := Rule("MD999").Name(); got != "" {
+		t.Errorf("Name() = %q, want empty", got)
+	}
+}
+
+func TestEveryRuleHasAName(t *testing.T) {
+	t.Parallel()
+
+	for _, r := range []Rule{
+		RuleHeadingIncrement, RuleListIndent, RuleTrailingSpaces, RuleLineLength,
+		RuleDuplicateHeading, RuleSingleH1, RuleBareURL, RuleFenceLanguage,
+	} {
+		if r.Name() == "" {
+			t.Errorf("%s has no name", r)
+		}
+	}
+}
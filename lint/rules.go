@@ -0,0 +1,220 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// checkHeadingIncrement is MD001.
+func checkHeadingIncrement(d *document, _ *config) []Finding {
+	var findings []Finding
+	previous := 0
+	for _, h := range d.headings() {
+		if previous > 0 && h.Level > previous+1 {
+			findings = append(findings, Finding{
+				Line:    d.line(h.Pos()),
+				Rule:    RuleHeadingIncrement,
+				Message: fmt.Sprintf("heading level jumps from h%d to h%d", previous, h.Level),
+			})
+		}
+		previous = h.Level
+	}
+	return findings
+}
+
+// listIndent is how far markdownlint wants each level of a bullet list
+// indented.
+const listIndent = 2
+
+// checkListIndent is MD007. Like markdownlint, it checks only bullet lists
+// whose enclosing lists are bullet lists too: under a numbered item, the
+// indentation follows the width of the number.
+func checkListIndent(d *document, _ *config) []Finding {
+	var findings []Finding
+	_ = ast.Walk(d.root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		item, ok := n.(*ast.ListItem)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		depth, bullets := 0, true
+		for p := item.Parent(); p != nil; p = p.Parent() {
+			if list, ok := p.(*ast.List); ok {
+				bullets = bullets && !list.IsOrdered()
+			}
+			if _, ok := p.(*ast.ListItem); ok {
+				depth++
+			}
+		}
+		if !bullets {
+			return ast.WalkContinue, nil
+		}
+
+		line := d.line(item.Pos())
+		content := unquote(d.lines[line-1])
+		rest := strings.TrimLeft(content, " ")
+		if rest == "" || !strings.ContainsRune("-*+", rune(rest[0])) {
+			// The item does not start its line, as in "- - a".
+			return ast.WalkContinue, nil
+		}
+		if got, want := len(content)-len(rest), depth*listIndent; got != want {
+			findings = append(findings, Finding{
+				Line:    line,
+				Rule:    RuleListIndent,
+				Message: fmt.Sprintf("list item is indented %d spaces, want %d", got, want),
+			})
+		}
+		return ast.WalkContinue, nil
+	})
+	return findings
+}
+
+// unquote returns line without the blockquote markers in front of it.
+func unquote(line string) string {
+	for {
+		rest := strings.TrimLeft(line, " ")
+		if len(line)-len(rest) > 3 || !strings.HasPrefix(rest, ">") {
+			return line
+		}
+		line = strings.TrimPrefix(rest[1:], " ")
+	}
+}
+
+// hardBreak is the number of trailing spaces that make a hard line break,
+// which markdownlint allows.
+const hardBreak = 2
+
+// checkTrailingSpaces is MD009.
+func checkTrailingSpaces(d *document, _ *config) []Finding {
+	var findings []Finding
+	for i, line := range d.lines {
+		if d.codeLines[i+1] {
+			continue
+		}
+		trimmed := strings.TrimRight(line, " ")
+		spaces := len(line) - len(trimmed)
+		if spaces == 0 || (spaces == hardBreak && strings.TrimSpace(trimmed) != "") {
+			continue
+		}
+		findings = append(findings, Finding{
+			Line:    i + 1,
+			Rule:    RuleTrailingSpaces,
+			Message: fmt.Sprintf("line ends in spaces (%d); only the 2 of a hard break are allowed", spaces),
+		})
+	}
+	return findings
+}
+
+// checkLineLength is MD013.
+func checkLineLength(d *document, c *config) []Finding {
+	if c.lineLength <= 0 {
+		return nil
+	}
+	var findings []Finding
+	for i, line := range d.lines {
+		length := utf8.RuneCountInString(line)
+		if length <= c.lineLength {
+			continue
+		}
+		beyond := string([]rune(line)[c.lineLength:])
+		if !strings.ContainsAny(beyond, " \t") {
+			continue
+		}
+		findings = append(findings, Finding{
+			Line:    i + 1,
+			Rule:    RuleLineLength,
+			Message: fmt.Sprintf("line is %d characters long, want at most %d", length, c.lineLength),
+		})
+	}
+	return findings
+}
+
+// checkDuplicateHeading is MD024.
+func checkDuplicateHeading(d *document, _ *config) []Finding {
+	var findings []Finding
+	first := map[string]int{}
+	for _, h := range d.headings() {
+		text := strings.TrimSpace(d.text(h))
+		line := d.line(h.Pos())
+		if earlier, ok := first[text]; ok {
+			findings = append(findings, Finding{
+				Line:    line,
+				Rule:    RuleDuplicateHeading,
+				Message: fmt.Sprintf("heading %q repeats the one on line %d", text, earlier),
+			})
+			continue
+		}
+		first[text] = line
+	}
+	return findings
+}
+
+// checkSingleH1 is MD025.
+func checkSingleH1(d *document, _ *config) []Finding {
+	// The title is the front matter's, or else a leading H1, which is then
+	// the one H1 allowed.
+	var title *ast.Heading
+	if first, ok := d.root.FirstChild().(*ast.Heading); ok && first.Level == 1 && !d.title {
+		title = first
+	}
+	if title == nil && !d.title {
+		return nil
+	}
+
+	var findings []Finding
+	for _, h := range d.headings() {
+		if h.Level != 1 || h == title {
+			continue
+		}
+		findings = append(findings, Finding{
+			Line:    d.line(h.Pos()),
+			Rule:    RuleSingleH1,
+			Message: "second top-level heading; the document has a title already",
+		})
+	}
+	return findings
+}
+
+// checkBareURL is MD034.
+func checkBareURL(d *document, _ *config) []Finding {
+	var findings []Finding
+	_ = ast.Walk(d.root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*ast.AutoLink)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		if pos := link.Pos(); pos >= 0 && pos < len(d.source) && d.source[pos] == '<' {
+			// <https://example.com> is how a bare URL is meant to be written.
+			return ast.WalkContinue, nil
+		}
+		findings = append(findings, Finding{
+			Line:    d.line(link.Pos()),
+			Rule:    RuleBareURL,
+			Message: fmt.Sprintf("bare URL %s; write it as <%s> or as a link", link.Label(d.source), link.Label(d.source)),
+		})
+		return ast.WalkContinue, nil
+	})
+	return findings
+}
+
+// checkFenceLanguage is MD040.
+func checkFenceLanguage(d *document, _ *config) []Finding {
+	var findings []Finding
+	_ = ast.Walk(d.root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		block, ok := n.(*ast.FencedCodeBlock)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		if len(block.Language(d.source)) == 0 {
+			findings = append(findings, Finding{
+				Line:    d.line(block.Pos()),
+				Rule:    RuleFenceLanguage,
+				Message: "fenced code block has no language",
+			})
+		}
+		return ast.WalkContinue, nil
+	})
+	return findings
+}
@@ -0,0 +1,57 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nao1215/markdown/lint"
+)
+
+// TestGeneratedDocumentPassesLint guards the output of the builder itself: a
+// document built the recommended way has nothing for markdownlint to report.
+func TestGeneratedDocumentPassesLint(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil, WithBlockSpacing(), WithFrontMatter(map[string]string{"description": "a guide"})).
+		H1("Guide").
+		TableOfContents(TableOfContentsDepthH3).
+		H2("Install").
+		PlainText("Run the installer, then read "+Link("the docs", "https://go.dev")+".").
+		CodeBlocks(SyntaxHighlightShell, "go install example.com/tool@latest").
+		H2("Usage").
+		BulletListTree(Item("first", Item("nested", Item("deeper"))), Item("second")).
+		OrderedListTree(Item("one", Item("sub"))).
+		CheckBox([]CheckBoxSet{{Text: "done", Checked: true}}).
+		H3("Options").
+		Table(TableSet{Header: []string{"Name", "Meaning"}, Rows: [][]string{{"-v", "verbose"}}}).
+		Note("Every line\nis quoted.").
+		Details("More", "Hidden text.").
+		Blockquote("A quote.")
+
+	if findings := m.Lint(); findings != nil {
+		t.Errorf("the document has findings:\n%s\n\n%s", findingLines(findings), m.String())
+	}
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).H1("A").H3("B").CodeBlocks(SyntaxHighlightNone, "x")
+
+	got := findingLines(m.Lint(lint.WithoutRules(lint.RuleFenceLanguage)))
+	if want := "2: MD001/heading-increment heading level jumps from h1 to h3"; got != want {
+		t.Errorf("Lint() = %q, want %q", got, want)
+	}
+	if n := len(m.Lint()); n != 2 {
+		t.Errorf("Lint() found %d, want 2", n)
+	}
+}
+
+// findingLines returns the findings one per line.
+func findingLines(findings []lint.Finding) string {
+	lines := make([]string, 0, len(findings))
+	for _, f := range findings {
+		lines = append(lines, f.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,187 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// ListItem is one item of a nested list: its own text, the blocks written
+// under that text, and the items nested below it.
+//
+// The nested items are written as a list of the same kind as the one holding
+// them, so the children of a CheckBoxTree item are sub-tasks and the children
+// of an OrderedListTree item are numbered from 1 again.
+type ListItem struct {
+	// Text is the text of the item itself.
+	Text string
+	// Checked marks the item as done. Only CheckBoxTree reads it.
+	Checked bool
+	// Body holds blocks, such as a code block or a quote, written under the
+	// text and indented so that markdown reads them as part of the item. It
+	// may be nil. It is a builder like any other, and what it records joins
+	// the document the list is added to: its error, its cross references, and
+	// its footnotes and reference links, renumbered when their numbers are
+	// taken. Its blocks are written the way the document writes blocks, and
+	// its front matter is left out.
+	Body *Markdown
+	// Items are the items nested under this one.
+	Items []ListItem
+}
+
+// Item returns a ListItem with the given text and nested items. It keeps a
+// tree written inline short enough to read.
+func Item(text string, items ...ListItem) ListItem {
+	return ListItem{Text: text, Items: items}
+}
+
+// marker returns the marker that opens the item at index i of a list.
+func (s ListStyle) marker(i int, item ListItem) string {
+	switch s {
+	case ListStyleOrdered:
+		return fmt.Sprintf("%d. ", i+1)
+	case ListStyleCheckBox:
+		if item.Checked {
+			return "- [x] "
+		}
+		return "- [ ] "
+	case ListStyleBullet:
+		return "- "
+	}
+	return "- "
+}
+
+// indent returns the indentation that places a line under the content of the
+// item a marker opens.
+//
+// The content of a list item starts after the list marker, so that is where a
+// continuation has to be indented to. The task list box is not part of the
+// marker: it is the start of the item's content, and a sub-task indented past
+// it would be read as a code block on a parser that counts four spaces.
+func (s ListStyle) indent(marker string) string {
+	if s == ListStyleCheckBox {
+		return "  "
+	}
+	return strings.Repeat(" ", len(marker))
+}
+
+// BulletListTree is a markdown bullet list whose items may hold nested items
+// and blocks.
+//
+// The whole tree is one block, so the blank line rules that keep a list apart
+// from its neighbors apply to it exactly as they do to BulletList.
+func (m *Markdown) BulletListTree(items ...ListItem) *Markdown {
+	return m.listTree(ListStyleBullet, items)
+}
+
+// OrderedListTree is a markdown numbered list whose items may hold nested
+// items and blocks. Each level is numbered from 1.
+func (m *Markdown) OrderedListTree(items ...ListItem) *Markdown {
+	return m.listTree(ListStyleOrdered, items)
+}
+
+// CheckBoxTree is a markdown task list whose items may hold nested sub-tasks
+// and blocks. ListItem.Checked decides which boxes are ticked.
+func (m *Markdown) CheckBoxTree(items ...ListItem) *Markdown {
+	return m.listTree(ListStyleCheckBox, items)
+}
+
+// listTree appends the tree as a single List block.
+//
+// A tree written one block per item would let the join put a blank line
+// between an item and its children, which ends the list there. One block keeps
+// the indentation under this file's control.
+func (m *Markdown) listTree(style ListStyle, items []ListItem) *Markdown {
+	if len(items) == 0 {
+		return m
+	}
+	items, _ = m.adoptItems(items)
+	return m.add(&List{Style: style, Items: m.literalItems(items)})
+}
+
+// flatList appends the items the way BulletList, OrderedList and CheckBox
+// always have: one marker and one line of text each.
+func (m *Markdown) flatList(style ListStyle, items []ListItem) *Markdown {
+	if len(items) == 0 {
+		return m
+	}
+	return m.add(&List{Style: style, Items: m.literalItems(items), flat: true})
+}
+
+// textItems returns one item per text.
+func textItems(text []string) []ListItem {
+	items := make([]ListItem, 0, len(text))
+	for _, t := range text {
+		items = append(items, ListItem{Text: t})
+	}
+	return items
+}
+
+// adoptItems adopts the bodies of the items, nested ones included, as
+// adoptBody does, and reports whether any of them had to be renumbered. The
+// items are returned as they are unless one did; the caller's are left
+// untouched either way.
+func (m *Markdown) adoptItems(items []ListItem) ([]ListItem, bool) {
+	var adopted []ListItem
+	for i, item := range items {
+		body, bodyChanged := m.adoptBody(item.Body)
+		nested, nestedChanged := m.adoptItems(item.Items)
+		if !bodyChanged && !nestedChanged {
+			continue
+		}
+		if adopted == nil {
+			adopted = append([]ListItem{}, items...)
+		}
+		adopted[i].Body, adopted[i].Items = body, nested
+	}
+	if adopted == nil {
+		return items, false
+	}
+	return adopted, true
+}
+
+// renderListTree writes the items as a list, recursing into the nested ones.
+func renderListTree(r *blockRenderer, style ListStyle, items []ListItem) string {
+	lf := internal.LineFeed()
+
+	lines := make([]string, 0, len(items))
+	for i, item := range items {
+		marker := style.marker(i, item)
+		content := renderListItem(r.indented(len(marker)), style, item)
+		lines = append(lines, marker+indentContinuation(content, style.indent(marker)))
+	}
+	return strings.Join(lines, lf)
+}
+
+// renderListItem writes the content of one item: its text, its body, and its
+// nested list, joined with the same blank line rules as the document.
+func renderListItem(r *blockRenderer, style ListStyle, item ListItem) string {
+	parts := r.render([]Block{&Paragraph{Text: normalizeLineFeeds(item.Text)}})
+	if item.Body != nil {
+		parts = append(parts, r.render(item.Body.body)...)
+	}
+	if len(item.Items) > 0 {
+		parts = append(parts, r.render([]Block{&List{Style: style, Items: item.Items}})...)
+	}
+	// A table or a details block ends with a line feed of its own. Inside an
+	// item that would leave a blank line before the next item, which turns a
+	// tight list loose.
+	return strings.TrimRight(joinBlocks(parts, r.blockSpacing), "\r\n")
+}
+
+// indentContinuation indents every line of text after the first, leaving blank
+// lines empty so the output carries no trailing whitespace.
+func indentContinuation(text, indent string) string {
+	lf := internal.LineFeed()
+
+	lines := strings.Split(text, lf)
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = indent + lines[i]
+	}
+	return strings.Join(lines, lf)
+}
@@ -0,0 +1,175 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestListTrees(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		build func(*Markdown) *Markdown
+		want  []string
+	}{
+		"bullet list nests under the text": {
+			build: func(m *Markdown) *Markdown {
+				return m.BulletListTree(Item("a", Item("b", Item("c"))), Item("d"))
+			},
+			want: []string{"- a", "  - b", "    - c", "- d"},
+		},
+		"ordered list indents past the number": {
+			build: func(m *Markdown) *Markdown {
+				return m.OrderedListTree(Item("a", Item("b")), Item("c"))
+			},
+			want: []string{"1. a", "   1. b", "2. c"},
+		},
+		"a two digit number indents further": {
+			build: func(m *Markdown) *Markdown {
+				items := make([]ListItem, 10)
+				for i := range items {
+					items[i] = Item("x")
+				}
+				items[9].Items = []ListItem{Item("y")}
+				return m.OrderedListTree(items...)
+			},
+			want: []string{
+				"1. x", "2. x", "3. x", "4. x", "5. x", "6. x", "7. x", "8. x", "9. x",
+				"10. x", "    1. y",
+			},
+		},
+		"checkbox children are sub-tasks": {
+			build: func(m *Markdown) *Markdown {
+				return m.CheckBoxTree(ListItem{
+					Text:    "a",
+					Checked: true,
+					Items:   []ListItem{{Text: "b"}, {Text: "c", Checked: true}},
+				})
+			},
+			want: []string{"- [x] a", "  - [ ] b", "  - [x] c"},
+		},
+		"multi-line text continues under the item": {
+			build: func(m *Markdown) *Markdown {
+				return m.BulletListTree(Item("first\nsecond"))
+			},
+			want: []string{"- first", "  second"},
+		},
+		"a quote in the body is followed by a blank line before the children": {
+			build: func(m *Markdown) *Markdown {
+				return m.BulletListTree(ListItem{
+					Text:  "a",
+					Body:  NewMarkdown(nil).Blockquote("quoted"),
+					Items: []ListItem{Item("b")},
+				})
+			},
+			want: []string{"- a", "  > quoted", "", "  - b"},
+		},
+		"a table in the body does not leave a blank line behind": {
+			build: func(m *Markdown) *Markdown {
+				return m.BulletListTree(
+					ListItem{
+						Text: "a",
+						Body: NewMarkdown(nil).Table(TableSet{Header: []string{"h"}, Rows: [][]string{{"v"}}}),
+					},
+					Item("b"),
+				)
+			},
+			want: []string{"- a", "  | h |", "  |---------|", "  | v |", "- b"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := strings.Split(tt.build(NewMarkdown(nil)).String(), lf())
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListTreeIsOneBlock(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).
+		BulletListTree(Item("a", Item("b"))).
+		PlainText("after")
+
+	if len(m.body) != 2 {
+		t.Fatalf("body has %d entries, want 2: %q", len(m.body), m.body)
+	}
+	// The paragraph would otherwise continue the last item lazily.
+	want := "- a" + lf() + "  - b" + lf() + lf() + "after"
+	if got := m.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestListTreeWithNoItems(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).BulletListTree().OrderedListTree().CheckBoxTree()
+	if len(m.body) != 0 {
+		t.Errorf("body = %q, want nothing", m.body)
+	}
+}
+
+func TestListTreeRecordsTheBodyError(t *testing.T) {
+	t.Parallel()
+
+	body := NewMarkdown(nil).Table(TableSet{Header: []string{"a", "b"}, Rows: [][]string{{"one"}}})
+	m := NewMarkdown(nil).BulletListTree(ListItem{Text: "a", Body: body})
+
+	if !errors.Is(m.Error(), ErrMismatchColumn) {
+		t.Errorf("Error() = %v, want an error wrapping ErrMismatchColumn", m.Error())
+	}
+}
+
+func TestListTreeSharesFootnotesOfTheBodies(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil)
+	m.PlainText("a" + m.Footnote("first"))
+
+	body := NewMarkdown(nil)
+	body.PlainText("see" + body.Footnote("second") + " " + body.ReferenceLink("Go", "https://go.dev"))
+	nested := NewMarkdown(nil)
+	nested.PlainText("also" + nested.Footnote("first"))
+	m.BulletListTree(ListItem{Text: "item", Body: body, Items: []ListItem{{Text: "child", Body: nested}}})
+
+	want := "a[^1]\n- item\n  see[^2] [Go][1]\n  - child\n    also[^1]\n\n" +
+		"[^1]: first\n[^2]: second\n\n[1]: https://go.dev"
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+	// The body the caller built keeps its own numbers.
+	if diff := cmp.Diff(normalizeLineFeeds("see[^1] [Go][1]\n\n[^1]: second\n\n[1]: https://go.dev"), body.String()); diff != "" {
+		t.Errorf("the body changed (-want +got):\n%s", diff)
+	}
+}
+
+func TestListTreeChecksCrossReferencesOfTheBodies(t *testing.T) {
+	t.Parallel()
+
+	body := NewMarkdown(nil)
+	body.PlainText(body.CrossReference("there", "nope"))
+	err := NewMarkdown(&strings.Builder{}).BulletListTree(Item("a", Item("b")), ListItem{Text: "c", Body: body}).Build()
+	if !errors.Is(err, ErrUndefinedCrossReference) || !strings.Contains(err.Error(), "#nope") {
+		t.Errorf("Build() = %v, want ErrUndefinedCrossReference naming #nope", err)
+	}
+
+	body = NewMarkdown(nil)
+	body.PlainText(body.CrossReference("install", "install"))
+	err = NewMarkdown(&strings.Builder{}).
+		H2WithID("Install", "install").
+		AddBlocks(&List{Items: []ListItem{{Text: "a", Items: []ListItem{{Text: "b", Body: body}}}}}).
+		Build()
+	if err != nil {
+		t.Errorf("Build() = %v, want nil", err)
+	}
+}
@@ -4,14 +4,26 @@
 // in the order they should appear, and finish with [Markdown.Build]. The output
 // follows GitHub Flavored Markdown.
 //
-// Nested structures, such as a list inside a list item, are out of scope. They
-// would turn the chain into a tree.
+// The chain itself is flat. A list nested inside a list item is described as a
+// tree of [ListItem] values instead, and handed to the chain in one call, such
+// as [Markdown.BulletListTree].
+//
+// Every call appends a [Block], such as a [Heading] or a [Table], rather than
+// text. [Markdown.Walk] and [Markdown.Transform] visit them, so a document can
+// be post-processed, its links rewritten or a section dropped, before it is
+// built.
 //
 // The builder records errors instead of returning them from every call. Nothing
 // panics on bad input, and a rejected call does not stop the document: the
 // chain runs to the end, and [Markdown.Error] and [Markdown.Build] both report
 // the first error it recorded.
 //
+// [Parse] starts from an existing document instead of an empty one. Its blocks
+// can be read back and replaced, and new ones appended with the same chain.
+//
+// [Markdown.HTML] and [Markdown.BuildHTML] render the same document as HTML,
+// for publishing it somewhere other than a repository.
+//
 // [Markdown.String] returns the document without needing a writer. That is how
 // the mermaid subpackages hand a diagram to [Markdown.CodeBlocks].
 //
@@ -25,6 +37,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/nao1215/markdown/inline"
 	"github.com/nao1215/markdown/internal"
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/renderer"
@@ -164,24 +177,64 @@ type TableOfContentsOptions struct {
 type headerInfo struct {
 	level TableOfContentsDepth
 	text  string
+	// id is the explicit ID of the heading, or "" for one derived from text.
+	id string
+	// block is the index of the block the header was written to.
+	block int
 }
 
 // Markdown is markdown text.
 type Markdown struct {
 	// body is markdown body.
-	body []string
+	body []Block
 	// dest is output destination for markdown body.
 	dest io.Writer
 	// err manages errors that occur in all parts of the markdown building.
 	err error
-	// headers stores header information for table of contents generation.
-	headers []headerInfo
 	// tocOptions stores the table of contents generation options.
 	tocOptions *TableOfContentsOptions
 	// tocInserted indicates whether a table of contents placeholder has been generated.
 	tocInserted bool
 	// blockSpacing separates every block with a blank line.
 	blockSpacing bool
+	// frontMatter is the front matter written above the body, fences included.
+	frontMatter string
+	// dialect is the markdown flavor the document is written in.
+	dialect Dialect
+	// stream is set by WithStreaming, which writes blocks as they are added
+	// instead of keeping them in body.
+	stream *stream
+	// headingIDStyle is the way explicit heading IDs are written.
+	headingIDStyle HeadingIDStyle
+	// crossReferences holds the IDs CrossReference linked to, each set once
+	// its error has been recorded.
+	crossReferences map[string]bool
+	// crossReferenceOrder is the keys of crossReferences in the order they were
+	// first linked to, which is the order their errors are recorded in.
+	crossReferenceOrder []string
+	// footnotes holds the notes Footnote registered; the note at index i is
+	// footnote i+1.
+	footnotes []string
+	// footnoteIDs maps a note to its number.
+	footnoteIDs map[string]int
+	// references holds the link targets ReferenceLink registered; the target
+	// at index i has the label i+1.
+	references []reference
+	// referenceIDs maps a URL to its label.
+	referenceIDs map[string]int
+	// escaping makes the builder methods write their text literally. See
+	// WithEscaping.
+	escaping bool
+	// lineWidth is the column prose is wrapped at, or 0 for none. See
+	// WithLineWidth.
+	lineWidth int
+	// sectionLevel is the level of the heading of the section Section is
+	// running the body of, or 0 outside of one.
+	sectionLevel int
+	// nested marks the builder AlertBlocks and DetailsBlocks hand to their
+	// callback. The dialect is checked for its blocks when the block holding
+	// them is added, so add leaves them alone.
+	nested bool
 }
 
 // Option configures a Markdown at construction time.
@@ -203,9 +256,8 @@ func WithBlockSpacing() Option {
 // NewMarkdown returns new Markdown.
 func NewMarkdown(w io.Writer, opts ...Option) *Markdown {
 	m := &Markdown{
-		body:    []string{},
-		dest:    w,
-		headers: []headerInfo{},
+		body: []Block{},
+		dest: w,
 	}
 	for _, opt := range opts {
 		opt(m)
@@ -218,6 +270,25 @@ func NewMarkdown(w io.Writer, opts ...Option) *Markdown {
 // It returns the document built so far whether or not an error was recorded,
 // and it does not need a writer, so it works on a builder constructed with nil.
 func (m *Markdown) String() string {
+	body := m.bodyString()
+	if m.frontMatter == "" {
+		return body
+	}
+
+	// The front matter is not a block, so the join never sees it: a table of
+	// contents or a quote at the top of the body is not its business.
+	switch {
+	case body == "":
+		return m.frontMatter
+	case m.blockSpacing && !strings.HasPrefix(body, internal.LineFeed()):
+		return m.frontMatter + internal.LineFeed() + internal.LineFeed() + body
+	default:
+		return m.frontMatter + internal.LineFeed() + body
+	}
+}
+
+// bodyString returns the document without its front matter.
+func (m *Markdown) bodyString() string {
 	body := m.body
 
 	if m.tocInserted && m.tocOptions != nil {
@@ -226,7 +297,40 @@ func (m *Markdown) String() string {
 		}
 	}
 
-	return joinBlocks(body, m.blockSpacing)
+	return appendDefinitions(joinBlocks(m.blockRenderer().render(body), m.blockSpacing), m.definitions())
+}
+
+// blockRenderer returns the renderer that writes the blocks of this document.
+func (m *Markdown) blockRenderer() *blockRenderer {
+	return &blockRenderer{
+		blockSpacing:   m.blockSpacing,
+		dialect:        m.dialect,
+		headingIDStyle: m.headingIDStyle,
+		lineWidth:      m.lineWidth,
+	}
+}
+
+// add appends a block to the body, or writes it in streaming mode, recording
+// an error for every block in it the dialect cannot write.
+func (m *Markdown) add(b Block) *Markdown {
+	if !m.nested {
+		walkBlocks([]Block{b}, func(b Block) bool {
+			m.addError(m.dialect.unsupported(b))
+			return true
+		})
+	}
+	if m.stream != nil {
+		walkBlocks([]Block{b}, func(b Block) bool {
+			if h, ok := b.(*Heading); ok && h.ID != "" {
+				m.stream.headingIDs[h.ID]++
+			}
+			return true
+		})
+		m.streamBlock(b)
+		return m
+	}
+	m.body = append(m.body, b)
+	return m
 }
 
 // normalizeLineFeeds rewrites every line ending in text to the platform one.
@@ -256,7 +360,7 @@ func normalizeLineFeedsTo(text, lineFeed string) string {
 // produce documents that render wrongly on GitHub while looking fine in the
 // source, which is why callers of this package litter their code with manual
 // spacer calls. Everything else is joined exactly as before.
-func joinBlocks(body []string, always bool) string {
+func joinBlocks(body []renderedBlock, always bool) string {
 	lf := internal.LineFeed()
 
 	var buf strings.Builder
@@ -267,32 +371,31 @@ func joinBlocks(body []string, always bool) string {
 				buf.WriteString(lf)
 			}
 		}
-		buf.WriteString(block)
+		buf.WriteString(block.text)
 	}
 	return buf.String()
 }
 
 // needsBlankLine reports whether a blank line has to separate two blocks.
-func needsBlankLine(prev, next string, always bool) bool {
+func needsBlankLine(prev, next renderedBlock, always bool) bool {
 	// A whitespace-only entry, which is what LF() writes, already separates the
 	// blocks; adding another blank line would just pile them up.
-	if strings.TrimSpace(prev) == "" || strings.TrimSpace(next) == "" {
+	if prev.kind == kindBlank || next.kind == kindBlank {
 		return false
 	}
 	// Table and Details already end with a line feed, so the join produces the
 	// blank line on its own.
-	if strings.HasSuffix(prev, internal.LineFeed()) {
+	if strings.HasSuffix(prev.text, internal.LineFeed()) {
 		return false
 	}
 	// An HTML comment renders as nothing and cannot absorb the block before it.
 	// The table of contents markers are comments, so this keeps the generated
 	// entries tucked against them.
-	if strings.HasPrefix(next, "<!--") || strings.HasPrefix(prev, "<!--") {
+	if prev.kind == kindComment || next.kind == kindComment {
 		return false
 	}
 
-	prevList, nextList := listKind(prev), listKind(next)
-	sameList := prevList != "" && prevList == nextList
+	sameList := prev.kind.isList() && prev.kind == next.kind
 
 	if always {
 		// Consecutive items of one list still belong together; everything else
@@ -301,10 +404,10 @@ func needsBlankLine(prev, next string, always bool) bool {
 	}
 
 	switch {
-	case isQuoteBlock(prev):
+	case prev.kind == kindQuote:
 		// Anything on the line after a quote is read as part of it.
 		return true
-	case prevList != "" && !sameList:
+	case prev.kind.isList() && !sameList:
 		// A different kind of list starts a new list, so it needs the blank line
 		// as much as a paragraph or a table does.
 		return true
@@ -313,25 +416,21 @@ func needsBlankLine(prev, next string, always bool) bool {
 	}
 }
 
-// isQuoteBlock reports whether the block is a blockquote or an alert.
-func isQuoteBlock(block string) bool {
-	return strings.HasPrefix(block, ">")
-}
-
-// listKind identifies which kind of list item a block is, or returns an empty
-// string when it is not a list item at all.
+// listKind identifies which kind of list item text starts with, or returns
+// kindText when it is not a list item at all.
 //
-// The kind matters because list items are appended one per entry: consecutive
-// items of the same list must stay tight, while a bullet list followed by an
-// ordered list is two lists and needs the blank line between them.
-func listKind(block string) string {
-	trimmed := strings.TrimLeft(block, " ")
+// A List knows its kind. This is for text that may hold a list nobody
+// declared, written with PlainText or read by Parse: consecutive items of the
+// same list must stay tight, while a bullet list followed by an ordered list is
+// two lists and needs the blank line between them.
+func listKind(text string) blockKind {
+	trimmed := strings.TrimLeft(text, " ")
 
 	switch {
 	case strings.HasPrefix(trimmed, "- [ ] "), strings.HasPrefix(trimmed, "- [x] "):
-		return "checkbox"
+		return kindCheckBoxList
 	case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "), strings.HasPrefix(trimmed, "+ "):
-		return "bullet"
+		return kindBulletList
 	}
 
 	digits := 0
@@ -339,23 +438,23 @@ func listKind(block string) string {
 		digits++
 	}
 	if digits > 0 && strings.HasPrefix(trimmed[digits:], ". ") {
-		return "ordered"
+		return kindOrderedList
 	}
-	return ""
+	return kindText
 }
 
 // insertTableOfContents places the generated entries between the two marker
-// entries in the body.
+// blocks in the body.
 //
-// The markers are separate body entries, so this works on the slice rather than
-// on the joined text. Matching the joined text meant matching the exact pair
+// The markers are separate blocks, so this works on the slice rather than on
+// the joined text. Matching the joined text meant matching the exact pair
 // "<!-- BEGIN_TOC -->\n<!-- END_TOC -->", which silently stopped matching as
 // soon as anything was placed between the markers: the replacement quietly did
 // nothing and the document shipped with an empty table of contents.
-func insertTableOfContents(body, toc []string) []string {
+func insertTableOfContents(body, toc []Block) []Block {
 	begin := -1
-	for i, line := range body {
-		if line == TableOfContentsMarkerBegin {
+	for i, b := range body {
+		if isRaw(b, TableOfContentsMarkerBegin) {
 			begin = i
 			break
 		}
@@ -366,7 +465,7 @@ func insertTableOfContents(body, toc []string) []string {
 
 	end := -1
 	for i := begin + 1; i < len(body); i++ {
-		if body[i] == TableOfContentsMarkerEnd {
+		if isRaw(body[i], TableOfContentsMarkerEnd) {
 			end = i
 			break
 		}
@@ -375,13 +474,27 @@ func insertTableOfContents(body, toc []string) []string {
 		return body
 	}
 
-	out := make([]string, 0, len(body)+len(toc))
+	out := make([]Block, 0, len(body)+len(toc))
 	out = append(out, body[:begin+1]...)
 	out = append(out, toc...)
 	out = append(out, body[end:]...)
 	return out
 }
 
+// isRaw reports whether b is raw text reading exactly text.
+func isRaw(b Block, text string) bool {
+	r, ok := b.(*Raw)
+	return ok && r.Text == text
+}
+
+// addError records err alongside whatever the chain recorded before it. The
+// first error stays first, so it is still the one a caller reads.
+func (m *Markdown) addError(err error) {
+	if err != nil {
+		m.err = errors.Join(m.err, err)
+	}
+}
+
 // Error returns the error the chain recorded, or nil.
 //
 // It is the same error [Markdown.Build] returns, for callers who would rather
@@ -392,8 +505,7 @@ func (m *Markdown) Error() error {
 
 // PlainText set plain text
 func (m *Markdown) PlainText(text string) *Markdown {
-	m.body = append(m.body, text)
-	return m
+	return m.add(&Paragraph{Text: m.literal(text, EscapeParagraph)})
 }
 
 // PlainTextf set plain text with format
@@ -401,6 +513,14 @@ func (m *Markdown) PlainTextf(format string, args ...interface{}) *Markdown {
 	return m.PlainText(fmt.Sprintf(format, args...))
 }
 
+// Paragraph writes a paragraph made of inline nodes, each escaped for where it
+// is written. See the inline package.
+//
+// The nodes escape their own text, so WithEscaping leaves the paragraph alone.
+func (m *Markdown) Paragraph(nodes ...inline.Node) *Markdown {
+	return m.add(&Paragraph{Text: inline.Render(nodes...)})
+}
+
 // Build writes markdown text to output destination.
 //
 // It returns the error the chain recorded, or nil. A nil destination and a
@@ -410,14 +530,20 @@ func (m *Markdown) PlainTextf(format string, args ...interface{}) *Markdown {
 // The document is written with a trailing line ending, so appending a second
 // document to the same writer starts it on its own line.
 //
-// Build may be called more than once; each call writes the document again.
+// Build may be called more than once; each call writes the document again. A
+// streaming document is the exception: its blocks are written already, and
+// Build only ends it. See WithStreaming.
 func (m *Markdown) Build() error {
+	m.resolveCrossReferences()
 	if m.dest == nil {
 		if m.err != nil {
 			return fmt.Errorf("failed to write markdown text: destination writer is nil: %s", m.err.Error()) //nolint:wrapcheck
 		}
 		return errors.New("failed to write markdown text: destination writer is nil")
 	}
+	if m.stream != nil {
+		return m.finishStream()
+	}
 
 	// A document written to a file has to end with a newline: markdownlint MD047
 	// requires it, and appending a second document to the same writer would
@@ -439,9 +565,7 @@ func (m *Markdown) Build() error {
 // H1 is markdown header.
 // If you set text "Hello", it will be converted to "# Hello".
 func (m *Markdown) H1(text string) *Markdown {
-	m.headers = append(m.headers, headerInfo{level: TableOfContentsDepthH1, text: text})
-	m.body = append(m.body, fmt.Sprintf("# %s", text))
-	return m
+	return m.add(&Heading{Level: 1, Text: m.literal(text, EscapeHeading)})
 }
 
 // H1f is markdown header with format.
@@ -453,9 +577,7 @@ func (m *Markdown) H1f(format string, args ...interface{}) *Markdown {
 // H2 is markdown header.
 // If you set text "Hello", it will be converted to "## Hello".
 func (m *Markdown) H2(text string) *Markdown {
-	m.headers = append(m.headers, headerInfo{level: TableOfContentsDepthH2, text: text})
-	m.body = append(m.body, fmt.Sprintf("## %s", text))
-	return m
+	return m.add(&Heading{Level: 2, Text: m.literal(text, EscapeHeading)})
 }
 
 // H2f is markdown header with format.
@@ -467,9 +589,7 @@ func (m *Markdown) H2f(format string, args ...interface{}) *Markdown {
 // H3 is markdown header.
 // If you set text "Hello", it will be converted to "### Hello".
 func (m *Markdown) H3(text string) *Markdown {
-	m.headers = append(m.headers, headerInfo{level: TableOfContentsDepthH3, text: text})
-	m.body = append(m.body, fmt.Sprintf("### %s", text))
-	return m
+	return m.add(&Heading{Level: 3, Text: m.literal(text, EscapeHeading)})
 }
 
 // H3f is markdown header with format.
@@ -481,9 +601,7 @@ func (m *Markdown) H3f(format string, args ...interface{}) *Markdown {
 // H4 is markdown header.
 // If you set text "Hello", it will be converted to "#### Hello".
 func (m *Markdown) H4(text string) *Markdown {
-	m.headers = append(m.headers, headerInfo{level: TableOfContentsDepthH4, text: text})
-	m.body = append(m.body, fmt.Sprintf("#### %s", text))
-	return m
+	return m.add(&Heading{Level: 4, Text: m.literal(text, EscapeHeading)})
 }
 
 // H4f is markdown header with format.
@@ -495,9 +613,7 @@ func (m *Markdown) H4f(format string, args ...interface{}) *Markdown {
 // H5 is markdown header.
 // If you set text "Hello", it will be converted to "##### Hello".
 func (m *Markdown) H5(text string) *Markdown {
-	m.headers = append(m.headers, headerInfo{level: TableOfContentsDepthH5, text: text})
-	m.body = append(m.body, fmt.Sprintf("##### %s", text))
-	return m
+	return m.add(&Heading{Level: 5, Text: m.literal(text, EscapeHeading)})
 }
 
 // H5f is markdown header with format.
@@ -509,9 +625,7 @@ func (m *Markdown) H5f(format string, args ...interface{}) *Markdown {
 // H6 is markdown header.
 // If you set text "Hello", it will be converted to "###### Hello".
 func (m *Markdown) H6(text string) *Markdown {
-	m.headers = append(m.headers, headerInfo{level: TableOfContentsDepthH6, text: text})
-	m.body = append(m.body, fmt.Sprintf("###### %s", text))
-	return m
+	return m.add(&Heading{Level: 6, Text: m.literal(text, EscapeHeading)})
 }
 
 // H6f is markdown header with format.
@@ -520,6 +634,28 @@ func (m *Markdown) H6f(format string, args ...interface{}) *Markdown {
 	return m.H6(fmt.Sprintf(format, args...))
 }
 
+// headings returns the headings of the document, in order, with the index of
+// the block each one is in. The table of contents is built from them.
+func (m *Markdown) headings() []headerInfo {
+	headers := []headerInfo{}
+	for i, b := range m.body {
+		switch b := b.(type) {
+		case *Heading:
+			headers = append(headers, headerInfo{level: TableOfContentsDepth(b.Level), text: b.Text, id: b.ID, block: i})
+		case *Raw:
+			// Raw text read by Parse can hold headings the source wrote tight
+			// against the block before them. They count until the text changes.
+			if _, ok := b.verbatim(b.Text); ok {
+				for _, h := range b.headings {
+					h.block = i
+					headers = append(headers, h)
+				}
+			}
+		}
+	}
+	return headers
+}
+
 // TableOfContents generates a table of contents placeholder that will be replaced when Build() is called.
 // The table of contents will include all headers from H1 to the specified maxDepth.
 // Only one table of contents can be generated per document.
@@ -552,9 +688,14 @@ func (m *Markdown) TableOfContents(maxDepth TableOfContentsDepth) *Markdown {
 //	   H5("Deep Detail").  // This H5 will not appear in table of contents
 //	   Build()
 func (m *Markdown) TableOfContentsWithRange(minDepth, maxDepth TableOfContentsDepth) *Markdown {
+	if m.stream != nil {
+		m.addError(fmt.Errorf("%w: a table of contents, which lists headings not added yet", ErrStreamingUnsupported))
+		return m
+	}
+
 	if m.tocInserted {
 		if m.err == nil {
-			m.err = errors.New("table of contents has already been generated")
+			m.err = errTableOfContentsGenerated
 		}
 		return m
 	}
@@ -580,6 +721,10 @@ func (m *Markdown) TableOfContentsWithRange(minDepth, maxDepth TableOfContentsDe
 		return m
 	}
 
+	if m.dialect == DialectCommonMark {
+		m.addError(fmt.Errorf("%w: a table of contents in %s, which gives headings no anchors", ErrUnsupportedByDialect, m.dialect))
+	}
+
 	m.tocOptions = &TableOfContentsOptions{
 		MinDepth: minDepth,
 		MaxDepth: maxDepth,
@@ -587,26 +732,29 @@ func (m *Markdown) TableOfContentsWithRange(minDepth, maxDepth TableOfContentsDe
 	m.tocInserted = true
 
 	// Insert table of contents placeholder markers
-	m.body = append(m.body, TableOfContentsMarkerBegin)
-	m.body = append(m.body, TableOfContentsMarkerEnd)
-	m.body = append(m.body, "")
-
-	return m
+	return m.add(&Raw{Text: TableOfContentsMarkerBegin}).
+		add(&Raw{Text: TableOfContentsMarkerEnd}).
+		add(&Raw{Text: ""})
 }
 
 // generateTableOfContents generates the table of contents based on collected headers and options.
-func (m *Markdown) generateTableOfContents() []string {
-	if m.tocOptions == nil || len(m.headers) == 0 {
-		return []string{}
+func (m *Markdown) generateTableOfContents() []Block {
+	if directive := m.dialect.tableOfContentsDirective(); directive != "" {
+		return []Block{&Raw{Text: directive}}
+	}
+
+	headers := m.headings()
+	if m.tocOptions == nil || len(headers) == 0 {
+		return []Block{}
 	}
 
-	tocLines := make([]string, 0, len(m.headers))
+	tocLines := make([]Block, 0, len(headers))
 	// Indent relative to the shallowest heading that actually appears, not to
 	// the requested MinDepth. TableOfContents pins MinDepth at H1, so a document
 	// that starts at H2 would otherwise have every entry indented two spaces,
 	// producing a list nested under nothing.
 	minIndent := int(m.tocOptions.MaxDepth)
-	for _, header := range m.headers {
+	for _, header := range headers {
 		if header.level < m.tocOptions.MinDepth || header.level > m.tocOptions.MaxDepth {
 			continue
 		}
@@ -614,9 +762,9 @@ func (m *Markdown) generateTableOfContents() []string {
 			minIndent = int(header.level)
 		}
 	}
-	anchorCounts := make(map[string]int, len(m.headers))
+	anchorCounts := make(map[string]int, len(headers))
 
-	for _, header := range m.headers {
+	for _, header := range headers {
 		// Skip headers outside the specified range
 		if header.level < m.tocOptions.MinDepth || header.level > m.tocOptions.MaxDepth {
 			continue
@@ -625,16 +773,20 @@ func (m *Markdown) generateTableOfContents() []string {
 		// Calculate relative indentation
 		indent := strings.Repeat("  ", int(header.level)-minIndent)
 
-		// Generate anchor following GitHub's convention
-		baseAnchor := generateGitHubAnchor(header.text)
-		count := anchorCounts[baseAnchor]
-		anchor := baseAnchor
-		if count > 0 {
-			anchor = fmt.Sprintf("%s-%d", baseAnchor, count)
+		// Generate the anchor the platform gives the heading. An explicit ID
+		// written as an attribute replaces it; one written as an anchor sits
+		// beside it, so the heading still takes its turn in the count.
+		anchor := header.id
+		if anchor == "" || m.headingIDStyle == HeadingIDAnchor {
+			baseAnchor := m.dialect.anchor(header.text)
+			count := anchorCounts[baseAnchor]
+			anchorCounts[baseAnchor] = count + 1
+			if anchor == "" {
+				anchor = m.dialect.uniqueAnchor(baseAnchor, count)
+			}
 		}
-		anchorCounts[baseAnchor] = count + 1
 
-		tocLines = append(tocLines, fmt.Sprintf("%s- [%s](#%s)", indent, header.text, anchor))
+		tocLines = append(tocLines, &Raw{Text: fmt.Sprintf("%s- [%s](#%s)", indent, header.text, anchor)})
 	}
 
 	return tocLines
@@ -665,12 +817,19 @@ func generateGitHubAnchor(text string) string {
 // <details> renders as literal text, and the block that follows </details>
 // disappears into the same HTML block.
 func (m *Markdown) Details(summary, text string) *Markdown {
-	lf := internal.LineFeed()
-	m.body = append(
-		m.body,
-		fmt.Sprintf("<details>%s<summary>%s</summary>%s%s%s%s%s</details>%s",
-			lf, summary, lf, lf, text, lf, lf, lf))
-	return m
+	return m.add(&Details{Summary: m.literal(summary, escapeSummary), Text: m.literal(text, EscapeParagraph)})
+}
+
+// DetailsBlocks writes a collapsible section that holds the blocks body adds to
+// the builder it is given, such as a table, a code block or a list, which the
+// text of Details cannot hold. An open section is shown expanded until the
+// reader collapses it.
+//
+// The builder body is given writes the way m does and shares its footnotes,
+// reference links and cross references, and an error it records is recorded by
+// m.
+func (m *Markdown) DetailsBlocks(summary string, open bool, body func(m *Markdown)) *Markdown {
+	return m.add(&Details{Summary: m.literal(summary, escapeSummary), Body: m.blockBody(body), Open: open})
 }
 
 // Detailsf is markdown details with format.
@@ -681,19 +840,13 @@ func (m *Markdown) Detailsf(summary, format string, args ...interface{}) *Markdo
 // BulletList is markdown bullet list.
 // If you set text "Hello", it will be converted to "- Hello".
 func (m *Markdown) BulletList(text ...string) *Markdown {
-	for _, v := range text {
-		m.body = append(m.body, fmt.Sprintf("- %s", v))
-	}
-	return m
+	return m.flatList(ListStyleBullet, textItems(text))
 }
 
 // OrderedList is markdown number list.
 // If you set text "Hello", it will be converted to "1. Hello".
 func (m *Markdown) OrderedList(text ...string) *Markdown {
-	for i, v := range text {
-		m.body = append(m.body, fmt.Sprintf("%d. %s", i+1, v))
-	}
-	return m
+	return m.flatList(ListStyleOrdered, textItems(text))
 }
 
 // CheckBoxSet is markdown checkbox list.
@@ -706,31 +859,20 @@ type CheckBoxSet struct {
 
 // CheckBox is markdown CheckBox.
 func (m *Markdown) CheckBox(set []CheckBoxSet) *Markdown {
+	items := make([]ListItem, 0, len(set))
 	for _, v := range set {
-		if v.Checked {
-			m.body = append(m.body, fmt.Sprintf("- [x] %s", v.Text))
-		} else {
-			m.body = append(m.body, fmt.Sprintf("- [ ] %s", v.Text))
-		}
+		items = append(items, ListItem{Text: v.Text, Checked: v.Checked})
 	}
-	return m
+	return m.flatList(ListStyleCheckBox, items)
 }
 
 // Blockquote is markdown blockquote.
 // If you set text "Hello", it will be converted to "> Hello".
 func (m *Markdown) Blockquote(text string) *Markdown {
-	// Split on "\n" after dropping "\r": splitting on internal.LineFeed() meant
-	// a plain Go literal containing "\n" was never split on Windows, and the
-	// quote silently covered only its first line.
-	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
-	for i, line := range lines {
-		lines[i] = fmt.Sprintf("> %s", line)
-	}
-	// One entry per quote rather than one per line: the whole quote is a single
+	// One block per quote rather than one per line: the whole quote is a single
 	// block, and the join has to be able to put a blank line after it without
 	// cutting it in half.
-	m.body = append(m.body, strings.Join(lines, internal.LineFeed()))
-	return m
+	return m.add(&Blockquote{Text: m.literal(text, EscapeParagraph)})
 }
 
 // CodeBlocks is code blocks.
@@ -739,22 +881,18 @@ func (m *Markdown) Blockquote(text string) *Markdown {
 // Hello
 // ```".
 //
-// The block is fenced with three backticks, so content holding a line that
-// starts with three backticks closes it early and everything after that line
-// renders as prose. Markdown's answer is a longer fence, which this method does
-// not write: a caller whose content can hold a fence has to build the block
-// itself with PlainText.
+// The fence is three backticks unless the content holds a run of backticks
+// that long, which would close the block early; then it is one backtick longer
+// than the longest run, so any content is safe. CustomCodeBlock adds a title,
+// highlighted lines and line numbers.
 func (m *Markdown) CodeBlocks(lang SyntaxHighlight, text string) *Markdown {
-	m.body = append(m.body,
-		fmt.Sprintf("```%s%s%s%s```", lang, internal.LineFeed(), text, internal.LineFeed()))
-	return m
+	return m.add(&CodeBlock{Lang: lang, Code: text})
 }
 
 // HorizontalRule is markdown horizontal rule.
 // It will be converted to "---".
 func (m *Markdown) HorizontalRule() *Markdown {
-	m.body = append(m.body, "---")
-	return m
+	return m.add(&HorizontalRule{})
 }
 
 // TableAlignment represents column alignment in markdown tables.
@@ -890,6 +1028,11 @@ func (m *Markdown) Table(t TableSet) *Markdown {
 		return m
 	}
 
+	return m.add(&Table{Set: m.literalTable(t)})
+}
+
+// renderTable writes the table the way Table does.
+func renderTable(t TableSet) string {
 	if t.EscapeCells {
 		t = t.escaped()
 	}
@@ -936,9 +1079,7 @@ func (m *Markdown) Table(t TableSet) *Markdown {
 		}
 		buf.WriteString(internal.LineFeed())
 	}
-
-	m.body = append(m.body, buf.String())
-	return m
+	return buf.String()
 }
 
 // TableOptions is markdown table options.
@@ -960,6 +1101,15 @@ func (m *Markdown) CustomTable(t TableSet, options TableOptions) *Markdown {
 		}
 	}
 
+	if _, err := renderCustomTable(t, options); err != nil {
+		m.addError(err)
+		return m
+	}
+	return m.add(&Table{Set: m.literalTable(t), Options: &options})
+}
+
+// renderCustomTable writes the table the way CustomTable does.
+func renderCustomTable(t TableSet, options TableOptions) (string, error) {
 	if t.EscapeCells {
 		t = t.escaped()
 	}
@@ -1020,13 +1170,11 @@ func (m *Markdown) CustomTable(t TableSet, options TableOptions) *Markdown {
 
 	table.Header(t.Header)
 	if err := table.Bulk(t.Rows); err != nil {
-		m.err = errors.Join(m.err, fmt.Errorf("failed to add rows to table: %w", err))
-		return m
+		return "", fmt.Errorf("failed to add rows to table: %w", err)
 	}
 	// This is so if the user wants to change the table settings they can
 	if err := table.Render(); err != nil {
-		m.err = errors.Join(m.err, fmt.Errorf("failed to render table: %w", err))
-		return m
+		return "", fmt.Errorf("failed to render table: %w", err)
 	}
 
 	// tablewriter always separates rows with "\n", so on Windows its output
@@ -1034,8 +1182,7 @@ func (m *Markdown) CustomTable(t TableSet, options TableOptions) *Markdown {
 	// delimiter row rewrite below would fail to find any rows to work on.
 	rendered := normalizeLineFeeds(buf.String())
 
-	m.body = append(m.body, applyAlignmentToDelimiterRow(rendered, t.Alignment))
-	return m
+	return applyAlignmentToDelimiterRow(rendered, t.Alignment), nil
 }
 
 // applyAlignmentToDelimiterRow rewrites the delimiter row of a rendered table so
@@ -1122,12 +1269,10 @@ func delimiterCell(width int, align TableAlignment) string {
 // also happens to separate blocks, which is how most callers use it. Use
 // BlankLine when a blank line is what you mean.
 func (m *Markdown) LF() *Markdown {
-	m.body = append(m.body, "  ")
-	return m
+	return m.add(&Raw{Text: "  "})
 }
 
 // BlankLine writes an empty line between two blocks.
 func (m *Markdown) BlankLine() *Markdown {
-	m.body = append(m.body, "")
-	return m
+	return m.add(&Raw{Text: ""})
 }
@@ -25,7 +25,7 @@ func TestPlainText(t *testing.T) {
 		m := NewMarkdown(os.Stdout)
 		m.PlainText("Hello")
 		want := []string{"Hello"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -126,7 +126,7 @@ func TestMarkdownDetailsf(t *testing.T) {
 		want := fmt.Sprintf("<details>%s<summary>Hello</summary>%s%sGood World%s%s</details>%s",
 			internal.LineFeed(), internal.LineFeed(), internal.LineFeed(),
 			internal.LineFeed(), internal.LineFeed(), internal.LineFeed())
-		got := m.body[0]
+		got := m.BlockText(0)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -142,8 +142,9 @@ func TestMarkdownBulletList(t *testing.T) {
 
 		m := NewMarkdown(os.Stdout)
 		m.BulletList("Hello", "World")
-		want := []string{"- Hello", "- World"}
-		got := m.body
+		// The whole list is one block.
+		want := []string{"- Hello" + lf() + "- World"}
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -159,8 +160,8 @@ func TestMarkdownNumberList(t *testing.T) {
 
 		m := NewMarkdown(os.Stdout)
 		m.OrderedList("Hello", "World")
-		want := []string{"1. Hello", "2. World"}
-		got := m.body
+		want := []string{"1. Hello" + lf() + "2. World"}
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -179,10 +180,9 @@ func TestMarkdownCheckBoxf(t *testing.T) {
 		}
 		m.CheckBox(set)
 		want := []string{
-			"- [x] Hello",
-			"- [ ] World",
+			"- [x] Hello" + lf() + "- [ ] World",
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -202,7 +202,7 @@ func TestMarkdownBlockquote(t *testing.T) {
 		want := []string{
 			"> Hello" + internal.LineFeed() + "> Good" + internal.LineFeed() + "> World",
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -217,7 +217,7 @@ func TestMarkdownCodeBlocks(t *testing.T) {
 		m := NewMarkdown(os.Stdout)
 		m.CodeBlocks(SyntaxHighlightGo, "Hello")
 		want := []string{fmt.Sprintf("```go%sHello%s```", internal.LineFeed(), internal.LineFeed())}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -234,7 +234,7 @@ func TestHorizontalRule(t *testing.T) {
 		m := NewMarkdown(os.Stdout)
 		m.HorizontalRule()
 		want := []string{"---"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -289,7 +289,7 @@ func TestMarkdownTable(t *testing.T) {
 			fmt.Sprintf("| Name | Age |%s|---------|---------|%s| David | 23 |%s",
 				internal.LineFeed(), internal.LineFeed(), internal.LineFeed()),
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -310,7 +310,7 @@ func TestMarkdownTable(t *testing.T) {
 			fmt.Sprintf("| Left Align | Normal |%s|:--------|---------|%s| Content1 | Content2 |%s",
 				internal.LineFeed(), internal.LineFeed(), internal.LineFeed()),
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -331,7 +331,7 @@ func TestMarkdownTable(t *testing.T) {
 			fmt.Sprintf("| Center Align | Normal |%s|:-------:|---------|%s| Content1 | Content2 |%s",
 				internal.LineFeed(), internal.LineFeed(), internal.LineFeed()),
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -352,7 +352,7 @@ func TestMarkdownTable(t *testing.T) {
 			fmt.Sprintf("| Right Align | Normal |%s|--------:|---------|%s| Content1 | Content2 |%s",
 				internal.LineFeed(), internal.LineFeed(), internal.LineFeed()),
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -373,7 +373,7 @@ func TestMarkdownTable(t *testing.T) {
 			fmt.Sprintf("| Left Align | Center Align | Right Align |%s|:--------|:-------:|--------:|%s| Content1 | Content2 | Content3 |%s| Content4 | Content5 | Content6 |%s",
 				internal.LineFeed(), internal.LineFeed(), internal.LineFeed(), internal.LineFeed()),
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -394,7 +394,7 @@ func TestMarkdownTable(t *testing.T) {
 			fmt.Sprintf("| Left | Default | Center |%s|:--------|:-------:|---------|%s| A | B | C |%s",
 				internal.LineFeed(), internal.LineFeed(), internal.LineFeed()),
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -411,7 +411,7 @@ func TestMarkdownTable(t *testing.T) {
 		}
 		m.Table(set)
 		want := []string{}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -453,7 +453,7 @@ func TestMarkdownLF(t *testing.T) {
 		m := NewMarkdown(os.Stdout)
 		m.LF()
 		want := []string{"  "}
-		got := m.body
+		got := blockTexts(m)
 
 		if !reflect.DeepEqual(want, got) {
 			t.Errorf("value is mismatch want: %v, got: %v", want, got)
@@ -524,7 +524,7 @@ func TestMarkdownCustomTable(t *testing.T) {
 			fmt.Sprintf("| Name  | Age |%s|-------|-----|%s| David | 23  |%s",
 				internal.LineFeed(), internal.LineFeed(), internal.LineFeed()),
 		}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1474,7 +1474,7 @@ func TestMarkdownAlerts(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.Notef("%s", "Hello")
 		want := []string{"> [!NOTE]  " + internal.LineFeed() + "> Hello"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1487,7 +1487,7 @@ func TestMarkdownAlerts(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.Warningf("%s", "Hello")
 		want := []string{"> [!WARNING]  " + internal.LineFeed() + "> Hello"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1500,7 +1500,7 @@ func TestMarkdownAlerts(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.Tipf("%s", "Hello")
 		want := []string{"> [!TIP]  " + internal.LineFeed() + "> Hello"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1513,7 +1513,7 @@ func TestMarkdownAlerts(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.Importantf("%s", "Hello")
 		want := []string{"> [!IMPORTANT]  " + internal.LineFeed() + "> Hello"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1526,7 +1526,7 @@ func TestMarkdownAlerts(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.Cautionf("%s", "Hello")
 		want := []string{"> [!CAUTION]  " + internal.LineFeed() + "> Hello"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1542,7 +1542,7 @@ func TestMarkdown_RedBadgef(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.RedBadgef("%s", "Hello")
 		want := []string{"![Badge](https://img.shields.io/badge/Hello-red)"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1555,7 +1555,7 @@ func TestMarkdown_RedBadgef(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.YellowBadgef("%s", "Hello")
 		want := []string{"![Badge](https://img.shields.io/badge/Hello-yellow)"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1568,7 +1568,7 @@ func TestMarkdown_RedBadgef(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.GreenBadgef("%s", "Hello")
 		want := []string{"![Badge](https://img.shields.io/badge/Hello-green)"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1581,7 +1581,7 @@ func TestMarkdown_RedBadgef(t *testing.T) {
 		m := NewMarkdown(io.Discard)
 		m.BlueBadgef("%s", "Hello")
 		want := []string{"![Badge](https://img.shields.io/badge/Hello-blue)"}
-		got := m.body
+		got := blockTexts(m)
 
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("value is mismatch (-want +got):\n%s", diff)
@@ -1857,6 +1857,71 @@ func TestDetailsSurroundsBodyWithBlankLines(t *testing.T) {
 	}
 }
 
+func TestDetailsBlocks(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts    []Option
+		summary string
+		open    bool
+		body    func(*Markdown)
+		want    string
+	}{
+		"blocks": {
+			summary: "Logs",
+			body: func(m *Markdown) {
+				m.CodeBlocks(SyntaxHighlightText, "ok").BulletList("a")
+			},
+			want: "<details>\n<summary>Logs</summary>\n\n```text\nok\n```\n- a\n\n</details>\n",
+		},
+		"open": {
+			summary: "Shown",
+			open:    true,
+			body:    func(m *Markdown) { m.PlainText("text") },
+			want:    "<details open>\n<summary>Shown</summary>\n\ntext\n\n</details>\n",
+		},
+		"nested": {
+			summary: "Outer",
+			body: func(m *Markdown) {
+				m.DetailsBlocks("Inner", false, func(m *Markdown) {
+					m.Table(TableSet{Header: []string{"a"}, Rows: [][]string{{"1"}}})
+				}).PlainText("after")
+			},
+			want: "<details>\n<summary>Outer</summary>\n\n" +
+				"<details>\n<summary>Inner</summary>\n\n| a |\n|---------|\n| 1 |\n\n</details>\n\nafter" +
+				"\n\n</details>\n",
+		},
+		"escaped summary": {
+			opts:    []Option{WithEscaping()},
+			summary: "<b>a</b>",
+			want:    "<details>\n<summary>&lt;b&gt;a&lt;/b&gt;</summary>\n\n\n\n</details>\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, tt.opts...).DetailsBlocks(tt.summary, tt.open, tt.body)
+			if err := m.Error(); err != nil {
+				t.Fatalf("Error() = %v", err)
+			}
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), m.String()); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("the body's error", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).DetailsBlocks("s", false, func(m *Markdown) { m.H1WithID("a", "bad id") })
+		if m.Error() == nil {
+			t.Error("Error() = nil, want the heading's")
+		}
+	})
+}
+
 // TestDetailsDoesNotSwallowTheNextBlock is the other half of the same defect:
 // without the trailing blank line the block after </details> stayed inside the
 // HTML block and rendered as text.
@@ -2043,20 +2108,20 @@ func TestTableOfContentsMarkersStayTight(t *testing.T) {
 func TestInsertTableOfContentsWithMissingMarkers(t *testing.T) {
 	t.Parallel()
 
-	toc := []string{"- [A](#a)"}
+	toc := rawBlocks("- [A](#a)")
 
-	tests := map[string][]string{
-		"no markers at all": {"# Title"},
-		"only the opening":  {TableOfContentsMarkerBegin, "# Title"},
-		"only the closing":  {"# Title", TableOfContentsMarkerEnd},
-		"reversed order":    {TableOfContentsMarkerEnd, TableOfContentsMarkerBegin},
+	tests := map[string][]Block{
+		"no markers at all": rawBlocks("# Title"),
+		"only the opening":  rawBlocks(TableOfContentsMarkerBegin, "# Title"),
+		"only the closing":  rawBlocks("# Title", TableOfContentsMarkerEnd),
+		"reversed order":    rawBlocks(TableOfContentsMarkerEnd, TableOfContentsMarkerBegin),
 	}
 
 	for name, body := range tests {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			if diff := cmp.Diff(body, insertTableOfContents(body, toc)); diff != "" {
+			if diff := cmp.Diff(body, insertTableOfContents(body, toc), cmp.AllowUnexported(Raw{}, source{})); diff != "" {
 				t.Errorf("body was modified (-want +got):\n%s", diff)
 			}
 		})
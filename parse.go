@@ -0,0 +1,390 @@
+package markdown
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Parse reads a GitHub Flavored Markdown document into a builder, so that an
+// existing file can be changed and written back rather than rebuilt by hand.
+//
+// Every top-level block of the source becomes one block of the builder, in
+// order, and every blank line between them is kept as one too. Headings,
+// paragraphs, fenced code blocks and "---" rules are read into a [Heading], a
+// [Paragraph], a [CodeBlock] and a [HorizontalRule]; everything else, lists and
+// tables included, is [Raw] text. A document nobody touched is written back
+// byte for byte, apart from two things: line endings follow the platform as
+// they do for everything else the builder writes, and Build ends the document
+// with exactly one line feed.
+//
+// The headings of the source are registered as if they had been added with H1
+// to H6, so TableOfContents and SectionBounds see them. The options are the
+// ones NewMarkdown takes, and they apply to the blocks added after parsing: a
+// block the source already holds is written the way the source wrote it.
+//
+// YAML or TOML front matter at the top of the source is kept as it is, and
+// is not a block. WithFrontMatter among the options replaces it.
+//
+// The error is the reader's. Markdown has no syntax errors, so any text parses.
+func Parse(r io.Reader, w io.Writer, opts ...Option) (*Markdown, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read markdown text: %w", err)
+	}
+
+	m := NewMarkdown(w, opts...)
+	frontMatter, body := internal.SplitFrontMatter(strings.ReplaceAll(string(src), "\r\n", "\n"))
+	if m.frontMatter == "" {
+		m.frontMatter = normalizeLineFeeds(frontMatter)
+	}
+	m.parse(body)
+	return m, nil
+}
+
+// parsedBlock is one block under construction, with the headings that start
+// inside it and the node it was read from.
+type parsedBlock struct {
+	text     string
+	headings []headerInfo
+	// node is the block the text holds, or nil when the text is blank lines,
+	// link reference definitions, or more than one block.
+	node ast.Node
+}
+
+// parse appends the blocks of src, whose lines end in "\n", to the body.
+func (m *Markdown) parse(src string) {
+	if src == "" {
+		return
+	}
+	data := []byte(src)
+	root := goldmark.New(goldmark.WithExtensions(extension.GFM, extension.Footnote)).
+		Parser().Parse(text.NewReader(data))
+
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	lineOf := lineIndex(src)
+
+	// Where each top-level block starts, and the heading it is when it is one.
+	type start struct {
+		line    int
+		heading *headerInfo
+		node    ast.Node
+	}
+	starts := []start{}
+	for _, node := range topLevelNodes(root) {
+		if node.Pos() < 0 {
+			continue
+		}
+		s := start{line: lineOf(node.Pos()), node: node}
+		if h, ok := node.(*ast.Heading); ok {
+			s.heading = &headerInfo{level: TableOfContentsDepth(h.Level), text: headingText(h, data)}
+		}
+		starts = append(starts, s)
+	}
+	sort.SliceStable(starts, func(i, j int) bool { return starts[i].line < starts[j].line })
+
+	blocks := []parsedBlock{}
+	from := 0
+	var opened start
+	for _, s := range starts {
+		if s.line < from {
+			continue // a second block opening on a line already taken
+		}
+		blocks = append(blocks, splitSpan(lines[from:s.line], opened.heading, opened.node, from == 0)...)
+		from, opened = s.line, s
+	}
+	blocks = append(blocks, splitSpan(lines[from:], opened.heading, opened.node, from == 0)...)
+
+	for _, b := range m.mergeTightBlocks(blocks) {
+		m.add(b.block(data))
+	}
+}
+
+// block returns the typed block the text was read as.
+//
+// A heading or a fenced code block keeps its source, so it is written back the
+// way it was read until one of its fields changes.
+func (b parsedBlock) block(data []byte) Block {
+	text := normalizeLineFeeds(b.text)
+	switch n := b.node.(type) {
+	case *ast.Heading:
+		h := b.headings[0]
+		return &Heading{
+			Level:  int(h.level),
+			Text:   h.text,
+			source: source{text: text, fields: []any{int(h.level), h.text, ""}},
+		}
+	case *ast.FencedCodeBlock:
+		lang := SyntaxHighlight(n.Language(data))
+		code := normalizeLineFeeds(strings.TrimSuffix(string(n.Lines().Value(data)), "\n"))
+		return &CodeBlock{
+			Lang:   lang,
+			Code:   code,
+			source: source{text: text, fields: []any{lang, code}},
+		}
+	case *ast.Paragraph:
+		return &Paragraph{Text: text, source: source{text: text, fields: []any{text}}}
+	case *ast.ThematicBreak:
+		if text == "---" {
+			return &HorizontalRule{}
+		}
+	}
+	r := &Raw{Text: text}
+	if len(b.headings) > 0 {
+		r.source = source{text: text, fields: []any{text}, headings: b.headings}
+	}
+	return r
+}
+
+// topLevelNodes returns the blocks directly under the document.
+//
+// The footnote extension moves every footnote definition into a list it appends
+// to the end of the document, which is not where the source wrote them. Their
+// positions still say where that was, so the definitions are taken out of the
+// list and sorted back in by the caller.
+func topLevelNodes(root ast.Node) []ast.Node {
+	nodes := []ast.Node{}
+	for n := root.FirstChild(); n != nil; n = n.NextSibling() {
+		if list, ok := n.(*extast.FootnoteList); ok {
+			for f := list.FirstChild(); f != nil; f = f.NextSibling() {
+				nodes = append(nodes, f)
+			}
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// lineIndex returns a function mapping a byte offset in src to its line.
+func lineIndex(src string) func(int) int {
+	starts := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return func(offset int) int {
+		return sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+	}
+}
+
+// headingText returns the text of a heading as the source wrote it, markup
+// included, which is what H1 to H6 record for the table of contents.
+func headingText(h *ast.Heading, source []byte) string {
+	parts := make([]string, 0, h.Lines().Len())
+	for i := 0; i < h.Lines().Len(); i++ {
+		segment := h.Lines().At(i)
+		parts = append(parts, strings.TrimSpace(string(segment.Value(source))))
+	}
+	return strings.Join(parts, " ")
+}
+
+// referenceDefinition matches a line holding a link reference definition,
+// which the parser consumes without leaving a node behind.
+var referenceDefinition = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*\S`) //nolint:gochecknoglobals // compiled once
+
+// splitSpan turns the lines from the start of one block to the start of the
+// next into body entries: the block itself, then one entry per blank line.
+//
+// The span can hold text that belongs to no block. Link reference definitions
+// leave no node, so they end up at the end of the span before them; they are
+// split off into entries of their own when a blank line separates them from the
+// block, so replacing the block does not take them with it. The span before the
+// first block, marked by leading, can also start with blank lines.
+func splitSpan(lines []string, heading *headerInfo, node ast.Node, leading bool) []parsedBlock {
+	blocks := []parsedBlock{}
+	if leading {
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			blocks = append(blocks, parsedBlock{text: lines[0]})
+			lines = lines[1:]
+		}
+	}
+
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	trailing := lines[end:]
+	content := lines[:end]
+
+	// Peel link reference definitions off the end, a paragraph at a time,
+	// along with the blank lines in front of them. The tail alternates between
+	// the two: blank lines at even indexes, definitions at odd ones.
+	tail := [][]string{}
+	for {
+		cut := len(content)
+		for cut > 0 && strings.TrimSpace(content[cut-1]) != "" {
+			cut--
+		}
+		gap := cut
+		for gap > 0 && strings.TrimSpace(content[gap-1]) == "" {
+			gap--
+		}
+		if gap == 0 || gap == cut || !allReferenceDefinitions(content[cut:]) {
+			break
+		}
+		tail = append([][]string{content[gap:cut], content[cut:]}, tail...)
+		content = content[:gap]
+	}
+
+	if len(content) > 0 {
+		b := parsedBlock{text: strings.Join(content, "\n"), node: node}
+		if heading != nil {
+			b.headings = []headerInfo{*heading}
+		}
+		blocks = append(blocks, b)
+	}
+	for i, chunk := range tail {
+		if i%2 == 0 {
+			for _, line := range chunk {
+				blocks = append(blocks, parsedBlock{text: line})
+			}
+			continue
+		}
+		blocks = append(blocks, parsedBlock{text: strings.Join(chunk, "\n")})
+	}
+	for _, line := range trailing {
+		blocks = append(blocks, parsedBlock{text: line})
+	}
+	return blocks
+}
+
+// allReferenceDefinitions reports whether every line is a link reference
+// definition.
+func allReferenceDefinitions(lines []string) bool {
+	for _, line := range lines {
+		if !referenceDefinition.MatchString(line) {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+// mergeTightBlocks joins two blocks the source wrote on consecutive lines when
+// the join would otherwise put a blank line between them.
+//
+// The blank line rules exist for documents the builder writes. A source that
+// ends a list with a heading on the next line is valid markdown, and writing it
+// back with a blank line added would change a file the caller never touched.
+func (m *Markdown) mergeTightBlocks(blocks []parsedBlock) []parsedBlock {
+	merged := make([]parsedBlock, 0, len(blocks))
+	r := m.blockRenderer()
+	for _, b := range blocks {
+		if n := len(merged); n > 0 && needsBlankLine(r.render(merged[n-1].raw())[0], r.render(b.raw())[0], m.blockSpacing) {
+			merged[n-1].text += "\n" + b.text
+			merged[n-1].headings = append(merged[n-1].headings, b.headings...)
+			merged[n-1].node = nil
+			continue
+		}
+		merged = append(merged, b)
+	}
+	return merged
+}
+
+// raw returns the text as a Raw block, whose spacing class is read from the
+// text the way the source's is.
+func (b parsedBlock) raw() []Block {
+	return []Block{&Raw{Text: b.text}}
+}
+
+// BlockCount returns the number of blocks in the document, counting every
+// blank line between blocks as one.
+func (m *Markdown) BlockCount() int {
+	return len(m.body)
+}
+
+// BlockText returns the markdown of the block at index i, as it will be
+// written, or an empty string when there is no such block.
+func (m *Markdown) BlockText(i int) string {
+	if i < 0 || i >= len(m.body) {
+		return ""
+	}
+	return m.body[i].render(m.blockRenderer())
+}
+
+// SectionBounds returns the blocks of the first section whose heading text is
+// heading: start is the heading itself, and end is the first block after the
+// section, which is the next heading of the same or a higher level, or the end
+// of the document. The result is ready to hand to ReplaceBlocks.
+//
+// ok is false when the document has no heading with that text.
+func (m *Markdown) SectionBounds(heading string) (start, end int, ok bool) {
+	headers := m.headings()
+	for i, h := range headers {
+		if h.text != heading {
+			continue
+		}
+		end = len(m.body)
+		for _, next := range headers[i+1:] {
+			if next.level <= h.level {
+				end = next.block
+				break
+			}
+		}
+		return h.block, end, true
+	}
+	return 0, 0, false
+}
+
+// ReplaceBlocks replaces the blocks from index i up to, but not including,
+// index j with the blocks of with. A nil with removes them, and i equal to j
+// inserts without removing anything.
+//
+// The blocks of with are shared rather than copied, so changing one later
+// changes both documents. Its headings join the document's, so a table of
+// contents sees them, and an error with recorded is recorded here too. Its
+// footnotes and reference links are registered here; when their numbers are
+// taken, its blocks are copied instead, with the markers renumbered as Include
+// does. A range outside the document is recorded as an error and
+// changes nothing.
+func (m *Markdown) ReplaceBlocks(i, j int, with *Markdown) *Markdown {
+	if i < 0 || j < i || j > len(m.body) {
+		m.addError(fmt.Errorf("invalid block range [%d, %d) for a document of %d blocks", i, j, len(m.body)))
+		return m
+	}
+
+	var blocks []Block
+	if with != nil {
+		blocks = with.body
+		m.adopt(with)
+		if renumber, ok := m.renumbering(with); ok {
+			blocks = inclusion{renumber: renumber}.blocks(blocks)
+		}
+	}
+
+	body := make([]Block, 0, len(m.body)-(j-i)+len(blocks))
+	body = append(body, m.body[:i]...)
+	body = append(body, blocks...)
+	body = append(body, m.body[j:]...)
+
+	m.body = body
+	return m
+}
+
+// adopt records the error other recorded, and takes over the table of
+// contents it asked for and the cross references it made, for blocks of other
+// that join the document. Its cross references are checked against the
+// headings of this document when it is built.
+func (m *Markdown) adopt(other *Markdown) {
+	m.addError(other.err)
+	for _, id := range other.crossReferenceOrder {
+		m.referTo(id, other.crossReferences[id])
+	}
+	if other.tocInserted {
+		if m.tocInserted {
+			m.addError(errTableOfContentsGenerated)
+		} else {
+			m.tocInserted, m.tocOptions = true, other.tocOptions
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParseRoundTripsTheRepositoryDocuments writes back every committed
+// document that ends with a line feed and expects the same bytes. They are the
+// widest sample of markdown at hand: hand-written prose, generated tables, and
+// fences holding every mermaid diagram type.
+func TestParseRoundTripsTheRepositoryDocuments(t *testing.T) {
+	t.Parallel()
+
+	files := []string{"README.md", "CHANGELOG.md"}
+	for _, pattern := range []string{"testdata/golden/*.md", "doc/*/generated.md"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := os.ReadFile(file) //nolint:gosec // paths come from the globs above
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := normalizeLineFeeds(strings.ReplaceAll(string(src), "\r\n", "\n"))
+
+			buf := &bytes.Buffer{}
+			m, err := Parse(bytes.NewReader(src), buf)
+			if err != nil {
+				t.Fatalf("Parse() = %v", err)
+			}
+			if err := m.Build(); err != nil {
+				t.Fatalf("Build() = %v", err)
+			}
+			if diff := cmp.Diff(want, buf.String()); diff != "" {
+				t.Errorf("the document did not round-trip (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseSplitsTopLevelBlocks(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		src  string
+		want []string
+	}{
+		"blank lines are blocks of their own": {
+			src:  "# Title\n\nSome text.\n\n\n- a\n- b\n",
+			want: []string{"# Title", "", "Some text.", "", "", "- a" + lf() + "- b"},
+		},
+		"a fenced block holding a blank line is one block": {
+			src:  "```go\na\n\nb\n```\ntext\n",
+			want: []string{"```go" + lf() + "a" + lf() + lf() + "b" + lf() + "```", "text"},
+		},
+		"reference definitions are split from the paragraph before them": {
+			src:  "See [Go][go].\n\n[go]: https://go.dev\n",
+			want: []string{"See [Go][go].", "", "[go]: https://go.dev"},
+		},
+		"a list followed directly by a heading stays together": {
+			src:  "- a\n# H\n",
+			want: []string{"- a" + lf() + "# H"},
+		},
+		"footnote definitions stay where they were written": {
+			src:  "Text[^1].\n\n[^1]: Note.\n\nMore.\n",
+			want: []string{"Text[^1].", "", "[^1]: Note.", "", "More."},
+		},
+		"leading blank lines are kept": {
+			src:  "\n\ntext\n",
+			want: []string{"", "", "text"},
+		},
+		"an empty document has no blocks": {
+			src:  "",
+			want: []string{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := Parse(strings.NewReader(tt.src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, blockTexts(m)); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseRegistersHeadings(t *testing.T) {
+	t.Parallel()
+
+	m, err := Parse(strings.NewReader("# Guide\n\n## Install\n\nSetext\n------\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []headerInfo{
+		{level: TableOfContentsDepthH1, text: "Guide", block: 0},
+		{level: TableOfContentsDepthH2, text: "Install", block: 2},
+		{level: TableOfContentsDepthH2, text: "Setext", block: 4},
+	}
+	if diff := cmp.Diff(want, m.headings(), cmp.AllowUnexported(headerInfo{})); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseReportsTheReaderError(t *testing.T) {
+	t.Parallel()
+
+	want := errors.New("read failed")
+	if _, err := Parse(iotest.ErrReader(want), nil); !errors.Is(err, want) {
+		t.Errorf("Parse() = %v, want an error wrapping %v", err, want)
+	}
+}
+
+func TestSectionBounds(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil).
+		H1("Title").
+		H2("A").PlainText("a").
+		H3("A.1").PlainText("a.1").
+		H2("B").PlainText("b")
+
+	tests := map[string]struct {
+		heading    string
+		start, end int
+		ok         bool
+	}{
+		"a section ends at the next heading of its level": {heading: "A", start: 1, end: 5, ok: true},
+		"a subsection ends at the next higher heading":    {heading: "A.1", start: 3, end: 5, ok: true},
+		"the last section ends at the end":                {heading: "B", start: 5, end: 7, ok: true},
+		"the top heading spans the document":              {heading: "Title", start: 0, end: 7, ok: true},
+		"a missing heading is reported":                   {heading: "C"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			start, end, ok := m.SectionBounds(tt.heading)
+			if start != tt.start || end != tt.end || ok != tt.ok {
+				t.Errorf("SectionBounds(%q) = (%d, %d, %v), want (%d, %d, %v)",
+					tt.heading, start, end, ok, tt.start, tt.end, tt.ok)
+			}
+		})
+	}
+}
+
+func TestReplaceBlocks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a replaced section takes its headings with it", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).
+			TableOfContents(TableOfContentsDepthH2).
+			H2("Old").PlainText("old").
+			H2("Kept")
+		start, end, _ := m.SectionBounds("Old")
+		m.ReplaceBlocks(start, end, NewMarkdown(nil).H2("New").PlainText("new"))
+
+		want := strings.Join([]string{
+			TableOfContentsMarkerBegin,
+			"- [New](#new)",
+			"- [Kept](#kept)",
+			TableOfContentsMarkerEnd,
+			"",
+			"## New",
+			"new",
+			"## Kept",
+		}, lf())
+		if diff := cmp.Diff(want, m.String()); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+		if _, _, ok := m.SectionBounds("Old"); ok {
+			t.Error("the replaced heading is still found")
+		}
+		if start, _, _ := m.SectionBounds("Kept"); m.BlockText(start) != "## Kept" {
+			t.Errorf("the heading after the replacement points at %q", m.BlockText(start))
+		}
+	})
+
+	t.Run("nil removes and an empty range inserts", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).PlainText("a").PlainText("b").PlainText("c")
+		m.ReplaceBlocks(1, 2, nil).ReplaceBlocks(0, 0, NewMarkdown(nil).PlainText("first"))
+
+		if diff := cmp.Diff([]string{"first", "a", "c"}, blockTexts(m)); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the replacement error is recorded", func(t *testing.T) {
+		t.Parallel()
+
+		with := NewMarkdown(nil).Table(TableSet{Header: []string{"a"}, Rows: [][]string{{"1", "2"}}})
+		m := NewMarkdown(nil).ReplaceBlocks(0, 0, with)
+		if !errors.Is(m.Error(), ErrMismatchColumn) {
+			t.Errorf("Error() = %v, want an error wrapping ErrMismatchColumn", m.Error())
+		}
+	})
+
+	t.Run("footnotes and reference links come along", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := Parse(strings.NewReader("# A\n\nold"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		with := NewMarkdown(nil)
+		with.PlainText("new" + with.Footnote("note"))
+		m.ReplaceBlocks(2, 3, with)
+
+		want := "# A\n\nnew[^1]\n\n[^1]: note"
+		if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("taken numbers are renumbered", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil)
+		m.PlainText("a" + m.Footnote("first") + " " + m.ReferenceLink("Go", "https://go.dev"))
+		with := NewMarkdown(nil)
+		with.PlainText("b" + with.Footnote("second") + " " + with.ReferenceLink("pkg", "https://pkg.go.dev"))
+		m.ReplaceBlocks(1, 1, with)
+
+		want := "a[^1] [Go][1]\nb[^2] [pkg][2]\n\n[^1]: first\n[^2]: second\n\n[1]: https://go.dev\n[2]: https://pkg.go.dev"
+		if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(normalizeLineFeeds("b[^1] [pkg][1]\n\n[^1]: second\n\n[1]: https://pkg.go.dev"), with.String()); diff != "" {
+			t.Errorf("the replacement changed (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("a range outside the document is an error", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(nil).PlainText("a").ReplaceBlocks(0, 2, nil)
+		if m.Error() == nil {
+			t.Error("Error() = nil, want the range to be rejected")
+		}
+		if m.BlockCount() != 1 {
+			t.Errorf("BlockCount() = %d, want the document unchanged", m.BlockCount())
+		}
+	})
+}
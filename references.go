@@ -0,0 +1,118 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// reference is a link target ReferenceLink registered.
+type reference struct {
+	url   string
+	title string
+}
+
+// Footnote registers a footnote and returns the reference to place in the
+// text: [^1] for the first footnote, [^2] for the next, and so on. The same
+// note registered twice gets the same number.
+//
+// The definitions of every footnote, and of every link ReferenceLink
+// registered, are written at the end of the document by String and Build, so
+// none can be forgotten. A note of several lines is indented under its
+// definition, which keeps it one footnote.
+//
+// The numbers count from 1 in each builder. A document read by Parse keeps the
+// footnotes it already has, so give those names other than numbers.
+func (m *Markdown) Footnote(note string) string {
+	return FootnoteReference(strconv.Itoa(m.footnoteID(note)))
+}
+
+// footnoteID registers a note, unless it already is, and returns its number.
+func (m *Markdown) footnoteID(note string) int {
+	if m.footnoteIDs == nil {
+		m.footnoteIDs = map[string]int{}
+	}
+	id, ok := m.footnoteIDs[note]
+	if !ok {
+		m.footnotes = append(m.footnotes, note)
+		id = len(m.footnotes)
+		m.footnoteIDs[note] = id
+	}
+	return id
+}
+
+// ReferenceLink registers a link target and returns a reference link to it:
+// [text][1] for the first URL, [text][2] for the next, and so on. Every link to
+// the same URL shares one definition, which keeps the title the first link
+// gave it.
+//
+// The definitions are written at the end of the document, after the
+// footnotes. See Footnote.
+func (m *Markdown) ReferenceLink(text, url string, title ...string) string {
+	r := reference{url: url}
+	if len(title) > 0 {
+		r.title = title[0]
+	}
+	return ReferenceLink(text, strconv.Itoa(m.referenceID(r)))
+}
+
+// referenceID registers a link target, unless its URL already is, and returns
+// its label.
+func (m *Markdown) referenceID(r reference) int {
+	if m.referenceIDs == nil {
+		m.referenceIDs = map[string]int{}
+	}
+	id, ok := m.referenceIDs[r.url]
+	if !ok {
+		m.references = append(m.references, r)
+		id = len(m.references)
+		m.referenceIDs[r.url] = id
+	}
+	return id
+}
+
+// definitions returns the footnote definitions followed by the reference link
+// definitions, or "" when nothing was registered.
+func (m *Markdown) definitions() string {
+	lf := internal.LineFeed()
+	groups := []string{}
+
+	if len(m.footnotes) > 0 {
+		lines := make([]string, 0, len(m.footnotes))
+		for i, note := range m.footnotes {
+			noteLines := strings.Split(normalizeLineFeeds(note), lf)
+			for j := 1; j < len(noteLines); j++ {
+				if noteLines[j] != "" {
+					noteLines[j] = "    " + noteLines[j]
+				}
+			}
+			lines = append(lines, FootnoteDefinition(strconv.Itoa(i+1), strings.Join(noteLines, lf)))
+		}
+		groups = append(groups, strings.Join(lines, lf))
+	}
+	if len(m.references) > 0 {
+		lines := make([]string, 0, len(m.references))
+		for i, r := range m.references {
+			lines = append(lines, ReferenceLinkDefinition(strconv.Itoa(i+1), r.url, r.title))
+		}
+		groups = append(groups, strings.Join(lines, lf))
+	}
+	return strings.Join(groups, lf+lf)
+}
+
+// appendDefinitions writes the definitions after the body, separated from it
+// by a blank line: a definition cannot interrupt a paragraph.
+func appendDefinitions(body, definitions string) string {
+	lf := internal.LineFeed()
+	switch {
+	case definitions == "":
+		return body
+	case body == "":
+		return definitions
+	case strings.HasSuffix(body, lf):
+		return body + lf + definitions
+	default:
+		return body + lf + lf + definitions
+	}
+}
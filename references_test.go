@@ -0,0 +1,125 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFootnoteAndReferenceLink(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		build func(m *Markdown)
+		want  string
+	}{
+		"footnotes are numbered in order": {
+			build: func(m *Markdown) {
+				m.PlainText("a" + m.Footnote("first") + " b" + m.Footnote("second"))
+			},
+			want: "a[^1] b[^2]" + lf() + lf() + "[^1]: first" + lf() + "[^2]: second",
+		},
+		"the same note twice is one footnote": {
+			build: func(m *Markdown) {
+				m.PlainText("a" + m.Footnote("same")).PlainText("b" + m.Footnote("same"))
+			},
+			want: "a[^1]" + lf() + "b[^1]" + lf() + lf() + "[^1]: same",
+		},
+		"one definition per URL": {
+			build: func(m *Markdown) {
+				m.PlainText(m.ReferenceLink("Go", "https://go.dev", "The Go site") + " and " +
+					m.ReferenceLink("golang", "https://go.dev", "ignored") + " and " +
+					m.ReferenceLink("pkg", "https://pkg.go.dev"))
+			},
+			want: "[Go][1] and [golang][1] and [pkg][2]" + lf() + lf() +
+				`[1]: https://go.dev "The Go site"` + lf() + "[2]: https://pkg.go.dev",
+		},
+		"footnotes first, then links": {
+			build: func(m *Markdown) {
+				m.PlainText(m.ReferenceLink("x", "https://x.test") + m.Footnote("note"))
+			},
+			want: "[x][1][^1]" + lf() + lf() + "[^1]: note" + lf() + lf() + "[1]: https://x.test",
+		},
+		"a note of several lines": {
+			build: func(m *Markdown) {
+				m.PlainText("a" + m.Footnote("line one\nline two\n\nanother paragraph"))
+			},
+			want: "a[^1]" + lf() + lf() + "[^1]: line one" + lf() + "    line two" + lf() + lf() + "    another paragraph",
+		},
+		"after a block ending with a line feed": {
+			build: func(m *Markdown) {
+				m.CustomTable(TableSet{Header: []string{"a"}, Rows: [][]string{{"b" + m.Footnote("n")}}}, TableOptions{})
+			},
+			want: "|   a   |" + lf() + "|-------|" + lf() + "| b[^1] |" + lf() + lf() + "[^1]: n",
+		},
+		"nothing registered": {
+			build: func(m *Markdown) { m.PlainText("a") },
+			want:  "a",
+		},
+		"only definitions": {
+			build: func(m *Markdown) { _ = m.Footnote("orphan") },
+			want:  "[^1]: orphan",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil)
+			tt.build(m)
+			if got := m.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+
+			// Streaming writes the same document.
+			var buf strings.Builder
+			streamed := NewMarkdown(&buf, WithStreaming())
+			tt.build(streamed)
+			if err := streamed.Build(); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := buf.String(), tt.want+lf(); got != want {
+				t.Errorf("streamed = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFootnoteWithFrontMatterAndSpacing(t *testing.T) {
+	t.Parallel()
+
+	build := func(m *Markdown) {
+		m.H1("Title").PlainText("text" + m.Footnote("note"))
+	}
+
+	m := NewMarkdown(nil, WithFrontMatter(map[string]string{"title": "x"}), WithBlockSpacing())
+	build(m)
+	want := "---" + lf() + "title: x" + lf() + "---" + lf() + lf() +
+		"# Title" + lf() + lf() + "text[^1]" + lf() + lf() + "[^1]: note"
+	if got := m.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	var buf strings.Builder
+	streamed := NewMarkdown(&buf, WithFrontMatter(map[string]string{"title": "x"}), WithBlockSpacing(), WithStreaming())
+	build(streamed)
+	if err := streamed.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want+lf() {
+		t.Errorf("streamed = %q, want %q", got, want+lf())
+	}
+}
+
+func TestFootnoteHTML(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil)
+	m.PlainText("Claim" + m.Footnote("Source.") + " and " + m.ReferenceLink("a link", "https://example.com"))
+	got := renderHTML(t, m)
+	for _, want := range []string{`<a href="#fn:1"`, `<a href="https://example.com">a link</a>`, "Source."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("the html has no %q:\n%s", want, got)
+		}
+	}
+}
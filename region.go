@@ -0,0 +1,198 @@
+package markdown
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// regionMarker matches a line holding a managed region marker and captures
+// whether it begins or ends the region, and its name.
+var regionMarker = regexp.MustCompile(`^[ \t]*<!--[ \t]*(BEGIN|END)_GENERATED:([\w.-]+)[ \t]*-->[ \t]*$`) //nolint:gochecknoglobals // compiled once
+
+// regionFence matches a line opening or closing a fenced code block.
+var regionFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})") //nolint:gochecknoglobals // compiled once
+
+// RegionMarkerBegin returns the line that opens the managed region name, for
+// example "<!-- BEGIN_GENERATED:api -->". A name is made of letters, digits,
+// '_', '-' and '.'.
+func RegionMarkerBegin(name string) string {
+	return "<!-- BEGIN_GENERATED:" + name + " -->"
+}
+
+// RegionMarkerEnd returns the line that closes the managed region name, for
+// example "<!-- END_GENERATED:api -->".
+func RegionMarkerEnd(name string) string {
+	return "<!-- END_GENERATED:" + name + " -->"
+}
+
+// UpdateRegions replaces the managed regions of a document with generated
+// markdown and returns the document. Each key of regions names a region, marked
+// by the lines RegionMarkerBegin and RegionMarkerEnd return, and everything
+// between the two lines is replaced with the value's String. The markers stay,
+// so the document can be updated again, and the rest of it is kept as it is.
+//
+// This is how a hand-written README keeps a generated table in sync: the
+// author places the markers, and a generator rewrites what is between them.
+// A marker inside a fenced code block is text, not a marker.
+//
+// Markers that do not pair up are an error wrapping ErrUnbalancedRegion, and a
+// region in regions with no markers in the document is an error wrapping
+// ErrRegionNotFound. A region the document marks but regions does not name is
+// left alone. A nil builder is an error, a builder that recorded an error is
+// that error, and a streaming builder, which keeps no text to place, is
+// ErrStreamingUnsupported.
+func UpdateRegions(document string, regions map[string]*Markdown) (string, error) {
+	content := make(map[string]string, len(regions))
+	for name, m := range regions {
+		if !regionMarker.MatchString(RegionMarkerBegin(name)) {
+			return "", fmt.Errorf("invalid region name %q", name)
+		}
+		if m == nil {
+			return "", fmt.Errorf("region %s: nil builder", name)
+		}
+		if m.stream != nil {
+			return "", fmt.Errorf("region %s: %w", name, ErrStreamingUnsupported)
+		}
+		if err := m.Error(); err != nil {
+			return "", fmt.Errorf("region %s: %w", name, err)
+		}
+		content[name] = m.String()
+	}
+
+	// The document keeps its own line endings, whatever the builder writes.
+	newline := "\n"
+	if strings.Contains(document, "\r\n") {
+		newline = "\r\n"
+	}
+
+	lines := strings.SplitAfter(document, "\n")
+	var b strings.Builder
+	found := make(map[string]bool, len(regions))
+	open, openLine, replacing := "", 0, false
+	fence := ""
+	for i, line := range lines {
+		text := strings.TrimRight(line, "\r\n")
+
+		if !replacing {
+			b.WriteString(line)
+		}
+		if f := regionFence.FindStringSubmatch(text); f != nil {
+			switch {
+			case fence == "":
+				fence = f[1]
+			case f[1][0] == fence[0] && len(f[1]) >= len(fence) && strings.TrimSpace(strings.TrimLeft(text, " "+fence[:1])) == "":
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		marker := regionMarker.FindStringSubmatch(text)
+		if marker == nil {
+			continue
+		}
+		kind, name := marker[1], marker[2]
+		switch {
+		case kind == "BEGIN" && open != "":
+			return "", fmt.Errorf("line %d: region %s begins inside region %s, which begins on line %d: %w", i+1, name, open, openLine, ErrUnbalancedRegion)
+		case kind == "BEGIN":
+			open, openLine = name, i+1
+			found[name] = true
+			generated, ok := content[name]
+			if !ok {
+				// A region someone else manages is copied through.
+				continue
+			}
+			replacing = true
+			if generated != "" {
+				generated = strings.TrimSuffix(strings.ReplaceAll(generated, "\r\n", "\n"), "\n")
+				b.WriteString(strings.ReplaceAll(generated, "\n", newline) + newline)
+			}
+		case open == "":
+			return "", fmt.Errorf("line %d: region %s ends without beginning: %w", i+1, name, ErrUnbalancedRegion)
+		case name != open:
+			return "", fmt.Errorf("line %d: region %s ends inside region %s, which begins on line %d: %w", i+1, name, open, openLine, ErrUnbalancedRegion)
+		default:
+			if replacing {
+				b.WriteString(line)
+			}
+			open, replacing = "", false
+		}
+	}
+	if open != "" {
+		return "", fmt.Errorf("line %d: region %s has no end marker: %w", openLine, open, ErrUnbalancedRegion)
+	}
+
+	for name := range regions {
+		if !found[name] {
+			return "", fmt.Errorf("region %s: %w", name, ErrRegionNotFound)
+		}
+	}
+	return b.String(), nil
+}
+
+// UpdateFileRegions is UpdateRegions on a file: it reads the file at path,
+// replaces its managed regions and writes it back.
+//
+// The file is written atomically. The new text goes to a temporary file in the
+// same directory, which then takes the place of the original, so a reader
+// never sees half a file and a failure leaves the original untouched. The file
+// keeps its permissions, and a file whose text does not change is not written
+// at all. Errors are those UpdateRegions returns, and those of reading and
+// writing the file.
+func UpdateFileRegions(path string, regions map[string]*Markdown) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to update regions: %w", err)
+	}
+	src, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to update regions: %w", err)
+	}
+
+	updated, err := UpdateRegions(string(src), regions)
+	if err != nil {
+		return fmt.Errorf("failed to update regions of %s: %w", path, err)
+	}
+	if updated == string(src) {
+		return nil
+	}
+	return writeFileAtomically(path, []byte(updated), info.Mode().Perm())
+}
+
+// writeFileAtomically replaces the file at path with data by renaming a
+// temporary file written next to it.
+func writeFileAtomically(path string, data []byte, perm os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
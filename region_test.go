@@ -0,0 +1,252 @@
+package markdown
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUpdateRegions(t *testing.T) {
+	t.Parallel()
+
+	table := func() *Markdown {
+		return NewMarkdown(nil).Table(TableSet{Header: []string{"a"}, Rows: [][]string{{"1"}}})
+	}
+
+	tests := map[string]struct {
+		document string
+		regions  map[string]*Markdown
+		want     string
+	}{
+		"an empty region is filled": {
+			document: "# API\n<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\ntail\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil).PlainText("new")},
+			want:     "# API\n<!-- BEGIN_GENERATED:api -->\nnew\n<!-- END_GENERATED:api -->\ntail\n",
+		},
+		"old content is replaced": {
+			document: "<!-- BEGIN_GENERATED:api -->\nold\n\nolder\n<!-- END_GENERATED:api -->",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil).PlainText("new")},
+			want:     "<!-- BEGIN_GENERATED:api -->\nnew\n<!-- END_GENERATED:api -->",
+		},
+		"an empty builder empties the region": {
+			document: "<!-- BEGIN_GENERATED:api -->\nold\n<!-- END_GENERATED:api -->\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil)},
+			want:     "<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n",
+		},
+		"a region not named is left alone": {
+			document: "<!-- BEGIN_GENERATED:other -->\nkeep\n<!-- END_GENERATED:other -->\n<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil).PlainText("new")},
+			want:     "<!-- BEGIN_GENERATED:other -->\nkeep\n<!-- END_GENERATED:other -->\n<!-- BEGIN_GENERATED:api -->\nnew\n<!-- END_GENERATED:api -->\n",
+		},
+		"several regions": {
+			document: "<!-- BEGIN_GENERATED:a -->\n<!-- END_GENERATED:a -->\ntext\n  <!--BEGIN_GENERATED:b.v2-->\n<!-- END_GENERATED:b.v2 -->\n",
+			regions:  map[string]*Markdown{"a": NewMarkdown(nil).PlainText("A"), "b.v2": NewMarkdown(nil).PlainText("B")},
+			want:     "<!-- BEGIN_GENERATED:a -->\nA\n<!-- END_GENERATED:a -->\ntext\n  <!--BEGIN_GENERATED:b.v2-->\nB\n<!-- END_GENERATED:b.v2 -->\n",
+		},
+		"a marker in a code block is text": {
+			document: "````md\n<!-- BEGIN_GENERATED:api -->\n```\n<!-- END_GENERATED:api -->\n````\n<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil).PlainText("new")},
+			want:     "````md\n<!-- BEGIN_GENERATED:api -->\n```\n<!-- END_GENERATED:api -->\n````\n<!-- BEGIN_GENERATED:api -->\nnew\n<!-- END_GENERATED:api -->\n",
+		},
+		"CRLF is kept": {
+			document: "x\r\n<!-- BEGIN_GENERATED:api -->\r\n<!-- END_GENERATED:api -->\r\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil).PlainText("one").PlainText("two")},
+			want:     "x\r\n<!-- BEGIN_GENERATED:api -->\r\none\r\ntwo\r\n<!-- END_GENERATED:api -->\r\n",
+		},
+		"no regions at all": {
+			document: "# Title\n",
+			regions:  nil,
+			want:     "# Title\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := UpdateRegions(tt.document, tt.regions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+
+			again, err := UpdateRegions(got, tt.regions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != got {
+				t.Errorf("a second update changed the document:\n%q\n%q", got, again)
+			}
+		})
+	}
+
+	t.Run("a table", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := UpdateRegions("<!-- BEGIN_GENERATED:t -->\n<!-- END_GENERATED:t -->\n", map[string]*Markdown{"t": table()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(got, "| 1 |") {
+			t.Errorf("the table is missing:\n%s", got)
+		}
+	})
+}
+
+func TestUpdateRegionsErrors(t *testing.T) {
+	t.Parallel()
+
+	failed := NewMarkdown(nil)
+	failed.addError(errors.New("broken"))
+
+	tests := map[string]struct {
+		document string
+		regions  map[string]*Markdown
+		want     error
+	}{
+		"a region that is not there": {
+			document: "# Title\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil)},
+			want:     ErrRegionNotFound,
+		},
+		"a marker only in a code block": {
+			document: "```\n<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n```\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil)},
+			want:     ErrRegionNotFound,
+		},
+		"no end marker": {
+			document: "<!-- BEGIN_GENERATED:api -->\nold\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil)},
+			want:     ErrUnbalancedRegion,
+		},
+		"no end marker on a region not named": {
+			document: "<!-- BEGIN_GENERATED:other -->\n<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil)},
+			want:     ErrUnbalancedRegion,
+		},
+		"an end without a beginning": {
+			document: "<!-- END_GENERATED:api -->\n",
+			regions:  nil,
+			want:     ErrUnbalancedRegion,
+		},
+		"the wrong end": {
+			document: "<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:cli -->\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(nil)},
+			want:     ErrUnbalancedRegion,
+		},
+		"a streaming builder": {
+			document: "<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n",
+			regions:  map[string]*Markdown{"api": NewMarkdown(&strings.Builder{}, WithStreaming())},
+			want:     ErrStreamingUnsupported,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := UpdateRegions(tt.document, tt.regions); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	for name, regions := range map[string]map[string]*Markdown{
+		"a builder that failed": {"api": failed},
+		"an invalid name":       {"a b": NewMarkdown(nil)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := UpdateRegions("<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n", regions); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestUpdateRegionsNilBuilder(t *testing.T) {
+	t.Parallel()
+
+	_, err := UpdateRegions("<!-- BEGIN_GENERATED:api -->\n<!-- END_GENERATED:api -->\n", map[string]*Markdown{"api": nil})
+	if err == nil || err.Error() != "region api: nil builder" {
+		t.Errorf("got %v, want the nil builder to be an error", err)
+	}
+}
+
+func TestUpdateFileRegions(t *testing.T) {
+	t.Parallel()
+
+	write := func(t *testing.T, text string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "README.md")
+		if err := os.WriteFile(path, []byte(text), 0o640); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("the file is rewritten", func(t *testing.T) {
+		t.Parallel()
+
+		path := write(t, "# Tool\n<!-- BEGIN_GENERATED:api -->\nold\n<!-- END_GENERATED:api -->\n")
+		if err := UpdateFileRegions(path, map[string]*Markdown{"api": NewMarkdown(nil).PlainText("new")}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "# Tool\n<!-- BEGIN_GENERATED:api -->\nnew\n<!-- END_GENERATED:api -->\n"; string(got) != want {
+			t.Errorf("file = %q, want %q", got, want)
+		}
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("the temporary file was left behind: %v", entries)
+		}
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0o640 {
+				t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+			}
+		}
+	})
+
+	t.Run("an error leaves the file alone", func(t *testing.T) {
+		t.Parallel()
+
+		const text = "<!-- BEGIN_GENERATED:api -->\nold\n"
+		path := write(t, text)
+		err := UpdateFileRegions(path, map[string]*Markdown{"api": NewMarkdown(nil).PlainText("new")})
+		if !errors.Is(err, ErrUnbalancedRegion) {
+			t.Errorf("got %v, want ErrUnbalancedRegion", err)
+		}
+		if got, _ := os.ReadFile(filepath.Clean(path)); string(got) != text {
+			t.Errorf("the file changed: %q", got)
+		}
+	})
+
+	t.Run("a missing file", func(t *testing.T) {
+		t.Parallel()
+
+		err := UpdateFileRegions(filepath.Join(t.TempDir(), "none.md"), nil)
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("got %v, want os.ErrNotExist", err)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package markdown
+
+import "fmt"
+
+// Section writes title as a heading one level below the section it is called
+// in, H1 outside of any, and then runs body, in which a Section is one more
+// level down. A function that writes part of a document with Section can then
+// be called at any depth, and the table of contents follows:
+//
+//	md.NewMarkdown(w).
+//		Section("Guide", func(m *md.Markdown) {
+//			m.PlainText("Read this first.")
+//			m.Section("Install", writeInstall) // "## Install"
+//		}).
+//		Build()
+//
+// WithEscaping escapes the title as it does the text of H1. Headings body
+// writes with H1 to H6 keep their levels. A section that would be deeper than
+// H6 is recorded as an error, and neither its heading nor its body is written.
+func (m *Markdown) Section(title string, body func(m *Markdown)) *Markdown {
+	level := m.sectionLevel + 1
+	if level > int(TableOfContentsDepthH6) {
+		m.addError(fmt.Errorf("section %q is nested too deep: its heading would be H%d",
+			title, level))
+		return m
+	}
+	m.add(&Heading{Level: level, Text: m.literal(title, EscapeHeading)})
+
+	outer := m.sectionLevel
+	m.sectionLevel = level
+	defer func() { m.sectionLevel = outer }()
+	if body != nil {
+		body(m)
+	}
+	return m
+}
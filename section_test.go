@@ -0,0 +1,97 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSection(t *testing.T) {
+	t.Parallel()
+
+	install := func(m *Markdown) {
+		m.PlainText("Run go install.")
+		m.Section("Linux", nil)
+	}
+
+	tests := map[string]struct {
+		build func(*Markdown) *Markdown
+		want  string
+	}{
+		"top level": {
+			build: func(m *Markdown) *Markdown { return m.Section("Guide", nil) },
+			want:  "# Guide",
+		},
+		"nested": {
+			build: func(m *Markdown) *Markdown {
+				return m.Section("Guide", func(m *Markdown) {
+					m.Section("Install", install)
+					m.Section("Usage", nil)
+				}).Section("Appendix", nil)
+			},
+			want: "# Guide\n## Install\nRun go install.\n### Linux\n## Usage\n# Appendix",
+		},
+		"the same body at another depth": {
+			build: func(m *Markdown) *Markdown {
+				return m.Section("Install", install).Section("Guide", func(m *Markdown) {
+					m.Section("Install", install)
+				})
+			},
+			want: "# Install\nRun go install.\n## Linux\n# Guide\n## Install\nRun go install.\n### Linux",
+		},
+		"table of contents": {
+			build: func(m *Markdown) *Markdown {
+				return m.TableOfContents(TableOfContentsDepthH3).Section("Guide", func(m *Markdown) {
+					m.Section("Install", install)
+				})
+			},
+			want: "<!-- BEGIN_TOC -->\n- [Guide](#guide)\n  - [Install](#install)\n    - [Linux](#linux)\n<!-- END_TOC -->\n\n" +
+				"# Guide\n## Install\nRun go install.\n### Linux",
+		},
+		"escaping": {
+			build: func(m *Markdown) *Markdown { return m.Section("Fix *all* #", nil) },
+			want:  `# Fix \*all\* \#`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := tt.build(NewMarkdown(nil, WithEscaping()))
+			if err := m.Error(); err != nil {
+				t.Fatalf("Error() = %v", err)
+			}
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), m.String()); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSectionTooDeep(t *testing.T) {
+	t.Parallel()
+
+	var nest func(depth int) func(*Markdown)
+	nest = func(depth int) func(*Markdown) {
+		return func(m *Markdown) {
+			if depth > 0 {
+				m.Section("Level", nest(depth-1))
+			}
+		}
+	}
+
+	m := NewMarkdown(nil)
+	nest(7)(m)
+	m.Section("After", nil)
+
+	err := m.Error()
+	if err == nil || !strings.Contains(err.Error(), "its heading would be H7") {
+		t.Errorf("Error() = %v, want the section past H6", err)
+	}
+	want := "# Level\n## Level\n### Level\n#### Level\n##### Level\n###### Level\n# After"
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
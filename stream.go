@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+)
+
+// stream is what a streaming document remembers between blocks: enough of the
+// block written last to decide the blank line before the next one, and no more.
+type stream struct {
+	// last is the block written last, its text cut down to the line ending
+	// needsBlankLine looks for.
+	last renderedBlock
+	// written reports whether a block has been written.
+	written bool
+	// endsWithLineFeed reports whether the output so far ends with a line feed.
+	endsWithLineFeed bool
+	// built is set by Build, which ends the document.
+	built bool
+	// failed is set once the writer refuses a write. Nothing is written after it.
+	failed bool
+	// headingIDs counts the headings written with each explicit ID.
+	headingIDs map[string]int
+}
+
+// WithStreaming writes every block to the writer as soon as it is added,
+// instead of keeping the document until Build. The blank lines between blocks
+// are the ones String would write, and so is the output as a whole, but only
+// the block being added is held in memory. That is the mode for a report with
+// hundreds of thousands of blocks.
+//
+// Features that need the whole document cannot work this way:
+// TableOfContents is recorded as ErrStreamingUnsupported. String, Blocks,
+// Walk, HTML and Lint see no blocks, since none are kept.
+//
+// Build ends the document: it writes the final line ending, and the front
+// matter if no block was added. A block added after Build is an error. A
+// writer that refuses a block is recorded once, and nothing more is written to
+// it. Build reports a nil writer, as it does without streaming.
+func WithStreaming() Option {
+	return func(m *Markdown) {
+		m.stream = &stream{headingIDs: map[string]int{}}
+	}
+}
+
+// streamBlock writes b after the blocks written before it.
+func (m *Markdown) streamBlock(b Block) {
+	s := m.stream
+	if s.built {
+		m.addError(fmt.Errorf("%w: a block added after Build", ErrStreamingUnsupported))
+		return
+	}
+
+	lf := internal.LineFeed()
+	block := m.blockRenderer().render([]Block{b})[0]
+
+	var buf strings.Builder
+	switch {
+	case s.written:
+		buf.WriteString(lf)
+		if needsBlankLine(s.last, block, m.blockSpacing) {
+			buf.WriteString(lf)
+		}
+	case m.frontMatter != "":
+		// String puts the blank line after the front matter unless the body
+		// opens with one.
+		buf.WriteString(m.frontMatter + lf)
+		if m.blockSpacing && block.text != "" {
+			buf.WriteString(lf)
+		}
+	}
+	buf.WriteString(block.text)
+	m.streamWrite(buf.String())
+
+	s.written = true
+	s.last = renderedBlock{kind: block.kind}
+	if strings.HasSuffix(block.text, lf) {
+		s.last.text = lf
+	}
+}
+
+// finishStream writes what Build adds to a streamed document.
+func (m *Markdown) finishStream() error {
+	s := m.stream
+	if !s.built {
+		// The footnotes and link definitions go at the end, as String puts them.
+		if definitions := m.definitions(); definitions != "" {
+			if s.written {
+				separator := internal.LineFeed() + internal.LineFeed()
+				if s.endsWithLineFeed {
+					separator = internal.LineFeed()
+				}
+				m.streamWrite(separator + definitions)
+			} else {
+				m.streamBlock(&Raw{Text: definitions})
+			}
+		}
+		s.built = true
+		out := ""
+		if !s.written {
+			out = m.frontMatter
+		}
+		if !s.endsWithLineFeed || out != "" {
+			out += internal.LineFeed()
+		}
+		m.streamWrite(out)
+	}
+	return m.err
+}
+
+// streamWrite writes text to the destination, recording the first failure.
+func (m *Markdown) streamWrite(text string) {
+	s := m.stream
+	if s.failed || m.dest == nil || text == "" {
+		return
+	}
+	if _, err := io.WriteString(m.dest, text); err != nil {
+		s.failed = true
+		m.addError(fmt.Errorf("failed to write markdown text: %w", err))
+		return
+	}
+	s.endsWithLineFeed = strings.HasSuffix(text, internal.LineFeed())
+}
@@ -0,0 +1,174 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStreamingWritesWhatBuildWould(t *testing.T) {
+	t.Parallel()
+
+	chain := func(m *Markdown) *Markdown {
+		return m.H1("Report").
+			Note("Generated nightly.").
+			PlainText("Summary").
+			BulletList("a", "b").
+			OrderedList("one").
+			Table(TableSet{Header: []string{"h"}, Rows: [][]string{{"c"}}}).
+			PlainText("after the table").
+			Blockquote("quote").
+			LF().
+			CodeBlocks(SyntaxHighlightGo, "x := 1").
+			BlankLine().
+			Details("More", "text").
+			HorizontalRule()
+	}
+
+	tests := map[string][]Option{
+		"default":                   nil,
+		"block spacing":             {WithBlockSpacing()},
+		"front matter":              {WithFrontMatter(map[string]string{"title": "Report"})},
+		"front matter with spacing": {WithBlockSpacing(), WithFrontMatter(map[string]string{"title": "Report"})},
+		"another dialect":           {WithDialect(DialectAzureDevOps)},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var want, got bytes.Buffer
+			if err := chain(NewMarkdown(&want, opts...)).Build(); err != nil {
+				t.Fatal(err)
+			}
+			if err := chain(NewMarkdown(&got, append(opts, WithStreaming())...)).Build(); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("streamed %q, want %q", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestStreamingEdges(t *testing.T) {
+	t.Parallel()
+
+	fm := WithFrontMatter(map[string]string{"title": "x"})
+	tests := map[string]struct {
+		opts  []Option
+		chain func(*Markdown) *Markdown
+	}{
+		"no blocks":                        {chain: func(m *Markdown) *Markdown { return m }},
+		"front matter only":                {opts: []Option{fm}, chain: func(m *Markdown) *Markdown { return m }},
+		"a blank line after front matter":  {opts: []Option{fm}, chain: func(m *Markdown) *Markdown { return m.BlankLine().H1("a") }},
+		"only a blank line":                {opts: []Option{fm}, chain: func(m *Markdown) *Markdown { return m.BlankLine() }},
+		"a table last":                     {chain: func(m *Markdown) *Markdown { return m.Table(TableSet{Header: []string{"h"}}) }},
+		"a blank line first, with spacing": {opts: []Option{fm, WithBlockSpacing()}, chain: func(m *Markdown) *Markdown { return m.BlankLine().H1("a") }},
+		"two kinds of list, one after another": {
+			chain: func(m *Markdown) *Markdown { return m.BulletList("a").BulletList("b").OrderedList("c") },
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var want, got bytes.Buffer
+			if err := tt.chain(NewMarkdown(&want, tt.opts...)).Build(); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.chain(NewMarkdown(&got, append(tt.opts, WithStreaming())...)).Build(); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("streamed %q, want %q", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestStreamingFlushesEveryBlock(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	m := NewMarkdown(&buf, WithStreaming()).H1("Title")
+	if got := buf.String(); got != "# Title" {
+		t.Errorf("after H1 the writer holds %q, want %q", got, "# Title")
+	}
+
+	m.PlainText("text")
+	if got, want := buf.String(), "# Title"+lf()+"text"; got != want {
+		t.Errorf("after PlainText the writer holds %q, want %q", got, want)
+	}
+	if m.BlockCount() != 0 || m.String() != "" {
+		t.Errorf("the blocks were kept: %q", m.String())
+	}
+
+	if err := m.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "# Title"+lf()+"text"+lf(); got != want {
+		t.Errorf("after Build the writer holds %q, want %q", got, want)
+	}
+}
+
+func TestStreamingErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a table of contents needs the whole document", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		m := NewMarkdown(&buf, WithStreaming()).H1("Title").TableOfContents(TableOfContentsDepthH2)
+		if err := m.Build(); !errors.Is(err, ErrStreamingUnsupported) {
+			t.Errorf("Build() = %v, want %v", err, ErrStreamingUnsupported)
+		}
+		if strings.Contains(buf.String(), TableOfContentsMarkerBegin) {
+			t.Errorf("the markers were written: %q", buf.String())
+		}
+	})
+
+	t.Run("a block after Build", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		m := NewMarkdown(&buf, WithStreaming()).H1("Title")
+		if err := m.Build(); err != nil {
+			t.Fatal(err)
+		}
+		m.PlainText("late")
+		if !errors.Is(m.Error(), ErrStreamingUnsupported) {
+			t.Errorf("Error() = %v, want %v", m.Error(), ErrStreamingUnsupported)
+		}
+		if strings.Contains(buf.String(), "late") {
+			t.Errorf("the late block was written: %q", buf.String())
+		}
+	})
+
+	t.Run("a failing writer is recorded once", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMarkdown(&failingWriter{}, WithStreaming()).H1("a").H1("b").H1("c")
+		err := m.Build()
+		if err == nil || !strings.Contains(err.Error(), "failed to write markdown text") {
+			t.Fatalf("Build() = %v, want the write error", err)
+		}
+		if n := strings.Count(err.Error(), "failed to write markdown text"); n != 1 {
+			t.Errorf("the write error was recorded %d times: %v", n, err)
+		}
+	})
+
+	t.Run("a nil writer", func(t *testing.T) {
+		t.Parallel()
+
+		err := NewMarkdown(nil, WithStreaming()).H1("Title").Build()
+		if err == nil || !strings.Contains(err.Error(), "destination writer is nil") {
+			t.Errorf("Build() = %v, want the nil writer error", err)
+		}
+	})
+}
@@ -110,6 +110,8 @@ func Code(text string) string {
 
 // Highlight return text with highlight format.
 // If you set text "Hello", it will be converted to "==Hello==".
+// GitHub does not read this syntax; [Dialect.Highlight] writes one that the
+// platform the document is for does.
 func Highlight(text string) string {
 	return fmt.Sprintf("==%s==", text)
 }
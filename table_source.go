@@ -0,0 +1,259 @@
+package markdown
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TableReadOption configures how TableSetFromCSV, TableSetFromTSV and
+// TableSetFromSQLRows read their rows.
+type TableReadOption func(*tableReadConfig)
+
+// tableReadConfig is what the TableReadOptions set.
+type tableReadConfig struct {
+	// maxRows is the number of rows kept, or 0 for all of them.
+	maxRows int
+	// note is the format of the row that stands for the rows left out.
+	note string
+	// err is the error an option ran into.
+	err error
+}
+
+// defaultTruncationNote is the note row written unless WithTruncationNote
+// replaces it.
+const defaultTruncationNote = "… %d more rows"
+
+// WithMaxRows keeps the first n rows and leaves the rest out, adding a note row
+// that says how many were left out. The remaining input is still read, to
+// count it, but not kept. Zero or less keeps every row.
+func WithMaxRows(n int) TableReadOption {
+	return func(c *tableReadConfig) {
+		c.maxRows = max(n, 0)
+	}
+}
+
+// WithTruncationNote replaces the text of the note row WithMaxRows adds. The
+// format gets the number of rows left out, as "… %d more rows" does, and is
+// written in the first cell. An empty format adds no note row.
+//
+// The format holds %d exactly once and no other verb; "%%" writes a percent
+// sign. Any other format is an error, which the table reader returns.
+func WithTruncationNote(format string) TableReadOption {
+	return func(c *tableReadConfig) {
+		if format != "" && !oneCount(format) {
+			c.err = fmt.Errorf("invalid truncation note %q: it must hold %%d exactly once and no other verb", format)
+			return
+		}
+		c.note = format
+	}
+}
+
+// oneCount reports whether the only verb of format is a single %d.
+func oneCount(format string) bool {
+	counts := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		switch {
+		case i+1 < len(format) && format[i+1] == '%':
+		case i+1 < len(format) && format[i+1] == 'd':
+			counts++
+		default:
+			return false
+		}
+		i++
+	}
+	return counts == 1
+}
+
+// TableSetFromCSV reads a table from CSV: the first record is the header and
+// each record after it is a row. r is an encoding/csv reader, so the caller
+// sets the separator, comments and quoting; a record with more or fewer fields
+// than the header is an error unless the reader allows that, and then it is
+// padded or cut to the header.
+//
+// CSV is data from elsewhere, so the table has EscapeCells set. Set it to false
+// on the result to keep markup the cells hold on purpose.
+func TableSetFromCSV(r *csv.Reader, opts ...TableReadOption) (TableSet, error) {
+	header, err := r.Read()
+	if err != nil {
+		return TableSet{}, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	t := newTableReader(header, opts)
+	if t.err != nil {
+		return TableSet{}, t.err
+	}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return TableSet{}, fmt.Errorf("failed to read csv: %w", err)
+		}
+		t.add(record)
+	}
+	return t.table()
+}
+
+// TableSetFromTSV reads a table from tab-separated values: the first line is
+// the header, each line after it a row, and every field is separated by one
+// tab. TSV has no quoting, so a field is taken as it is, quotes included. A
+// line with more or fewer fields than the header is an error wrapping
+// ErrMismatchColumn; an empty line is skipped.
+//
+// The table has EscapeCells set, as TableSetFromCSV does.
+func TableSetFromTSV(r io.Reader, opts ...TableReadOption) (TableSet, error) {
+	br := bufio.NewReader(r)
+
+	var t *tableReader
+	for line := 1; ; line++ {
+		text, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return TableSet{}, fmt.Errorf("failed to read tsv: %w", err)
+		}
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+		if text != "" {
+			fields := strings.Split(text, "\t")
+			switch {
+			case t == nil:
+				t = newTableReader(fields, opts)
+				if t.err != nil {
+					return TableSet{}, t.err
+				}
+			case len(fields) != len(t.header):
+				return TableSet{}, fmt.Errorf("tsv line %d has %d fields, the header %d: %w", line, len(fields), len(t.header), ErrMismatchColumn)
+			default:
+				t.add(fields)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	if t == nil {
+		return TableSet{}, errors.New("failed to read tsv header: the input is empty")
+	}
+	return t.table()
+}
+
+// TableSetFromSQLRows reads a table from the result of a query: the column
+// names are the header and each row a row. It reads the rows to the end and
+// closes them.
+//
+// A NULL is an empty cell, bytes are written as text, a time as RFC 3339, and
+// anything else with fmt.Sprint. The table has EscapeCells set, as
+// TableSetFromCSV does.
+func TableSetFromSQLRows(rows *sql.Rows, opts ...TableReadOption) (TableSet, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return TableSet{}, fmt.Errorf("failed to read sql columns: %w", err)
+	}
+
+	t := newTableReader(columns, opts)
+	if t.err != nil {
+		return TableSet{}, t.err
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if t.full() {
+			t.skipped++
+			continue
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return TableSet{}, fmt.Errorf("failed to scan sql row: %w", err)
+		}
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = sqlCell(v)
+		}
+		t.add(cells)
+	}
+	if err := rows.Err(); err != nil {
+		return TableSet{}, fmt.Errorf("failed to read sql rows: %w", err)
+	}
+	return t.table()
+}
+
+// sqlCell writes one scanned value.
+func sqlCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// tableReader collects the rows of a table read from elsewhere, keeping the
+// ones WithMaxRows allows and counting the rest.
+type tableReader struct {
+	tableReadConfig
+	header  []string
+	rows    [][]string
+	skipped int
+}
+
+// newTableReader returns a reader for a table with this header.
+func newTableReader(header []string, opts []TableReadOption) *tableReader {
+	t := &tableReader{
+		tableReadConfig: tableReadConfig{note: defaultTruncationNote},
+		header:          append([]string{}, header...),
+	}
+	for _, opt := range opts {
+		opt(&t.tableReadConfig)
+	}
+	return t
+}
+
+// full reports whether every row from now on is left out.
+func (t *tableReader) full() bool {
+	return t.maxRows > 0 && len(t.rows) >= t.maxRows
+}
+
+// add keeps a row, padded or cut to the header, or counts it as left out.
+func (t *tableReader) add(record []string) {
+	if t.full() {
+		t.skipped++
+		return
+	}
+	row := make([]string, len(t.header))
+	copy(row, record)
+	t.rows = append(t.rows, row)
+}
+
+// table returns the rows read, with the note row when rows were left out, or
+// the error an option ran into.
+func (t *tableReader) table() (TableSet, error) {
+	if t.err != nil {
+		return TableSet{}, t.err
+	}
+	rows := t.rows
+	if rows == nil {
+		rows = [][]string{}
+	}
+	if t.skipped > 0 && t.note != "" && len(t.header) > 0 {
+		note := make([]string, len(t.header))
+		note[0] = fmt.Sprintf(t.note, t.skipped)
+		rows = append(rows, note)
+	}
+	return TableSet{Header: t.header, Rows: rows, EscapeCells: true}, nil
+}
@@ -0,0 +1,427 @@
+package markdown
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testDriverName is a database/sql driver that answers every query with the
+// table its data source name spells out: the column names on the first line
+// and a row on each line after it, with the values separated by tabs. NULL is
+// a NULL, and a line reading ERROR fails the read there.
+const testDriverName = "markdown-test"
+
+func init() { //nolint:gochecknoinits // a driver is registered once per binary
+	sql.Register(testDriverName, testDriver{})
+}
+
+type testDriver struct{}
+
+func (testDriver) Open(dsn string) (driver.Conn, error) { return testConn{dsn: dsn}, nil }
+
+type testConn struct{ dsn string }
+
+func (c testConn) Prepare(string) (driver.Stmt, error) { return testStmt(c), nil }
+func (testConn) Close() error                          { return nil }
+func (testConn) Begin() (driver.Tx, error)             { return nil, errors.New("not supported") }
+
+type testStmt struct{ dsn string }
+
+func (testStmt) Close() error                               { return nil }
+func (testStmt) NumInput() int                              { return -1 }
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("not supported") }
+
+func (s testStmt) Query([]driver.Value) (driver.Rows, error) {
+	lines := strings.Split(s.dsn, "\n")
+	return &testRows{columns: strings.Split(lines[0], "\t"), lines: lines[1:]}, nil
+}
+
+type testRows struct {
+	columns []string
+	lines   []string
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.lines) == 0 {
+		return io.EOF
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	if line == "ERROR" {
+		return errors.New("connection lost")
+	}
+	for i, v := range strings.Split(line, "\t") {
+		if v == "NULL" {
+			dest[i] = nil
+			continue
+		}
+		dest[i] = []byte(v)
+	}
+	return nil
+}
+
+// queryTestRows returns the rows the test driver answers for this table.
+func queryTestRows(t *testing.T, table string) *sql.Rows {
+	t.Helper()
+
+	db, err := sql.Open(testDriverName, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestTableSetFromCSV(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+		opts  []TableReadOption
+		want  TableSet
+	}{
+		"a header and rows": {
+			input: "name,note\nana,\"a, b\"\nbo,<b>x</b>\n",
+			want: TableSet{
+				Header:      []string{"name", "note"},
+				Rows:        [][]string{{"ana", "a, b"}, {"bo", "<b>x</b>"}},
+				EscapeCells: true,
+			},
+		},
+		"only a header": {
+			input: "name,note\n",
+			want:  TableSet{Header: []string{"name", "note"}, Rows: [][]string{}, EscapeCells: true},
+		},
+		"more rows than kept": {
+			input: "n\n1\n2\n3\n4\n",
+			opts:  []TableReadOption{WithMaxRows(2)},
+			want: TableSet{
+				Header:      []string{"n"},
+				Rows:        [][]string{{"1"}, {"2"}, {"… 2 more rows"}},
+				EscapeCells: true,
+			},
+		},
+		"exactly as many rows as kept": {
+			input: "n\n1\n2\n",
+			opts:  []TableReadOption{WithMaxRows(2)},
+			want:  TableSet{Header: []string{"n"}, Rows: [][]string{{"1"}, {"2"}}, EscapeCells: true},
+		},
+		"a note of its own": {
+			input: "n,m\n1,a\n2,b\n",
+			opts:  []TableReadOption{WithMaxRows(1), WithTruncationNote("(%d rows not shown)")},
+			want: TableSet{
+				Header:      []string{"n", "m"},
+				Rows:        [][]string{{"1", "a"}, {"(1 rows not shown)", ""}},
+				EscapeCells: true,
+			},
+		},
+		"no note": {
+			input: "n\n1\n2\n",
+			opts:  []TableReadOption{WithMaxRows(1), WithTruncationNote("")},
+			want:  TableSet{Header: []string{"n"}, Rows: [][]string{{"1"}}, EscapeCells: true},
+		},
+		"zero keeps every row": {
+			input: "n\n1\n2\n",
+			opts:  []TableReadOption{WithMaxRows(0)},
+			want:  TableSet{Header: []string{"n"}, Rows: [][]string{{"1"}, {"2"}}, EscapeCells: true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := TableSetFromCSV(csv.NewReader(strings.NewReader(tt.input)), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("a reader that allows ragged records", func(t *testing.T) {
+		t.Parallel()
+
+		r := csv.NewReader(strings.NewReader("a;b\n1\n1;2;3\n"))
+		r.Comma = ';'
+		r.FieldsPerRecord = -1
+		got, err := TableSetFromCSV(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([][]string{{"1", ""}, {"1", "2"}}, got.Rows); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the cells are escaped when written", func(t *testing.T) {
+		t.Parallel()
+
+		table, err := TableSetFromCSV(csv.NewReader(strings.NewReader("a\nx | y\n")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out := NewMarkdown(nil).Table(table).String(); !strings.Contains(out, `x \| y`) {
+			t.Errorf("the pipe in a cell is not escaped:\n%s", out)
+		}
+	})
+}
+
+func TestTableSetFromCSVErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, input := range map[string]string{
+		"empty input":       "",
+		"a ragged record":   "a,b\n1\n",
+		"a broken quote":    "a\n\"x\n",
+		"a ragged late row": "a\n1\n2\n3,4\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := TableSetFromCSV(csv.NewReader(strings.NewReader(input)), WithMaxRows(1)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestWithTruncationNote(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		format string
+		want   string
+	}{
+		"a count":            {format: "%d hidden", want: "1 hidden"},
+		"a percent sign":     {format: "100%% of %d", want: "100% of 1"},
+		"no count":           {format: "more rows"},
+		"two counts":         {format: "%d of %d"},
+		"another verb":       {format: "%s more"},
+		"a trailing sign":    {format: "%d more %"},
+		"a count with flags": {format: "%3d more"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := TableSetFromCSV(csv.NewReader(strings.NewReader("n\n1\n2\n")), WithMaxRows(1), WithTruncationNote(tt.format))
+			if tt.want == "" {
+				if err == nil || !strings.Contains(err.Error(), "invalid truncation note") {
+					t.Errorf("got %v, %v, want an invalid truncation note error", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([][]string{{"1"}, {tt.want}}, got.Rows); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTableReadersStopAtAnInvalidOption(t *testing.T) {
+	t.Parallel()
+
+	// The input goes bad after the header, so only an option checked before
+	// the rows are read gives the option's error.
+	opt := WithTruncationNote("%s more")
+	read := map[string]func(t *testing.T) (TableSet, error){
+		"csv": func(*testing.T) (TableSet, error) {
+			return TableSetFromCSV(csv.NewReader(strings.NewReader("a\n\"x\n")), opt)
+		},
+		"tsv": func(*testing.T) (TableSet, error) { return TableSetFromTSV(strings.NewReader("a\n1\t2\n"), opt) },
+		"sql": func(t *testing.T) (TableSet, error) { return TableSetFromSQLRows(queryTestRows(t, "a\nERROR"), opt) },
+	}
+	for name, read := range read {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := read(t); err == nil || !strings.Contains(err.Error(), "invalid truncation note") {
+				t.Errorf("got %v, want an invalid truncation note error", err)
+			}
+		})
+	}
+}
+
+func TestTableSetFromTSV(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+		opts  []TableReadOption
+		want  TableSet
+	}{
+		"a header and rows": {
+			input: "name\tnote\nana\t\"quoted\"\nbo\t\n",
+			want: TableSet{
+				Header:      []string{"name", "note"},
+				Rows:        [][]string{{"ana", `"quoted"`}, {"bo", ""}},
+				EscapeCells: true,
+			},
+		},
+		"CRLF and no final line break": {
+			input: "a\tb\r\n1\t2\r\n\r\n3\t4",
+			want: TableSet{
+				Header:      []string{"a", "b"},
+				Rows:        [][]string{{"1", "2"}, {"3", "4"}},
+				EscapeCells: true,
+			},
+		},
+		"more rows than kept": {
+			input: "n\n1\n2\n3\n",
+			opts:  []TableReadOption{WithMaxRows(1)},
+			want: TableSet{
+				Header:      []string{"n"},
+				Rows:        [][]string{{"1"}, {"… 2 more rows"}},
+				EscapeCells: true,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := TableSetFromTSV(strings.NewReader(tt.input), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("a ragged line", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := TableSetFromTSV(strings.NewReader("a\tb\n1\t2\n3\n"))
+		if !errors.Is(err, ErrMismatchColumn) {
+			t.Errorf("got %v, want ErrMismatchColumn", err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "line 3") {
+			t.Errorf("the error does not name the line: %v", err)
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := TableSetFromTSV(strings.NewReader("\n")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("a failing reader", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := TableSetFromTSV(io.MultiReader(strings.NewReader("a\n"), iotestErrReader{})); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+// iotestErrReader fails every read.
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) { return 0, errors.New("disk on fire") }
+
+func TestTableSetFromSQLRows(t *testing.T) {
+	t.Parallel()
+
+	t.Run("column names and values", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := TableSetFromSQLRows(queryTestRows(t, "id\tname\n1\tana\n2\tNULL"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := TableSet{
+			Header:      []string{"id", "name"},
+			Rows:        [][]string{{"1", "ana"}, {"2", ""}},
+			EscapeCells: true,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("more rows than kept", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := TableSetFromSQLRows(queryTestRows(t, "n\n1\n2\n3"), WithMaxRows(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([][]string{{"1"}, {"… 2 more rows"}}, got.Rows); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("a failing read", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := TableSetFromSQLRows(queryTestRows(t, "n\n1\nERROR")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("the rows are closed", func(t *testing.T) {
+		t.Parallel()
+
+		rows := queryTestRows(t, "n\n1\n2")
+		if _, err := TableSetFromSQLRows(rows, WithMaxRows(1)); err != nil {
+			t.Fatal(err)
+		}
+		if rows.Next() {
+			t.Error("the rows are still open")
+		}
+	})
+}
+
+func TestSQLCell(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value any
+		want  string
+	}{
+		"NULL":     {value: nil, want: ""},
+		"bytes":    {value: []byte("x"), want: "x"},
+		"a time":   {value: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC), want: "2024-05-01T09:30:00Z"},
+		"a number": {value: int64(42), want: "42"},
+		"a bool":   {value: true, want: "true"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := sqlCell(tt.value); got != tt.want {
+				t.Errorf("sqlCell(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
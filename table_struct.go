@@ -0,0 +1,260 @@
+package markdown
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TableSetFromStructs builds a table from a slice (or array) of structs, or of
+// pointers to structs: one column per exported field, one row per element.
+//
+// The md struct tag names and shapes a column:
+//
+//	type Job struct {
+//		Name     string        `md:"Job"`
+//		Duration time.Duration `md:"Took,align=right"`
+//		Cost     float64       `md:"Cost ($),align=right,format=%.2f"`
+//		Note     string        `md:",omitempty"`
+//		internal string        // unexported, skipped
+//		Debug    bool          `md:"-"` // skipped
+//	}
+//
+// The name before the first comma is the header; an empty one is the field
+// name. The options after it are:
+//
+//   - align=left, align=center or align=right sets the column's alignment.
+//   - omitempty leaves the cell empty when the value is the zero value.
+//   - format= is the fmt verb the value is written with, or, for a
+//     time.Time, the layout. It takes the rest of the tag, commas included,
+//     so it comes last.
+//
+// A value is written with its Error or String method when it has one, and
+// with fmt.Sprint otherwise; a time.Time without a format is written as RFC 3339,
+// and a nil pointer, interface, slice or map is an empty cell. The fields of an
+// embedded struct without a tag are columns of their own, as encoding/json
+// does it.
+//
+// Struct data is rarely markup and often comes from elsewhere, so the table
+// has EscapeCells set. The result works with both Table and CustomTable.
+func TableSetFromStructs(rows any) (TableSet, error) {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return TableSet{}, fmt.Errorf("table rows must be a slice of structs, got %T", rows)
+	}
+	elem := rv.Type().Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return TableSet{}, fmt.Errorf("table rows must be a slice of structs, got %T", rows)
+	}
+
+	columns, err := structColumns(elem, nil, map[reflect.Type]bool{})
+	if err != nil {
+		return TableSet{}, err
+	}
+
+	t := TableSet{
+		Header:      make([]string, 0, len(columns)),
+		Rows:        make([][]string, 0, rv.Len()),
+		Alignment:   make([]TableAlignment, 0, len(columns)),
+		EscapeCells: true,
+	}
+	for _, c := range columns {
+		t.Header = append(t.Header, c.name)
+		t.Alignment = append(t.Alignment, c.align)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		row := rv.Index(i)
+		for row.Kind() == reflect.Pointer && !row.IsNil() {
+			row = row.Elem()
+		}
+		cells := make([]string, 0, len(columns))
+		for _, c := range columns {
+			cells = append(cells, c.cell(row))
+		}
+		t.Rows = append(t.Rows, cells)
+	}
+	return t, nil
+}
+
+// structColumn is one column of a table built from structs.
+type structColumn struct {
+	// name is the header of the column.
+	name string
+	// index is the path to the field, as reflect.Value.FieldByIndex takes it.
+	index []int
+	// align is the alignment of the column.
+	align TableAlignment
+	// omitEmpty leaves the cell empty for a zero value.
+	omitEmpty bool
+	// format is the fmt verb, or the time layout, the value is written with.
+	format string
+}
+
+// structColumns returns the columns of a struct type, in field order, with
+// the fields of untagged embedded structs in place. An embedded struct of a
+// type that embeds it already, such as a *Node in a Node, is skipped, since its
+// fields would be in place forever; embedding holds the types that do.
+func structColumns(t reflect.Type, index []int, embedding map[reflect.Type]bool) ([]structColumn, error) {
+	embedding[t] = true
+	defer delete(embedding, t)
+
+	columns := []structColumn{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("md")
+		if tag == "-" {
+			continue
+		}
+		path := append(append([]int{}, index...), i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !tagged && ft.Kind() == reflect.Struct {
+			if embedding[ft] {
+				continue
+			}
+			embedded, err := structColumns(ft, path, embedding)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, embedded...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		c, err := parseStructTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if c.name == "" {
+			c.name = f.Name
+		}
+		c.index = path
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// parseStructTag reads the md tag of a field.
+func parseStructTag(tag string) (structColumn, error) {
+	parts := strings.Split(tag, ",")
+	c := structColumn{name: parts[0]}
+	for i := 1; i < len(parts); i++ {
+		part := parts[i]
+		switch {
+		case part == "omitempty":
+			c.omitEmpty = true
+		case strings.HasPrefix(part, "align="):
+			align, err := parseAlignment(strings.TrimPrefix(part, "align="))
+			if err != nil {
+				return structColumn{}, err
+			}
+			c.align = align
+		case strings.HasPrefix(part, "format="):
+			c.format = strings.TrimPrefix(strings.Join(parts[i:], ","), "format=")
+			return c, nil
+		case part == "":
+		default:
+			return structColumn{}, fmt.Errorf("unknown md tag option %q", part)
+		}
+	}
+	return c, nil
+}
+
+// parseAlignment reads the value of an align option.
+func parseAlignment(s string) (TableAlignment, error) {
+	switch s {
+	case "", "default":
+		return AlignDefault, nil
+	case "left":
+		return AlignLeft, nil
+	case "center":
+		return AlignCenter, nil
+	case "right":
+		return AlignRight, nil
+	}
+	return AlignDefault, fmt.Errorf("unknown alignment %q (must be left, center or right)", s)
+}
+
+// cell returns the text of the column for one row.
+func (c structColumn) cell(row reflect.Value) string {
+	if row.Kind() != reflect.Struct {
+		// A nil element of a slice of pointers.
+		return ""
+	}
+	v, ok := fieldByIndex(row, c.index)
+	if !ok || isNil(v) {
+		return ""
+	}
+	if c.omitEmpty && v.IsZero() {
+		return ""
+	}
+	return formatCell(v, c.format)
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, reporting a nil embedded
+// pointer on the way instead of panicking.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isNil reports whether v holds nothing to write.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// formatCell writes a value the way TableSetFromStructs documents.
+func formatCell(v reflect.Value, format string) string {
+	if !v.CanInterface() {
+		return ""
+	}
+	// An Error or String method may be declared on the pointer, so the value
+	// as the field holds it is asked first.
+	held := v.Interface()
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	value := v.Interface()
+
+	if t, ok := value.(time.Time); ok {
+		if format == "" {
+			return t.Format(time.RFC3339)
+		}
+		return t.Format(format)
+	}
+	if format != "" {
+		return fmt.Sprintf(format, value)
+	}
+	switch held := held.(type) {
+	case error:
+		return held.Error()
+	case fmt.Stringer:
+		return held.String()
+	}
+	if s, ok := value.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(value)
+}
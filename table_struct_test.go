@@ -0,0 +1,223 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testStatus int
+
+func (s testStatus) String() string {
+	if s == 0 {
+		return "ok"
+	}
+	return "failed"
+}
+
+type testPointerStringer struct{ name string }
+
+func (p *testPointerStringer) String() string { return "<" + p.name + ">" }
+
+type testAudit struct {
+	Owner string
+}
+
+type testJob struct {
+	testAudit
+	Name     string        `md:"Job"`
+	Took     time.Duration `md:"Took,align=right"`
+	Cost     float64       `md:"Cost ($),align=right,format=%.2f"`
+	Started  time.Time     `md:",align=center,format=2006-01-02 15:04"`
+	Status   testStatus    `md:"Status"`
+	Note     string        `md:",omitempty"`
+	Retries  *int
+	Ref      *testPointerStringer
+	Err      error
+	internal string //nolint:unused // unexported, skipped
+	Debug    bool   `md:"-"`
+}
+
+func TestTableSetFromStructs(t *testing.T) {
+	t.Parallel()
+
+	retries := 2
+	started := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	jobs := []*testJob{
+		{
+			testAudit: testAudit{Owner: "ana"},
+			Name:      "lint | vet",
+			Took:      90 * time.Second,
+			Cost:      1.5,
+			Started:   started,
+			Note:      "cached",
+			Retries:   &retries,
+			Ref:       &testPointerStringer{name: "r1"},
+			Err:       errors.New("exit 1"),
+		},
+		{Name: "test", Status: 1},
+		nil,
+	}
+
+	got, err := TableSetFromStructs(jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := TableSet{
+		Header:    []string{"Owner", "Job", "Took", "Cost ($)", "Started", "Status", "Note", "Retries", "Ref", "Err"},
+		Alignment: []TableAlignment{AlignDefault, AlignDefault, AlignRight, AlignRight, AlignCenter, AlignDefault, AlignDefault, AlignDefault, AlignDefault, AlignDefault},
+		Rows: [][]string{
+			{"ana", "lint | vet", "1m30s", "1.50", "2024-05-01 09:30", "ok", "cached", "2", "<r1>", "exit 1"},
+			{"", "test", "0s", "0.00", "0001-01-01 00:00", "failed", "", "", "", ""},
+			{"", "", "", "", "", "", "", "", "", ""},
+		},
+		EscapeCells: true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("works with Table and CustomTable", func(t *testing.T) {
+		t.Parallel()
+
+		for _, m := range []*Markdown{
+			NewMarkdown(nil).Table(got),
+			NewMarkdown(nil).CustomTable(got, TableOptions{}),
+		} {
+			if err := m.Error(); err != nil {
+				t.Fatal(err)
+			}
+			if out := m.String(); !strings.Contains(out, `lint \| vet`) {
+				t.Errorf("the pipe in a cell is not escaped:\n%s", out)
+			}
+		}
+	})
+}
+
+func TestTableSetFromStructsShapes(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		When time.Time
+		N    int `md:"n,format=%03d"`
+	}
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("a slice of values", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := TableSetFromStructs([]row{{When: when, N: 7}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([][]string{{"2024-01-02T03:04:05Z", "007"}}, got.Rows); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("an empty slice still has a header", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := TableSetFromStructs([]row{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"When", "n"}, got.Header); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("an array", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := TableSetFromStructs([1]row{{N: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Rows) != 1 {
+			t.Errorf("got %d rows, want 1", len(got.Rows))
+		}
+	})
+
+	t.Run("a format holding a comma", func(t *testing.T) {
+		t.Parallel()
+
+		type money struct {
+			Amount float64 `md:"Amount,format=%.1f, net"`
+		}
+		got, err := TableSetFromStructs([]money{{Amount: 2}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Rows[0][0] != "2.0, net" {
+			t.Errorf("cell = %q, want %q", got.Rows[0][0], "2.0, net")
+		}
+	})
+
+	t.Run("a nil embedded pointer", func(t *testing.T) {
+		t.Parallel()
+
+		type withPointer struct {
+			*testAudit
+			Name string
+		}
+		got, err := TableSetFromStructs([]withPointer{{Name: "x"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([][]string{{"", "x"}}, got.Rows); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("a struct that embeds itself", func(t *testing.T) {
+		t.Parallel()
+
+		type node struct {
+			*node
+			Name string
+		}
+		got, err := TableSetFromStructs([]node{{node: &node{Name: "parent"}, Name: "x"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"Name"}, got.Header); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([][]string{{"x"}}, got.Rows); diff != "" {
+			t.Errorf("value is mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestTableSetFromStructsErrors(t *testing.T) {
+	t.Parallel()
+
+	type badAlign struct {
+		A int `md:"A,align=middle"`
+	}
+	type badOption struct {
+		A int `md:"A,sortable"`
+	}
+
+	for name, rows := range map[string]any{
+		"not a slice":         testJob{},
+		"a slice of strings":  []string{"a"},
+		"nil":                 nil,
+		"an unknown align":    []badAlign{},
+		"an unknown option":   []badOption{},
+		"a slice of pointers": []*string{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := TableSetFromStructs(rows); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package markdown
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// FuncMap returns the functions a text/template needs to write the generated
+// parts of a document: tables, diagrams, alerts and badges, the inline markup
+// of this package, and its escaping helpers.
+//
+// The functions write markdown the way the builder would, in its dialect and
+// with its options, WithEscaping included. A block comes back without a line
+// feed at the end, so it belongs on a line of its own in the template. An
+// error a function runs into is recorded by m, as the chain records its own,
+// and Build reports it; the template goes on.
+//
+//	table          a TableSet, or a slice of structs as TableSetFromStructs reads it
+//	codeBlock      a language and the code, written as a fenced block
+//	mermaid        a diagram, or its text, written as a mermaid code block
+//	details        a summary and the collapsed text
+//	note, tip, important, warning, caution
+//	               an alert with the text
+//	badge          one or more Badge values, written side by side
+//	redBadge, yellowBadge, greenBadge, blueBadge
+//	               a badge with the text
+//	link, image, bold, italic, boldItalic, strikethrough, code, highlight, inlineMath
+//	               the inline markup of Link, Image, Bold and so on
+//	escapeHeading, escapeParagraph, escapeListItem, escapeLinkText, escapeTableCell
+//	               the escaping helpers of the same names
+//
+// A mermaid diagram is anything with a String method, such as the builders of
+// the mermaid packages; one whose Error method returns an error has it
+// recorded.
+func (m *Markdown) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"table": m.templateTable,
+		"codeBlock": func(lang, code string) string {
+			return m.templateBlock(func(f *Markdown) { f.CodeBlocks(SyntaxHighlight(lang), code) })
+		},
+		"mermaid": m.templateMermaid,
+		"details": func(summary, text string) string {
+			return m.templateBlock(func(f *Markdown) { f.Details(summary, text) })
+		},
+		"note":      func(text string) string { return m.templateBlock(func(f *Markdown) { f.Note(text) }) },
+		"tip":       func(text string) string { return m.templateBlock(func(f *Markdown) { f.Tip(text) }) },
+		"important": func(text string) string { return m.templateBlock(func(f *Markdown) { f.Important(text) }) },
+		"warning":   func(text string) string { return m.templateBlock(func(f *Markdown) { f.Warning(text) }) },
+		"caution":   func(text string) string { return m.templateBlock(func(f *Markdown) { f.Caution(text) }) },
+
+		"badge": func(badges ...Badge) string {
+			return m.templateBlock(func(f *Markdown) { f.Badges(badges...) })
+		},
+		"redBadge":    func(text string) string { return m.templateBlock(func(f *Markdown) { f.RedBadge(text) }) },
+		"yellowBadge": func(text string) string { return m.templateBlock(func(f *Markdown) { f.YellowBadge(text) }) },
+		"greenBadge":  func(text string) string { return m.templateBlock(func(f *Markdown) { f.GreenBadge(text) }) },
+		"blueBadge":   func(text string) string { return m.templateBlock(func(f *Markdown) { f.BlueBadge(text) }) },
+
+		"link":          Link,
+		"image":         Image,
+		"bold":          Bold,
+		"italic":        Italic,
+		"boldItalic":    BoldItalic,
+		"strikethrough": Strikethrough,
+		"code":          Code,
+		"highlight":     Highlight,
+		"inlineMath":    InlineMath,
+
+		"escapeHeading":   EscapeHeading,
+		"escapeParagraph": EscapeParagraph,
+		"escapeListItem":  EscapeListItem,
+		"escapeLinkText":  EscapeLinkText,
+		"escapeTableCell": EscapeTableCell,
+	}
+}
+
+// Template executes the text/template text with data and appends what it
+// writes, with the functions of FuncMap at hand, so that a document can be
+// mostly prose in a template and partly built by the chain:
+//
+//	md.NewMarkdown(w).
+//		H1("Release notes").
+//		Template(notes, release).
+//		Table(changes).
+//		Build()
+//
+// What the template writes is read the way Parse reads a document: its
+// headings are headings to TableOfContents, and its text is written as the
+// template wrote it. An error parsing or executing the template is recorded
+// and nothing is appended.
+func (m *Markdown) Template(text string, data any) *Markdown {
+	return m.executeTemplate("markdown", text, data)
+}
+
+// TemplateFile is Template with the template read from the file at path.
+func (m *Markdown) TemplateFile(path string, data any) *Markdown {
+	text, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		m.addError(fmt.Errorf("failed to read template: %w", err))
+		return m
+	}
+	return m.executeTemplate(filepath.Base(path), string(text), data)
+}
+
+// executeTemplate executes the template and appends the blocks it writes.
+func (m *Markdown) executeTemplate(name, text string, data any) *Markdown {
+	tmpl, err := template.New(name).Funcs(m.FuncMap()).Parse(text)
+	if err != nil {
+		m.addError(fmt.Errorf("failed to parse template: %w", err))
+		return m
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		m.addError(fmt.Errorf("failed to execute template: %w", err))
+		return m
+	}
+
+	// The blank lines a template leaves around its actions are not blocks.
+	if body := strings.Trim(strings.ReplaceAll(out.String(), "\r\n", "\n"), "\n"); body != "" {
+		m.parse(body + "\n")
+	}
+	return m
+}
+
+// fragment returns an empty builder that writes blocks the way m does, for
+// writing one block as text.
+func (m *Markdown) fragment() *Markdown {
+	return &Markdown{
+		body:           []Block{},
+		blockSpacing:   m.blockSpacing,
+		dialect:        m.dialect,
+		headingIDStyle: m.headingIDStyle,
+		escaping:       m.escaping,
+		lineWidth:      m.lineWidth,
+		sectionLevel:   m.sectionLevel,
+	}
+}
+
+// templateBlock returns the blocks build adds to a fragment as text, and
+// records the error it recorded.
+func (m *Markdown) templateBlock(build func(f *Markdown)) string {
+	f := m.fragment()
+	build(f)
+	m.addError(f.err)
+	return strings.TrimRight(f.String(), "\r\n")
+}
+
+// templateTable writes a TableSet, or a slice of structs, as a table.
+func (m *Markdown) templateTable(data any) string {
+	var t TableSet
+	switch data := data.(type) {
+	case TableSet:
+		t = data
+	case *TableSet:
+		t = *data
+	default:
+		var err error
+		if t, err = TableSetFromStructs(data); err != nil {
+			m.addError(err)
+			return ""
+		}
+	}
+	return m.templateBlock(func(f *Markdown) { f.Table(t) })
+}
+
+// diagram is a mermaid builder: its String method returns the diagram, and
+// its Error method the error its chain recorded.
+type diagram interface {
+	String() string
+	Error() error
+}
+
+// templateMermaid writes a diagram, or its text, as a mermaid code block.
+func (m *Markdown) templateMermaid(d any) string {
+	var text string
+	switch d := d.(type) {
+	case string:
+		text = d
+	case diagram:
+		m.addError(d.Error())
+		text = d.String()
+	case fmt.Stringer:
+		text = d.String()
+	default:
+		m.addError(fmt.Errorf("mermaid: %T is neither a diagram nor its text", d))
+		return ""
+	}
+	return m.templateBlock(func(f *Markdown) { f.CodeBlocks(SyntaxHighlightMermaid, text) })
+}
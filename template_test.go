@@ -0,0 +1,227 @@
+package markdown
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// templateDiagram stands in for a mermaid builder.
+type templateDiagram struct {
+	err error
+}
+
+func (d templateDiagram) String() string { return "graph TD\n  A-->B" }
+
+func (d templateDiagram) Error() error { return d.err }
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	type job struct {
+		Name string
+		Took int `md:",align=right"`
+	}
+
+	tests := map[string]struct {
+		opts []Option
+		text string
+		data any
+		want string
+	}{
+		"prose": {
+			text: "Hello, {{ . }}.\n",
+			data: "world",
+			want: "Hello, world.",
+		},
+		"blank lines around the output": {
+			text: "\n\n{{ bold . }}\n\n\n",
+			data: "text",
+			want: "**text**",
+		},
+		"inline markup": {
+			text: `{{ link "Go" "https://go.dev" }} {{ image "logo" "logo.png" }} {{ italic "i" }} {{ code "x" }}`,
+			want: "[Go](https://go.dev) ![logo](logo.png) *i* `x`",
+		},
+		"escaping": {
+			text: "## {{ escapeHeading . }}",
+			data: "Fix *all* #",
+			want: `## Fix \*all\* \#`,
+		},
+		"table from structs": {
+			text: "{{ table . }}",
+			data: []job{{Name: "build", Took: 3}},
+			want: "| Name | Took |\n|---------|--------:|\n| build | 3 |",
+		},
+		"table set": {
+			text: "{{ table . }}",
+			data: TableSet{Header: []string{"a"}, Rows: [][]string{{"1"}}},
+			want: "| a |\n|---------|\n| 1 |",
+		},
+		"code block": {
+			text: `{{ codeBlock "go" "x := 1" }}`,
+			want: "```go\nx := 1\n```",
+		},
+		"mermaid diagram": {
+			text: "{{ mermaid . }}",
+			data: templateDiagram{},
+			want: "```mermaid\ngraph TD\n  A-->B\n```",
+		},
+		"mermaid text": {
+			text: "{{ mermaid . }}",
+			data: "pie\n  \"a\" : 1",
+			want: "```mermaid\npie\n  \"a\" : 1\n```",
+		},
+		"alert": {
+			text: `{{ warning "Careful" }}`,
+			want: "> [!WARNING]  \n> Careful",
+		},
+		"alert in the dialect": {
+			opts: []Option{WithDialect(DialectGitLab)},
+			text: `{{ tip "Hint" }}`,
+			want: "> [!tip]\n> Hint",
+		},
+		"alert with escaping": {
+			opts: []Option{WithEscaping()},
+			text: `{{ note "*not bold*" }}`,
+			want: "> [!NOTE]  \n> \\*not bold\\*",
+		},
+		"badge": {
+			text: `{{ blueBadge "docs-latest" }}`,
+			want: "![Badge](https://img.shields.io/badge/docs-latest-blue)",
+		},
+		"shields.io badges": {
+			text: "{{ badge .Build .Coverage }}",
+			data: map[string]Badge{
+				"Build":    {Label: "build", Message: "passing", Color: "green"},
+				"Coverage": {Label: "coverage", Endpoint: "https://example.com/c.json"},
+			},
+			want: "![build: passing](https://img.shields.io/badge/build-passing-green) " +
+				"![coverage](https://img.shields.io/endpoint?url=https%3A%2F%2Fexample.com%2Fc.json)",
+		},
+		"details": {
+			text: `{{ details "More" "Hidden" }}`,
+			want: "<details>\n<summary>More</summary>\n\nHidden\n\n</details>",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMarkdown(nil, tt.opts...).Template(tt.text, tt.data)
+			if err := m.Error(); err != nil {
+				t.Fatalf("Error() = %v", err)
+			}
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), m.String()); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTemplateMixesWithTheChain(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarkdown(nil, WithBlockSpacing()).
+		H1("Release").
+		TableOfContents(TableOfContentsDepthH2).
+		Template("## Highlights\n\n{{ range . }}- {{ . }}\n{{ end }}", []string{"fast", "small"}).
+		H2("Changes")
+
+	want := "# Release\n" +
+		"<!-- BEGIN_TOC -->\n- [Release](#release)\n  - [Highlights](#highlights)\n  - [Changes](#changes)\n<!-- END_TOC -->\n\n" +
+		"## Highlights\n\n- fast\n- small\n\n## Changes"
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	tests := map[string]struct {
+		text string
+		data any
+		want string
+	}{
+		"parse": {
+			text: "{{ .Missing",
+			want: "failed to parse template",
+		},
+		"execute": {
+			text: "{{ .Missing }}",
+			data: 1,
+			want: "failed to execute template",
+		},
+		"unknown function": {
+			text: "{{ nope }}",
+			want: `function "nope" not defined`,
+		},
+		"table that is not one": {
+			text: "{{ table . }}",
+			data: 1,
+			want: "table rows must be a slice of structs",
+		},
+		"table with uneven columns": {
+			text: "{{ table . }}",
+			data: TableSet{Header: []string{"a"}, Rows: [][]string{{"1", "2"}}},
+			want: "failed to validate columns",
+		},
+		"mermaid diagram error": {
+			text: "{{ mermaid . }}",
+			data: templateDiagram{err: boom},
+			want: "boom",
+		},
+		"badge without a message": {
+			text: "{{ badge . }}",
+			data: Badge{Label: "build"},
+			want: `badge "build" has no message`,
+		},
+		"mermaid of something else": {
+			text: "{{ mermaid . }}",
+			data: 1,
+			want: "mermaid: int is neither a diagram nor its text",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewMarkdown(&strings.Builder{}).Template(tt.text, tt.data).Build()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md.tmpl")
+	if err := os.WriteFile(path, []byte("# {{ .Version }}\n\n{{ note .Note }}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMarkdown(nil).TemplateFile(path, map[string]string{"Version": "v1.2.0", "Note": "Read me"})
+	if err := m.Error(); err != nil {
+		t.Fatalf("Error() = %v", err)
+	}
+	want := "# v1.2.0\n\n> [!NOTE]  \n> Read me"
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+
+	missing := NewMarkdown(nil).TemplateFile(filepath.Join(dir, "missing.tmpl"), nil)
+	if err := missing.Error(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Error() = %v, want os.ErrNotExist", err)
+	}
+}
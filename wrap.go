@@ -0,0 +1,265 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nao1215/markdown/internal"
+	"github.com/olekukonko/tablewriter/pkg/twwidth"
+)
+
+// WithLineWidth wraps the text of paragraphs, quotes, alerts and list items at
+// width columns, so that a change to one sentence of a generated document is a
+// change to one line of its diff.
+//
+// Lines break between words only. A link, an image, a code span, an autolink
+// or an HTML tag is never split, and neither is a URL, so a word longer than
+// the width gets a line of its own rather than being cut. Width is measured the
+// way tables are aligned, so a wide character counts as two columns. Wrapped
+// lines of a quote or an alert are quoted, and those of a list item are
+// indented under its text; the text of a task item wraps as if it were
+// indented as far as its box, so that its first line fits too.
+//
+// Lines the caller broke stay broken, hard line breaks included. Lines that
+// are not prose, such as a fenced block, a table row or an indented line in
+// PlainText, are left as they are, and so is a block Parse read from a source.
+//
+// A width of 0, the default, turns wrapping off. A negative width is recorded
+// as an error.
+func WithLineWidth(width int) Option {
+	return func(m *Markdown) {
+		if width < 0 {
+			m.addError(fmt.Errorf("negative line width: %d", width))
+			return
+		}
+		m.lineWidth = width
+	}
+}
+
+// wrap wraps text to the line width of the document, less the columns taken
+// by a prefix of the given width, such as the "> " of a quote. Wrapped lines
+// are joined with the line feed and indent.
+func (r *blockRenderer) wrap(text string, prefix int, indent string) string {
+	if r.lineWidth == 0 {
+		return text
+	}
+	return wrapProse(text, max(r.lineWidth-prefix, 1), indent)
+}
+
+// indented returns a renderer for blocks that are written indented by width
+// columns, such as the content of a list item.
+func (r *blockRenderer) indented(width int) *blockRenderer {
+	if r.lineWidth == 0 {
+		return r
+	}
+	nested := *r
+	nested.lineWidth = max(r.lineWidth-width, 1)
+	return &nested
+}
+
+// wrapProse wraps every line of text that is prose and wider than width.
+func wrapProse(text string, width int, indent string) string {
+	lf := internal.LineFeed()
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	fence := ""
+	for i, line := range lines {
+		// A fenced block is copied as it is, up to the fence that closes it.
+		if opening := regionFence.FindStringSubmatch(line); opening != nil {
+			switch {
+			case fence == "":
+				fence = opening[1]
+			case strings.HasPrefix(opening[1], fence) && strings.TrimSpace(strings.TrimLeft(line, " "+fence[:1])) == "":
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		lines[i] = strings.Join(wrapLine(line, width), lf+indent)
+	}
+	return strings.Join(lines, lf)
+}
+
+// wrapLine breaks one line between words, filling each piece up to width.
+//
+// A line that starts like a block other than a paragraph, or that starts with
+// whitespace, is not prose and is returned whole, and so is a line that starts
+// with "[label]:", which a break after its first word would turn into a link
+// reference definition. A piece never starts with a word that would start such
+// a block either, and never ends in a backslash, which would turn the line
+// ending into a hard line break. The first piece is never a tag on its own,
+// which would start an HTML block, nor only the punctuation of a thematic
+// break or a setext underline: under the line above, or after a list marker,
+// "===" and "--" are read as one.
+func wrapLine(line string, width int) []string {
+	if twwidth.Width(line) <= width || unsafeLineStart(line) || strings.HasPrefix(line, "|") ||
+		strings.TrimLeft(line, " \t") != line || linkLabelStart.MatchString(line) {
+		return []string{line}
+	}
+
+	// The whitespace the line ends with stays at the end, where two spaces
+	// are a hard line break.
+	body := strings.TrimRight(line, " \t")
+	trailing := line[len(body):]
+
+	words := splitWords(body)
+	pieces := []string{}
+	current := words[0]
+	for _, word := range words[1:] {
+		if twwidth.Width(current)+1+twwidth.Width(word) <= width ||
+			unsafeLineStart(word) || trailingBackslashes(current)%2 == 1 ||
+			(len(pieces) == 0 && current == words[0] && strings.HasPrefix(current, "<")) ||
+			(len(pieces) == 0 && strings.Trim(current, "=-*_+: \t") == "") {
+			current += " " + word
+			continue
+		}
+		pieces = append(pieces, current)
+		current = word
+	}
+	return append(pieces, current+trailing)
+}
+
+// linkLabelStart matches the "[label]:" a link reference definition opens
+// with.
+var linkLabelStart = regexp.MustCompile(`^\[(?:[^\[\]\\]|\\.)+\]:`) //nolint:gochecknoglobals // compiled once
+
+// unsafeLineStart reports whether a wrapped line must not start with s, which
+// would start a block other than a paragraph, or display math, which GitHub
+// opens with "$$".
+func unsafeLineStart(s string) bool {
+	return startsBlock(s) || strings.HasPrefix(s, "$$")
+}
+
+// splitWords splits a line at its spaces, keeping together what must not be
+// split: a backslash escape, a code span, inline math, a link or an image with
+// its destination, an autolink and an HTML tag.
+func splitWords(line string) []string {
+	var words []string
+	start := 0
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t':
+			if i > start {
+				words = append(words, line[start:i])
+			}
+			i++
+			start = i
+		case '\\':
+			i = min(i+2, len(line))
+		case '`':
+			i = skipCodeSpan(line, i)
+		case '$':
+			i = skipMath(line, i)
+		case '[':
+			i = skipLink(line, i)
+		case '<':
+			if tag := inlineTag.FindString(line[i:]); tag != "" {
+				i += len(tag)
+			} else {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	if start < len(line) {
+		words = append(words, line[start:])
+	}
+	return words
+}
+
+// inlineTag matches an HTML tag, a comment or an autolink at the start of a
+// string, which markdown reads as one piece however many spaces it holds.
+var inlineTag = regexp.MustCompile(`^<(?:` + //nolint:gochecknoglobals // compiled once
+	`[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][\w.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>` +
+	`|/[A-Za-z][A-Za-z0-9-]*\s*>` +
+	`|!--.*?-->` +
+	`|[A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*>` +
+	`|[^\s@<>]+@[^\s@<>]+>)`)
+
+// skipCodeSpan returns the index after the code span that opens at i, or after
+// its backticks when nothing closes it.
+func skipCodeSpan(line string, i int) int {
+	run := len(line[i:]) - len(strings.TrimLeft(line[i:], "`"))
+	for j := i + run; j < len(line); {
+		if line[j] != '`' {
+			j++
+			continue
+		}
+		closing := len(line[j:]) - len(strings.TrimLeft(line[j:], "`"))
+		if closing == run {
+			return j + closing
+		}
+		j += closing
+	}
+	return i + run
+}
+
+// skipMath returns the index after the inline math that opens at i, read the
+// way mathInlineParser reads it, or i+1 when there is none.
+func skipMath(line string, i int) int {
+	if i+2 >= len(line) || line[i+1] == '$' || line[i+1] == ' ' || line[i+1] == '\t' {
+		return i + 1
+	}
+	if line[i+1] == '`' {
+		if end := strings.Index(line[i+2:], "`$"); end >= 1 {
+			return i + 2 + end + 2
+		}
+		return i + 1
+	}
+	for j := i + 1; j < len(line); j++ {
+		switch line[j] {
+		case '\\':
+			j++
+		case '$':
+			if line[j-1] == ' ' || line[j-1] == '\t' || (j+1 < len(line) && line[j+1] >= '0' && line[j+1] <= '9') {
+				return i + 1
+			}
+			return j + 1
+		}
+	}
+	return i + 1
+}
+
+// skipLink returns the index after the bracketed text that opens at i and the
+// "(destination)" or "[label]" that follows it, or i+1 when the bracket is
+// never closed.
+func skipLink(line string, i int) int {
+	end := skipBalanced(line, i, '[', ']')
+	if end < 0 {
+		return i + 1
+	}
+	if end < len(line) && (line[end] == '(' || line[end] == '[') {
+		closing := byte(')')
+		if line[end] == '[' {
+			closing = ']'
+		}
+		if after := skipBalanced(line, end, line[end], closing); after > 0 {
+			return after
+		}
+	}
+	return end
+}
+
+// skipBalanced returns the index after the close that balances the open at i,
+// skipping escaped brackets, or -1 when there is none.
+func skipBalanced(line string, i int, open, closing byte) int {
+	depth := 0
+	for j := i; j < len(line); j++ {
+		switch line[j] {
+		case '\\':
+			j++
+		case open:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return -1
+}
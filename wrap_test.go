@@ -0,0 +1,217 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithLineWidth(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		width int
+		build func(*Markdown) *Markdown
+		want  string
+	}{
+		"paragraph": {
+			width: 20,
+			build: func(m *Markdown) *Markdown {
+				return m.PlainText("The quick brown fox jumps over the lazy dog.")
+			},
+			want: "The quick brown fox\njumps over the lazy\ndog.",
+		},
+		"short paragraph": {
+			width: 20,
+			build: func(m *Markdown) *Markdown { return m.PlainText("Short enough.") },
+			want:  "Short enough.",
+		},
+		"links and code spans are kept whole": {
+			width: 12,
+			build: func(m *Markdown) *Markdown {
+				return m.PlainText("See [the long guide](https://example.com/a/b \"Guide\") and `go test ./...` now.")
+			},
+			want: "See\n[the long guide](https://example.com/a/b \"Guide\")\nand\n`go test ./...`\nnow.",
+		},
+		"a long url gets a line of its own": {
+			width: 10,
+			build: func(m *Markdown) *Markdown {
+				return m.PlainText("Go to https://example.com/very/long/path today.")
+			},
+			want: "Go to\nhttps://example.com/very/long/path\ntoday.",
+		},
+		"no line starts a block": {
+			width: 6,
+			build: func(m *Markdown) *Markdown { return m.PlainText("one - two # three 1. four > five") },
+			want:  "one -\ntwo #\nthree 1.\nfour >\nfive",
+		},
+		"wide characters": {
+			width: 20,
+			build: func(m *Markdown) *Markdown {
+				return m.PlainText("日本語の文章 日本語の文章 日本語の文章")
+			},
+			want: "日本語の文章\n日本語の文章\n日本語の文章",
+		},
+		"hard line break": {
+			width: 12,
+			build: func(m *Markdown) *Markdown { return m.PlainText("a line that breaks  \nhere") },
+			want:  "a line that\nbreaks  \nhere",
+		},
+		"lines that are not prose": {
+			width: 8,
+			build: func(m *Markdown) *Markdown {
+				return m.PlainText("| a long | table |\n```\na long code line\n```\n    indented long line")
+			},
+			want: "| a long | table |\n```\na long code line\n```\n    indented long line",
+		},
+		"quote": {
+			width: 16,
+			build: func(m *Markdown) *Markdown { return m.Blockquote("A quote that goes on and on.") },
+			want:  "> A quote that\n> goes on and\n> on.",
+		},
+		"alert": {
+			width: 16,
+			build: func(m *Markdown) *Markdown { return m.Note("An alert that goes on and on.") },
+			want:  "> [!NOTE]  \n> An alert that\n> goes on and\n> on.",
+		},
+		"bullet list": {
+			width: 16,
+			build: func(m *Markdown) *Markdown { return m.BulletList("An item that goes on and on.", "Short.") },
+			want:  "- An item that\n  goes on and\n  on.\n- Short.",
+		},
+		"task list": {
+			width: 16,
+			build: func(m *Markdown) *Markdown {
+				return m.CheckBox([]CheckBoxSet{{Checked: true, Text: "A task that goes on"}})
+			},
+			want: "- [x] A task\n  that goes\n  on",
+		},
+		"nested list": {
+			width: 20,
+			build: func(m *Markdown) *Markdown {
+				return m.OrderedListTree(Item("A parent item that goes on", Item("A child item that goes on")))
+			},
+			want: "1. A parent item\n   that goes on\n   1. A child item\n      that goes on",
+		},
+		"an alert does not start with an underline": {
+			width: 5,
+			build: func(m *Markdown) *Markdown { return m.Note("=== `c d` __ ===") },
+			want:  "> [!NOTE]  \n> === `c d` __ ===",
+		},
+		"a list item does not start with a thematic break": {
+			width: 3,
+			build: func(m *Markdown) *Markdown { return m.BulletList("-- [x](y) <div> ===") },
+			want:  "- -- [x](y) <div> ===",
+		},
+		"a paragraph does not start with a link reference definition": {
+			width: 9,
+			build: func(m *Markdown) *Markdown { return m.PlainText("[a]: bb [x](y) <? more") },
+			want:  "[a]: bb [x](y) <? more",
+		},
+		"off": {
+			width: 0,
+			build: func(m *Markdown) *Markdown { return m.PlainText("The quick brown fox jumps over the lazy dog.") },
+			want:  "The quick brown fox jumps over the lazy dog.",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tt.build(NewMarkdown(nil, WithLineWidth(tt.width))).String()
+			if diff := cmp.Diff(normalizeLineFeeds(tt.want), got); diff != "" {
+				t.Errorf("value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithLineWidthKeepsParsedBlocks(t *testing.T) {
+	t.Parallel()
+
+	source := "A parsed paragraph that is longer than the width.\n"
+	m, err := Parse(strings.NewReader(source), nil, WithLineWidth(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.PlainText("An added paragraph that is longer than the width.")
+
+	want := "A parsed paragraph that is longer than the width.\nAn added paragraph\nthat is longer than\nthe width."
+	if diff := cmp.Diff(normalizeLineFeeds(want), m.String()); diff != "" {
+		t.Errorf("value is mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithLineWidthNegative(t *testing.T) {
+	t.Parallel()
+
+	if err := NewMarkdown(nil, WithLineWidth(-1)).Error(); err == nil {
+		t.Error("Error() = nil, want the negative width")
+	}
+}
+
+// FuzzWrapProse checks that wrapping text does not change what it renders to,
+// whitespace aside.
+func FuzzWrapProse(f *testing.F) {
+	f.Add("The quick brown fox - jumps [a b](c d) `x y` <a href=\"x\"> 1. b # c + d > e", 10)
+	f.Add("$x + y$ and $`a b`$ cost $5 and $10\\ total  \nnext", 4)
+	f.Add("<span>text</span> <!-- c --> ![alt text](img.png) **bold text** _it_", 8)
+	whitespace := regexp.MustCompile(`\s+`)
+	render := func(t *testing.T, source string) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if err := newHTMLConverter(false).Convert([]byte(source), &buf); err != nil {
+			t.Fatal(err)
+		}
+		return whitespace.ReplaceAllString(buf.String(), "")
+	}
+	f.Fuzz(func(t *testing.T, text string, width int) {
+		if width < 1 || width > 80 || strings.ContainsAny(text, "\r\v\f\x00") {
+			t.Skip()
+		}
+		wrapped := wrapProse(text, width, "")
+		if render(t, text) != render(t, wrapped) {
+			t.Errorf("wrapProse(%q, %d) = %q, which renders differently", text, width, wrapped)
+		}
+	})
+}
+
+// FuzzWithLineWidth checks that the blocks WithLineWidth wraps render to the
+// same HTML as they do unwrapped, whitespace aside.
+func FuzzWithLineWidth(f *testing.F) {
+	f.Add("=== `c d` __ ===", 5)
+	f.Add("-- [x](y) <div> ===", 3)
+	f.Add("[a]: bb [x](y) <? more", 9)
+	f.Add("The quick brown fox - jumps # over 1. the > lazy dog", 6)
+	whitespace := regexp.MustCompile(`\s+`)
+	build := func(width int, text string) *Markdown {
+		return NewMarkdown(nil, WithLineWidth(width)).
+			PlainText(text).
+			BulletList(text, text).
+			OrderedListTree(Item(text, Item(text))).
+			Blockquote(text).
+			Note(text)
+	}
+	render := func(t *testing.T, m *Markdown) string {
+		t.Helper()
+
+		out, err := m.HTML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return whitespace.ReplaceAllString(out, "")
+	}
+	f.Fuzz(func(t *testing.T, text string, width int) {
+		if width < 1 || width > 80 || strings.ContainsAny(text, "\r\n\v\f\x00") {
+			t.Skip()
+		}
+		if render(t, build(0, text)) != render(t, build(width, text)) {
+			t.Errorf("text %q at width %d renders differently:\n%s", text, width, build(width, text))
+		}
+	})
+}